@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/beego/beego/v2/server/web"
+
+	"github.com/opensourceways/atomgit-sig-file-cache/models"
+	"github.com/opensourceways/atomgit-sig-file-cache/sdk"
+)
+
+// Loader provides the index served by the controllers, it must be set before serving.
+var Loader *models.Loader
+
+type response struct {
+	Data interface{} `json:"data,omitempty"`
+	Code string      `json:"code,omitempty"`
+	Msg  string      `json:"msg,omitempty"`
+}
+
+type baseController struct {
+	web.Controller
+}
+
+func (c *baseController) index() *sdk.Index {
+	return Loader.Index()
+}
+
+func (c *baseController) sendData(data interface{}) {
+	c.Data["json"] = response{Data: data}
+	_ = c.ServeJSON()
+}
+
+func (c *baseController) sendNotFound(msg string) {
+	c.Ctx.Output.SetStatus(http.StatusNotFound)
+	c.Data["json"] = response{Code: sdk.CodeNotFound, Msg: msg}
+	_ = c.ServeJSON()
+}
+
+// SigController serves the sigs.
+type SigController struct {
+	baseController
+}
+
+// List returns the names of all the sigs.
+func (c *SigController) List() {
+	c.sendData(c.index().Sigs())
+}
+
+// GetSigInfo returns the sig-info.yaml of sig.
+func (c *SigController) GetSigInfo() {
+	sig := c.Ctx.Input.Param(":sig")
+
+	if v, ok := c.index().SigInfo(sig); ok {
+		c.sendData(v)
+	} else {
+		c.sendNotFound("no sig of " + sig)
+	}
+}
+
+// GetMembers returns the maintainers, committers and mentors of sig.
+func (c *SigController) GetMembers() {
+	sig := c.Ctx.Input.Param(":sig")
+
+	if v, ok := c.index().SigMembers(sig); ok {
+		c.sendData(v)
+	} else {
+		c.sendNotFound("no sig of " + sig)
+	}
+}
+
+// RepoController serves the sig and branches of repository.
+type RepoController struct {
+	baseController
+}
+
+// GetSig returns the sig which manages the repository.
+func (c *RepoController) GetSig() {
+	org, repo := c.Ctx.Input.Param(":org"), c.Ctx.Input.Param(":repo")
+
+	if v, ok := c.index().RepoSig(org, repo); ok {
+		c.sendData(v)
+	} else {
+		c.sendNotFound("no sig manages " + org + "/" + repo)
+	}
+}
+
+// GetBranchKeepers returns the keepers of the branch in query,
+// because the name of branch may contain slashes.
+func (c *RepoController) GetBranchKeepers() {
+	org, repo := c.Ctx.Input.Param(":org"), c.Ctx.Input.Param(":repo")
+	branch := c.GetString("branch")
+
+	if v, ok := c.index().BranchKeepers(org, repo, branch); ok {
+		c.sendData(v)
+	} else {
+		c.sendNotFound("no keepers of " + org + "/" + repo + "/" + branch)
+	}
+}
+
+// UserController serves the sigs of user.
+type UserController struct {
+	baseController
+}
+
+// GetSigs returns the sigs which the user belongs to.
+func (c *UserController) GetSigs() {
+	c.sendData(c.index().UserSigs(c.Ctx.Input.Param(":login")))
+}
@@ -1,22 +1,63 @@
 package main
 
 import (
-	"fmt"
-	_ "sig-info-cache/routers"
+	"time"
 
 	"github.com/beego/beego/v2/server/web"
+	"github.com/opensourceways/community-robot-lib/atomgitclient"
+	"github.com/opensourceways/community-robot-lib/config"
+	"github.com/opensourceways/community-robot-lib/logrusutil"
+	framework "github.com/opensourceways/community-robot-lib/robot-atomgit-framework"
+	"github.com/opensourceways/community-robot-lib/secret"
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/atomgit-sig-file-cache/models"
+	"github.com/opensourceways/atomgit-sig-file-cache/routers"
 )
 
+const component = "sig-info-cache"
+
+// hookRobot receives the push of community repository by the framework of robot.
+type hookRobot struct {
+	loader *models.Loader
+}
+
+func (r hookRobot) NewConfig() config.Config {
+	return nil
+}
+
+func (r hookRobot) RegisterEventHandler(f framework.HandlerRegister) {
+	f.RegisterPushEventHandler(r.loader.HandlePushEvent)
+}
+
 func main() {
-	//err := web.LoadAppConfig("ini", "D:\\Project\\github\\ibfru\\atomgit-bot\\sig-info-cache\\conf\\app.conf")
-	//if err != nil {
-	//	return
-	//}
-	configFile, err := web.AppConfig.String("appconf")
-	if err != nil {
-		_ = fmt.Errorf("error %+v", err)
+	logrusutil.ComponentInit(component)
+
+	cfg := web.AppConfig
+	org := cfg.DefaultString("community_org", "openeuler")
+	repo := cfg.DefaultString("community_repo", "community")
+	branch := cfg.DefaultString("community_branch", "master")
+	tokenPath := cfg.DefaultString("token_path", "/etc/atomgit/oauth")
+	interval := cfg.DefaultInt("refresh_interval", 60)
+
+	secretAgent := new(secret.Agent)
+	if err := secretAgent.Start([]string{tokenPath}); err != nil {
+		logrus.WithError(err).Fatal("Error starting secret agent.")
+	}
+
+	defer secretAgent.Stop()
+
+	getToken := secretAgent.GetTokenGenerator(tokenPath)
+
+	loader := models.NewLoader(atomgitclient.NewClient(getToken), org, repo, branch)
+	if err := loader.Load(); err != nil {
+		logrus.WithError(err).Errorf("load the sig info of %s/%s", org, repo)
 	}
-	fmt.Println(configFile)
+
+	loader.Start(time.Duration(interval) * time.Minute)
+	defer loader.Stop()
+
+	routers.Init(loader, framework.NewWebhookHandler(hookRobot{loader: loader}, nil, getToken))
 
 	web.Run()
 }
@@ -0,0 +1,163 @@
+package models
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opensourceways/community-robot-lib/config"
+	"github.com/opensourceways/go-atomgit/atomgit"
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/atomgit-sig-file-cache/sdk"
+)
+
+// Loader loads the index from the community repository, and refreshes it
+// when the repository is pushed or periodically.
+type Loader struct {
+	cli    sdk.Client
+	org    string
+	repo   string
+	branch string
+
+	mut   sync.RWMutex
+	index *sdk.Index
+
+	// loadMut serializes the loads, and blobs is accessed only during the load.
+	loadMut sync.Mutex
+	// blobs maps the sha of file to the content, so that the unchanged files
+	// will not be downloaded again.
+	blobs map[string][]byte
+
+	trigger chan struct{}
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// NewLoader creates the loader of the community repository org/repo on branch.
+func NewLoader(cli sdk.Client, org, repo, branch string) *Loader {
+	return &Loader{
+		cli:     cli,
+		org:     org,
+		repo:    repo,
+		branch:  branch,
+		index:   &sdk.Index{},
+		blobs:   map[string][]byte{},
+		trigger: make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+// Index returns the latest index.
+func (l *Loader) Index() *sdk.Index {
+	l.mut.RLock()
+	defer l.mut.RUnlock()
+
+	return l.index
+}
+
+// Load builds the index with the latest files of the community repository.
+// The index is replaced even if some files are invalid, and the error of them is returned.
+func (l *Loader) Load() error {
+	l.loadMut.Lock()
+	defer l.loadMut.Unlock()
+
+	idx, blobs, err := sdk.LoadIndex(l.cli, l.org, l.repo, l.branch, l.blobs)
+	if idx == nil {
+		return err
+	}
+
+	l.blobs = blobs
+
+	l.mut.Lock()
+	l.index = idx
+	l.mut.Unlock()
+
+	return err
+}
+
+// Start refreshes the index in the background when it is triggered by Refresh,
+// or after every interval if it is positive.
+func (l *Loader) Start(interval time.Duration) {
+	go func() {
+		defer close(l.stopped)
+
+		var tick <-chan time.Time
+		if interval > 0 {
+			t := time.NewTicker(interval)
+			defer t.Stop()
+
+			tick = t.C
+		}
+
+		for {
+			select {
+			case <-l.trigger:
+			case <-tick:
+			case <-l.stop:
+				return
+			}
+
+			if err := l.Load(); err != nil {
+				logrus.WithError(err).Error("refresh the sig info")
+			}
+		}
+	}()
+}
+
+// Stop stops the background refreshing started by Start.
+func (l *Loader) Stop() {
+	close(l.stop)
+	<-l.stopped
+}
+
+// Refresh triggers the refreshing without waiting for it. The triggers are
+// merged into one if the previous refreshing has not started.
+func (l *Loader) Refresh() {
+	select {
+	case l.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// HandlePushEvent refreshes the index if the sig files on the branch of
+// the community repository are changed. It is a framework.PushEventHandler.
+func (l *Loader) HandlePushEvent(e *atomgit.PushEvent, _ config.Config, log *logrus.Entry) error {
+	org := e.GetRepo().GetOwner().GetLogin()
+	repo := e.GetRepo().GetName()
+
+	if !strings.EqualFold(org, l.org) || !strings.EqualFold(repo, l.repo) {
+		return nil
+	}
+
+	if e.GetRef() != "refs/heads/"+l.branch || !hasSigFileChanged(e) {
+		return nil
+	}
+
+	log.Info("refresh the sig info for the push")
+
+	l.Refresh()
+
+	return nil
+}
+
+// hasSigFileChanged checks the files changed by the commits of push,
+// and it is regarded as changed if the push has no commits.
+func hasSigFileChanged(e *atomgit.PushEvent) bool {
+	if len(e.Commits) == 0 {
+		return true
+	}
+
+	for _, c := range e.Commits {
+		for _, files := range [][]string{c.Added, c.Modified, c.Removed} {
+			for _, f := range files {
+				if sdk.IsSigFile(f) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/opensourceways/community-robot-lib/atomgitclient"
+	"github.com/opensourceways/community-robot-lib/fakeatomgit"
+	"github.com/opensourceways/go-atomgit/atomgit"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	infraSigInfo = `
+name: Infrastructure
+maintainers:
+  - gitee_id: Alice
+repositories:
+  - repo:
+      - openeuler/infrastructure
+    committers:
+      - gitee_id: bob
+branches:
+  - repo_branch:
+      - repo: openeuler/infrastructure
+        branch: openEuler-24.03-LTS
+    keeper:
+      - gitee_id: carol
+`
+
+	kernelOwners = `
+maintainers:
+  - dave
+committers:
+  - bob
+`
+)
+
+func TestLoader(t *testing.T) {
+	s := fakeatomgit.NewServer("robot")
+	s.SetFile("openeuler", "community", "master", "sig/Infrastructure/sig-info.yaml", []byte(infraSigInfo))
+	s.SetFile("openeuler", "community", "master", "sig/Kernel/OWNERS", []byte(kernelOwners))
+	s.SetFile("openeuler", "community", "master", "sig/Kernel/README.md", []byte("kernel"))
+
+	api := httptest.NewServer(s)
+	defer api.Close()
+
+	cli, err := atomgitclient.NewClientWithEndpoint(func() []byte { return []byte("token") }, api.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := NewLoader(cli, "openeuler", "community", "master")
+	if err := l.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	idx := l.Index()
+
+	if v := idx.Sigs(); !reflect.DeepEqual(v, []string{"Infrastructure", "Kernel"}) {
+		t.Errorf("sigs = %v", v)
+	}
+
+	rs, ok := idx.RepoSig("openeuler", "Infrastructure")
+	if !ok || rs.Sig != "Infrastructure" || !reflect.DeepEqual(rs.Committers, []string{"bob"}) {
+		t.Errorf("sig of repo = %+v", rs)
+	}
+
+	m, _ := idx.SigMembers("Kernel")
+	if !reflect.DeepEqual(m.Maintainers, []string{"dave"}) || !reflect.DeepEqual(m.Committers, []string{"bob"}) {
+		t.Errorf("members of Kernel = %+v", m)
+	}
+
+	if v := idx.UserSigs("Bob"); !reflect.DeepEqual(v, []string{"Infrastructure", "Kernel"}) {
+		t.Errorf("sigs of bob = %v", v)
+	}
+
+	keepers, ok := idx.BranchKeepers("openeuler", "infrastructure", "openEuler-24.03-LTS")
+	if !ok || !reflect.DeepEqual(keepers, []string{"alice", "carol"}) {
+		t.Errorf("keepers = %v", keepers)
+	}
+
+	// the push to sig files triggers the refreshing.
+	s.SetFile("openeuler", "community", "master", "sig/Kernel/OWNERS", []byte("maintainers: [erin]"))
+
+	e := &atomgit.PushEvent{
+		Ref: atomgit.String("refs/heads/master"),
+		Repo: &atomgit.PushEventRepository{
+			Name:  atomgit.String("community"),
+			Owner: &atomgit.User{Login: atomgit.String("openeuler")},
+		},
+		Commits: []*atomgit.HeadCommit{{Modified: []string{"sig/Kernel/OWNERS"}}},
+	}
+
+	if err := l.HandlePushEvent(e, nil, logrus.NewEntry(logrus.New())); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-l.trigger:
+	default:
+		t.Fatal("the push should trigger the refreshing")
+	}
+
+	if err := l.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := l.Index().UserSigs("erin"); !reflect.DeepEqual(v, []string{"Kernel"}) {
+		t.Errorf("sigs of erin after push = %v", v)
+	}
+}
@@ -1,13 +1,35 @@
 package routers
 
 import (
-	"sig-info-cache/controllers"
+	"net/http"
 
 	"github.com/beego/beego/v2/server/web"
+	"github.com/beego/beego/v2/server/web/context"
+
+	"github.com/opensourceways/atomgit-sig-file-cache/controllers"
+	"github.com/opensourceways/atomgit-sig-file-cache/models"
 )
 
-func init() {
-	web.Router("/", &controllers.MainController{})
-	web.Router("/sig/:org/:repo", &controllers.SigInfoController{}, "get:GetSigInfo")
-	//web.Include(&controllers.SigInfoController{})
+// HookPath is the path of webhook which receives the push of community repository.
+const HookPath = "/atomgit-hook"
+
+// Init registers the routers of service, and the webhook is served by hook.
+func Init(loader *models.Loader, hook http.Handler) {
+	controllers.Loader = loader
+
+	web.Get("/", func(ctx *context.Context) {
+		// service's healthy check, do nothing
+	})
+
+	web.Router("/sigs", &controllers.SigController{}, "get:List")
+	web.Router("/sigs/:sig", &controllers.SigController{}, "get:GetSigInfo")
+	web.Router("/sigs/:sig/members", &controllers.SigController{}, "get:GetMembers")
+	web.Router("/repos/:org/:repo/sig", &controllers.RepoController{}, "get:GetSig")
+	web.Router("/repos/:org/:repo/branch-keepers", &controllers.RepoController{}, "get:GetBranchKeepers")
+	web.Router("/users/:login/sigs", &controllers.UserController{}, "get:GetSigs")
+
+	// it is kept for the old clients.
+	web.Router("/sig/:org/:repo", &controllers.RepoController{}, "get:GetSig")
+
+	web.Handler(HookPath, hook)
 }
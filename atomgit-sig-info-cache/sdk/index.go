@@ -0,0 +1,258 @@
+package sdk
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/opensourceways/community-robot-lib/utils"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	sigInfoFile = "sig-info.yaml"
+	ownersFile  = "OWNERS"
+)
+
+// sigFileRe matches the files indexed, and the first group is the name of sig.
+var sigFileRe = regexp.MustCompile(`^sig/([^/]+)/(sig-info\.yaml|OWNERS)$`)
+
+// IsSigFile reports whether the file at path of the community repository is indexed.
+func IsSigFile(path string) bool {
+	return sigFileRe.MatchString(path)
+}
+
+// owners is the content of sig/<sig>/OWNERS.
+type owners struct {
+	Maintainers []string `json:"maintainers"`
+	Committers  []string `json:"committers"`
+}
+
+// Index is the relations among the repositories, sigs and users. It is read only
+// after being built, so it is safe for concurrent use.
+type Index struct {
+	sigs    map[string]*SigInfo
+	members map[string]*SigMembers
+
+	// repos maps org/repo in lower case to the sig of it.
+	repos map[string]*RepoSig
+
+	// userSigs maps login in lower case to the sigs of it.
+	userSigs map[string][]string
+
+	// branchKeepers maps org/repo/branch in lower case to the keepers of it.
+	branchKeepers map[string][]string
+}
+
+// BuildIndex builds the index with the content of sig-info.yaml and OWNERS of each sig,
+// files maps the path to the content of file. The invalid files are skipped and returned
+// as the error, so the index is always usable.
+func BuildIndex(files map[string][]byte) (*Index, error) {
+	infos := map[string]*SigInfo{}
+	ows := map[string]*owners{}
+	mErr := utils.NewMultiErrors()
+
+	for path, content := range files {
+		m := sigFileRe.FindStringSubmatch(path)
+		if m == nil {
+			continue
+		}
+
+		sig := m[1]
+
+		switch m[2] {
+		case sigInfoFile:
+			v := new(SigInfo)
+			if err := yaml.Unmarshal(content, v); err != nil {
+				mErr.Add(fmt.Sprintf("invalid %s, err:%s", path, err.Error()))
+
+				continue
+			}
+
+			if v.Name == "" {
+				v.Name = sig
+			}
+
+			infos[sig] = v
+
+		case ownersFile:
+			v := new(owners)
+			if err := yaml.Unmarshal(content, v); err != nil {
+				mErr.Add(fmt.Sprintf("invalid %s, err:%s", path, err.Error()))
+
+				continue
+			}
+
+			ows[sig] = v
+		}
+	}
+
+	// a sig may have only OWNERS.
+	for sig := range ows {
+		if _, ok := infos[sig]; !ok {
+			infos[sig] = &SigInfo{Name: sig}
+		}
+	}
+
+	idx := &Index{
+		sigs:          infos,
+		members:       make(map[string]*SigMembers, len(infos)),
+		repos:         map[string]*RepoSig{},
+		userSigs:      map[string][]string{},
+		branchKeepers: map[string][]string{},
+	}
+
+	names := make([]string, 0, len(infos))
+	for sig := range infos {
+		names = append(names, sig)
+	}
+	sort.Strings(names)
+
+	userSigs := map[string]sets.String{}
+	for _, sig := range names {
+		if err := idx.addSig(sig, infos[sig], ows[sig], userSigs); err != nil {
+			mErr.AddError(err)
+		}
+	}
+
+	for login, v := range userSigs {
+		idx.userSigs[login] = v.List()
+	}
+
+	return idx, mErr.Err()
+}
+
+func (idx *Index) addSig(sig string, info *SigInfo, o *owners, userSigs map[string]sets.String) error {
+	if o == nil {
+		o = new(owners)
+	}
+
+	maintainers := logins(info.Maintainers).Insert(toLower(o.Maintainers)...)
+	mentors := logins(info.Mentors)
+	sigCommitters := sets.NewString(toLower(o.Committers)...)
+	committers := sets.NewString(sigCommitters.UnsortedList()...)
+
+	mErr := utils.NewMultiErrors()
+
+	for _, item := range info.Repositories {
+		repoCommitters := logins(item.Committers).Union(sigCommitters)
+		committers = committers.Union(repoCommitters)
+
+		for _, repo := range item.Repo {
+			k := strings.ToLower(repo)
+			if v, ok := idx.repos[k]; ok {
+				mErr.Add(fmt.Sprintf("%s belongs to both %s and %s", repo, v.Sig, sig))
+
+				continue
+			}
+
+			idx.repos[k] = &RepoSig{
+				Repo:       repo,
+				Sig:        sig,
+				Committers: repoCommitters.List(),
+				Info:       info,
+			}
+		}
+	}
+
+	for _, item := range info.Branches {
+		keepers := logins(item.Keeper).Union(maintainers).List()
+
+		for _, b := range item.RepoBranch {
+			idx.branchKeepers[strings.ToLower(b.Repo+"/"+b.Branch)] = keepers
+		}
+	}
+
+	idx.members[sig] = &SigMembers{
+		Sig:         sig,
+		Maintainers: maintainers.List(),
+		Committers:  committers.List(),
+		Mentors:     mentors.List(),
+	}
+
+	for _, login := range maintainers.Union(committers).Union(mentors).UnsortedList() {
+		if _, ok := userSigs[login]; !ok {
+			userSigs[login] = sets.NewString()
+		}
+
+		userSigs[login].Insert(sig)
+	}
+
+	return mErr.Err()
+}
+
+// Sigs returns the names of all the sigs.
+func (idx *Index) Sigs() []string {
+	r := make([]string, 0, len(idx.sigs))
+	for sig := range idx.sigs {
+		r = append(r, sig)
+	}
+
+	sort.Strings(r)
+
+	return r
+}
+
+// SigInfo returns the sig-info.yaml of sig.
+func (idx *Index) SigInfo(sig string) (*SigInfo, bool) {
+	v, ok := idx.sigs[sig]
+
+	return v, ok
+}
+
+// SigMembers returns the maintainers, committers and mentors of sig.
+func (idx *Index) SigMembers(sig string) (*SigMembers, bool) {
+	v, ok := idx.members[sig]
+
+	return v, ok
+}
+
+// RepoSig returns the sig which manages org/repo.
+func (idx *Index) RepoSig(org, repo string) (*RepoSig, bool) {
+	v, ok := idx.repos[strings.ToLower(org+"/"+repo)]
+
+	return v, ok
+}
+
+// UserSigs returns the sigs which the user belongs to.
+func (idx *Index) UserSigs(login string) []string {
+	if v := idx.userSigs[strings.ToLower(login)]; v != nil {
+		return v
+	}
+
+	return []string{}
+}
+
+// BranchKeepers returns the keepers of branch, which include the maintainers of sig.
+// It returns false if the branch has no keepers set.
+func (idx *Index) BranchKeepers(org, repo, branch string) ([]string, bool) {
+	v, ok := idx.branchKeepers[strings.ToLower(org+"/"+repo+"/"+branch)]
+
+	return v, ok
+}
+
+func logins(members []Member) sets.String {
+	r := sets.NewString()
+
+	for i := range members {
+		if v := members[i].GiteeID; v != "" {
+			r.Insert(strings.ToLower(v))
+		}
+	}
+
+	return r
+}
+
+func toLower(v []string) []string {
+	r := make([]string, 0, len(v))
+
+	for _, s := range v {
+		if s != "" {
+			r = append(r, strings.ToLower(s))
+		}
+	}
+
+	return r
+}
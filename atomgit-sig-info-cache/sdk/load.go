@@ -0,0 +1,66 @@
+package sdk
+
+import (
+	"fmt"
+
+	"github.com/opensourceways/go-atomgit/atomgit"
+)
+
+// Client is the methods of atomgitclient.Client to read the community repository.
+type Client interface {
+	GetDirectoryTree(org, repo, branch string, recursive bool) ([]*atomgit.TreeEntry, error)
+	GetPathContent(org, repo, path, branch string) (*atomgit.RepositoryContent, error)
+}
+
+// LoadIndex builds the index with the sig files on the branch of community repository org/repo.
+// blobs maps the sha of file to the content loaded last time, so that the unchanged files
+// will not be downloaded again, and the blobs of this time are returned. The index is returned
+// with the error if some files are invalid.
+func LoadIndex(cli Client, org, repo, branch string, blobs map[string][]byte) (*Index, map[string][]byte, error) {
+	entries, err := cli.GetDirectoryTree(org, repo, branch, true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list the files of %s/%s, err:%s", org, repo, err.Error())
+	}
+
+	files := map[string][]byte{}
+	newBlobs := map[string][]byte{}
+
+	for _, e := range entries {
+		path := e.GetPath()
+		if e.GetType() != "blob" || !IsSigFile(path) {
+			continue
+		}
+
+		sha := e.GetSHA()
+
+		content, ok := blobs[sha]
+		if !ok || sha == "" {
+			if content, err = download(cli, org, repo, branch, path); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		files[path] = content
+		if sha != "" {
+			newBlobs[sha] = content
+		}
+	}
+
+	idx, err := BuildIndex(files)
+
+	return idx, newBlobs, err
+}
+
+func download(cli Client, org, repo, branch, path string) ([]byte, error) {
+	fc, err := cli.GetPathContent(org, repo, path, branch)
+	if err != nil {
+		return nil, fmt.Errorf("get %s, err:%s", path, err.Error())
+	}
+
+	s, err := fc.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("decode %s, err:%s", path, err.Error())
+	}
+
+	return []byte(s), nil
+}
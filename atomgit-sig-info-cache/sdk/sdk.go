@@ -1,53 +1,248 @@
 package sdk
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
+	"time"
 
-	"github.com/opensourceways/server-common-lib/utils"
+	"github.com/opensourceways/community-robot-lib/utils"
+	"github.com/sirupsen/logrus"
 )
 
-func NewSDK(endpoint string, maxRetries int) *SDK {
+const defaultCacheTTL = 5 * time.Minute
+
+// CodeNotFound is the code in the response of service when the sig info is not found,
+// so that it is distinguished from the 404 of a wrong endpoint.
+const CodeNotFound = "not_found"
+
+// ErrNotFound means the sig, repository or branch has no sig info.
+var ErrNotFound = errors.New("not found")
+
+// Option customizes the SDK created by NewSDK.
+type Option func(*SDK)
+
+// CacheTTL sets the duration to cache the results, and disables the cache if it is not positive.
+func CacheTTL(ttl time.Duration) Option {
+	return func(cli *SDK) {
+		cli.ttl = ttl
+	}
+}
+
+func NewSDK(endpoint string, maxRetries int, opts ...Option) *SDK {
 	slash := "/"
 	if !strings.HasSuffix(endpoint, slash) {
 		endpoint += slash
 	}
 
-	return &SDK{
+	cli := &SDK{
 		hc:       utils.NewHttpClient(maxRetries),
 		endpoint: endpoint,
+		ttl:      defaultCacheTTL,
+		cache:    utils.NewTTLCache[cacheItem](),
+	}
+
+	for _, o := range opts {
+		o(cli)
 	}
+
+	return cli
 }
 
+// SDK is the client of sig-info-cache. The results are cached for a while, and they
+// are read from the community repository directly if the fallback is set and the
+// service is unavailable. It is safe for concurrent use, and the results returned
+// are the copies of the cached ones.
 type SDK struct {
 	hc       utils.HttpClient
 	endpoint string
+
+	// ttl is not changed after NewSDK, so that it is read without lock.
+	ttl time.Duration
+
+	cache *utils.TTLCache[cacheItem]
+
+	fallback *fallback
 }
 
-func (cli *SDK) GetSigInfo(urlPath string) (string, error) {
+type cacheItem struct {
+	data interface{}
+	err  error
+}
 
-	req, err := http.NewRequest(http.MethodGet, cli.endpoint+urlPath, nil)
+// SetFallback reads the sig info from the branch of community repository org/repo by
+// c when the service is unavailable. The index built is reused until the ttl of cache expires.
+// It should be called before the SDK is used.
+func (cli *SDK) SetFallback(c Client, org, repo, branch string) {
+	cli.fallback = &fallback{cli: c, org: org, repo: repo, branch: branch}
+}
+
+// GetSigOfRepo returns the sig which manages org/repo.
+func (cli *SDK) GetSigOfRepo(org, repo string) (*RepoSig, error) {
+	v, err := cli.get(
+		fmt.Sprintf("repos/%s/%s/sig", org, repo),
+		func() interface{} { return new(RepoSig) },
+		func(idx *Index) (interface{}, bool) { return idx.RepoSig(org, repo) },
+	)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	return v.(*RepoSig).clone(), nil
+}
+
+// GetSigMembers returns the maintainers, committers and mentors of sig.
+func (cli *SDK) GetSigMembers(sig string) (*SigMembers, error) {
+	v, err := cli.get(
+		fmt.Sprintf("sigs/%s/members", sig),
+		func() interface{} { return new(SigMembers) },
+		func(idx *Index) (interface{}, bool) { return idx.SigMembers(sig) },
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.(*SigMembers).clone(), nil
+}
+
+// GetUserSigs returns the sigs which the user belongs to.
+func (cli *SDK) GetUserSigs(login string) ([]string, error) {
+	v, err := cli.get(
+		fmt.Sprintf("users/%s/sigs", login),
+		func() interface{} { return new([]string) },
+		func(idx *Index) (interface{}, bool) { v := idx.UserSigs(login); return &v, true },
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return cloneStrings(*(v.(*[]string))), nil
+}
+
+// GetBranchKeepers returns the keepers of branch, which include the maintainers of sig.
+func (cli *SDK) GetBranchKeepers(org, repo, branch string) ([]string, error) {
+	v, err := cli.get(
+		fmt.Sprintf("repos/%s/%s/branch-keepers?branch=%s", org, repo, url.QueryEscape(branch)),
+		func() interface{} { return new([]string) },
+		func(idx *Index) (interface{}, bool) {
+			v, ok := idx.BranchKeepers(org, repo, branch)
+			return &v, ok
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return cloneStrings(*(v.(*[]string))), nil
+}
+
+// get returns the data of urlPath in the cache, or fetches it from the service
+// and then the fallback. newData returns a pointer of data to decode the response,
+// and query queries the same data from the index of fallback.
+func (cli *SDK) get(
+	urlPath string, newData func() interface{}, query func(*Index) (interface{}, bool),
+) (interface{}, error) {
+	if v, ok := cli.cache.Get(urlPath); ok {
+		return v.data, v.err
 	}
 
-	var v struct {
-		Data map[string]string `json:"data"`
+	data := newData()
+
+	err := cli.fetch(urlPath, data)
+	if err != nil && !errors.Is(err, ErrNotFound) && cli.fallback != nil {
+		logrus.WithError(err).Warnf("read %s from the community repository", urlPath)
+
+		var idx *Index
+		if idx, err = cli.fallback.index(cli.ttl); err == nil {
+			var ok bool
+			if data, ok = query(idx); !ok {
+				err = ErrNotFound
+			}
+		}
 	}
 
-	if err = cli.forwardTo(req, &v); err != nil {
-		return "", err
+	if err == nil || errors.Is(err, ErrNotFound) {
+		cli.cache.Set(urlPath, cacheItem{data: data, err: err}, cli.ttl)
 	}
 
-	return v.Data["111"], nil
+	return data, err
 }
 
-func (cli *SDK) forwardTo(req *http.Request, jsonResp interface{}) error {
-	req.Header.Set("Content-Type", "application/json")
+// fetch reads the data of urlPath from the service. It returns ErrNotFound only if the
+// service says so, and the other 404s mean the endpoint is wrong.
+func (cli *SDK) fetch(urlPath string, data interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, cli.endpoint+urlPath, nil)
+	if err != nil {
+		return err
+	}
+
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", "sig-info-cache-sdk")
 
-	_, err := cli.hc.ForwardTo(req, jsonResp)
+	resp, err := cli.hc.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	v := struct {
+		Data interface{} `json:"data"`
+		Code string      `json:"code"`
+	}{Data: data}
+
+	if code := resp.StatusCode; code < 200 || code > 299 {
+		if code == http.StatusNotFound && json.Unmarshal(body, &v) == nil && v.Code == CodeNotFound {
+			return ErrNotFound
+		}
+
+		return fmt.Errorf("response has status:%s and body:%q", resp.Status, body)
+	}
+
+	return json.Unmarshal(body, &v)
+}
+
+// fallback loads the index from the community repository.
+type fallback struct {
+	cli    Client
+	org    string
+	repo   string
+	branch string
+
+	mut      sync.Mutex
+	idx      *Index
+	blobs    map[string][]byte
+	loadedAt time.Time
+}
+
+func (f *fallback) index(ttl time.Duration) (*Index, error) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	if f.idx != nil && time.Since(f.loadedAt) < ttl {
+		return f.idx, nil
+	}
+
+	idx, blobs, err := LoadIndex(f.cli, f.org, f.repo, f.branch, f.blobs)
+	if idx == nil {
+		return nil, err
+	}
+
+	if err != nil {
+		logrus.WithError(err).Warn("some sig files are invalid")
+	}
+
+	f.idx, f.blobs, f.loadedAt = idx, blobs, time.Now()
 
-	return err
+	return idx, nil
 }
@@ -0,0 +1,105 @@
+package sdk_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/opensourceways/community-robot-lib/atomgitclient"
+	"github.com/opensourceways/community-robot-lib/fakeatomgit"
+
+	"github.com/opensourceways/atomgit-sig-file-cache/sdk"
+)
+
+func TestSDK(t *testing.T) {
+	calls := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		switch r.URL.Path {
+		case "/sigs/Kernel/members":
+			_, _ = w.Write([]byte(`{"data":{"sig":"Kernel","maintainers":["dave"]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code":"not_found","msg":"not found"}`))
+		}
+	}))
+	defer s.Close()
+
+	cli := sdk.NewSDK(s.URL, 3)
+
+	for i := 0; i < 2; i++ {
+		m, err := cli.GetSigMembers("Kernel")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(m.Maintainers, []string{"dave"}) {
+			t.Errorf("maintainers = %v", m.Maintainers)
+		}
+
+		// the cached members are not changed by the caller.
+		m.Maintainers[0] = "eve"
+	}
+
+	if _, err := cli.GetSigOfRepo("openeuler", "unknown"); !errors.Is(err, sdk.ErrNotFound) {
+		t.Errorf("err = %v, want not found", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("the service is called %d times, the members should be cached", calls)
+	}
+}
+
+func TestSDKFallback(t *testing.T) {
+	s := fakeatomgit.NewServer("robot")
+	s.SetFile("openeuler", "community", "master", "sig/Kernel/OWNERS", []byte("maintainers: [dave]"))
+
+	api := httptest.NewServer(s)
+	defer api.Close()
+
+	c, err := atomgitclient.NewClientWithEndpoint(func() []byte { return []byte("token") }, api.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the service is unavailable.
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	cli := sdk.NewSDK(down.URL, 1)
+	cli.SetFallback(c, "openeuler", "community", "master")
+
+	v, err := cli.GetUserSigs("Dave")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(v, []string{"Kernel"}) {
+		t.Errorf("sigs = %v", v)
+	}
+}
+
+func TestSDKWrongEndpoint(t *testing.T) {
+	calls := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		http.NotFound(w, r)
+	}))
+	defer s.Close()
+
+	cli := sdk.NewSDK(s.URL+"/v1/file", 1)
+
+	for i := 0; i < 2; i++ {
+		if _, err := cli.GetSigOfRepo("openeuler", "community"); err == nil || errors.Is(err, sdk.ErrNotFound) {
+			t.Errorf("the 404 of wrong endpoint should not mean not found, err = %v", err)
+		}
+	}
+
+	if calls != 2 {
+		t.Errorf("the service is called %d times, the error should not be cached", calls)
+	}
+}
@@ -0,0 +1,130 @@
+package sdk
+
+// SigInfo is the content of sig/<sig>/sig-info.yaml in the community repository.
+type SigInfo struct {
+	Name         string      `json:"name,omitempty"`
+	Description  string      `json:"description,omitempty"`
+	MailingList  string      `json:"mailing_list,omitempty"`
+	MeetingURL   string      `json:"meeting_url,omitempty"`
+	MatureLevel  string      `json:"mature_level,omitempty"`
+	Mentors      []Member    `json:"mentors,omitempty"`
+	Maintainers  []Member    `json:"maintainers,omitempty"`
+	Repositories []RepoAdmin `json:"repositories,omitempty"`
+	Branches     []Branches  `json:"branches,omitempty"`
+}
+
+// Member is a person of sig, such as a maintainer, committer or mentor.
+type Member struct {
+	GiteeID      string `json:"gitee_id,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Organization string `json:"organization,omitempty"`
+	Email        string `json:"email,omitempty"`
+}
+
+// RepoAdmin is the people of repositories managed by sig.
+type RepoAdmin struct {
+	// Repo is in the form of org/repo.
+	Repo         []string `json:"repo,omitempty"`
+	Admins       []Member `json:"admins,omitempty"`
+	Committers   []Member `json:"committers,omitempty"`
+	Contributors []Member `json:"contributors,omitempty"`
+}
+
+// Branches is the keepers of branches.
+type Branches struct {
+	RepoBranch []RepoBranch `json:"repo_branch,omitempty"`
+	Keeper     []Member     `json:"keeper,omitempty"`
+}
+
+// RepoBranch is a branch of repository which is in the form of org/repo.
+type RepoBranch struct {
+	Repo   string `json:"repo"`
+	Branch string `json:"branch"`
+}
+
+// SigMembers is the people of sig collected from sig-info.yaml and OWNERS.
+// The logins are in lower case.
+type SigMembers struct {
+	Sig         string   `json:"sig"`
+	Maintainers []string `json:"maintainers"`
+	Committers  []string `json:"committers"`
+	Mentors     []string `json:"mentors"`
+}
+
+// RepoSig is the sig which manages the repository, and the committers of it.
+type RepoSig struct {
+	// Repo is in the form of org/repo.
+	Repo       string   `json:"repo"`
+	Sig        string   `json:"sig"`
+	Committers []string `json:"committers"`
+
+	Info *SigInfo `json:"info"`
+}
+
+// clone returns the deep copy of r, so that the one in cache is not changed by the caller.
+func (r *RepoSig) clone() *RepoSig {
+	v := *r
+	v.Committers = cloneStrings(r.Committers)
+	v.Info = r.Info.clone()
+
+	return &v
+}
+
+func (m *SigMembers) clone() *SigMembers {
+	v := *m
+	v.Maintainers = cloneStrings(m.Maintainers)
+	v.Committers = cloneStrings(m.Committers)
+	v.Mentors = cloneStrings(m.Mentors)
+
+	return &v
+}
+
+func (s *SigInfo) clone() *SigInfo {
+	if s == nil {
+		return nil
+	}
+
+	v := *s
+	v.Mentors = cloneMembers(s.Mentors)
+	v.Maintainers = cloneMembers(s.Maintainers)
+
+	if s.Repositories != nil {
+		v.Repositories = make([]RepoAdmin, len(s.Repositories))
+		for i, r := range s.Repositories {
+			v.Repositories[i] = RepoAdmin{
+				Repo:         cloneStrings(r.Repo),
+				Admins:       cloneMembers(r.Admins),
+				Committers:   cloneMembers(r.Committers),
+				Contributors: cloneMembers(r.Contributors),
+			}
+		}
+	}
+
+	if s.Branches != nil {
+		v.Branches = make([]Branches, len(s.Branches))
+		for i, b := range s.Branches {
+			v.Branches[i] = Branches{
+				RepoBranch: append([]RepoBranch(nil), b.RepoBranch...),
+				Keeper:     cloneMembers(b.Keeper),
+			}
+		}
+	}
+
+	return &v
+}
+
+func cloneStrings(v []string) []string {
+	if v == nil {
+		return nil
+	}
+
+	return append([]string{}, v...)
+}
+
+func cloneMembers(v []Member) []Member {
+	if v == nil {
+		return nil
+	}
+
+	return append([]Member{}, v...)
+}
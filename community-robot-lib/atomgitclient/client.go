@@ -3,30 +3,74 @@ package atomgitclient
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"golang.org/x/oauth2"
 
 	"github.com/opensourceways/go-atomgit/atomgit"
+
+	"github.com/opensourceways/community-robot-lib/tracing"
 )
 
 type client struct {
 	c *atomgit.Client
+
+	// ctx is the context of requests, which carries the trace of event being handled.
+	ctx context.Context
 }
 
 func NewClient(getToken func() []byte) Client {
+	return client{c: atomgit.NewClient(newHTTPClient(getToken)), ctx: context.Background()}
+}
+
+// NewClientWithEndpoint creates a client which calls the api served at endpoint
+// instead of AtomGit, such as a fake server used by tests.
+func NewClientWithEndpoint(getToken func() []byte, endpoint string) (Client, error) {
+	if !strings.HasSuffix(endpoint, "/") {
+		endpoint += "/"
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	c := atomgit.NewClient(newHTTPClient(getToken))
+	c.BaseURL = u
+
+	return client{c: c, ctx: context.Background()}, nil
+}
+
+// newHTTPClient creates the http client which authorizes the requests by the token,
+// and records a span for each of them.
+func newHTTPClient(getToken func() []byte) *http.Client {
 	ts := oauth2.StaticTokenSource(&oauth2.Token{
 		AccessToken: string(getToken()),
 	})
-	tc := oauth2.NewClient(context.Background(), ts)
 
-	return client{atomgit.NewClient(tc)}
+	base := &http.Client{Transport: tracing.Transport(http.DefaultTransport)}
+
+	return oauth2.NewClient(context.WithValue(context.Background(), oauth2.HTTPClient, base), ts)
+}
+
+// WithContext returns the client which sends the requests with ctx, so that the spans
+// of requests are the children of the one in ctx.
+func (cl client) WithContext(ctx context.Context) Client {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	cl.ctx = ctx
+
+	return cl
 }
 
 func (cl client) AddPRLabel(pr *PRIssue, label string) error {
 	_, _, err := cl.c.Issues.AddLabelsToIssue(
-		context.Background(),
+		cl.ctx,
 		pr.Org, pr.Repo, pr.Number, []string{label},
 	)
 
@@ -35,7 +79,7 @@ func (cl client) AddPRLabel(pr *PRIssue, label string) error {
 
 func (cl client) RemovePRLabel(pr *PRIssue, label string) error {
 	r, err := cl.c.Issues.RemoveLabelForIssue(
-		context.Background(),
+		cl.ctx,
 		pr.Org, pr.Repo, pr.Number, label,
 	)
 	if err != nil && r != nil && r.StatusCode == 404 {
@@ -50,7 +94,7 @@ func (cl client) CreatePRComment(pr *PRIssue, comment string) error {
 		Body: atomgit.String(comment),
 	}
 	_, _, err := cl.c.PullRequests.CreateComment(
-		context.Background(),
+		cl.ctx,
 		pr.Org, pr.Repo, pr.Number, &ic,
 	)
 
@@ -59,7 +103,7 @@ func (cl client) CreatePRComment(pr *PRIssue, comment string) error {
 
 func (cl client) CreatePRCommentReply(pr *PRIssue, comment, commentID string) error {
 	_, _, err := cl.c.PullRequests.CreateCommentInReplyTo(
-		context.Background(),
+		cl.ctx,
 		pr.Org, pr.Repo, pr.Number, comment, commentID,
 	)
 
@@ -67,7 +111,7 @@ func (cl client) CreatePRCommentReply(pr *PRIssue, comment, commentID string) er
 }
 
 func (cl client) DeletePRComment(org, repo, commentId string) error {
-	_, err := cl.c.PullRequests.DeleteComment(context.Background(), org, repo, commentId)
+	_, err := cl.c.PullRequests.DeleteComment(cl.ctx, org, repo, commentId)
 
 	return err
 }
@@ -79,7 +123,7 @@ func (cl client) GetPRComments(pr *PRIssue) ([]*atomgit.PullRequestComment, erro
 	opt.Page = 1
 
 	for {
-		v, resp, err := cl.c.PullRequests.ListComments(context.Background(), pr.Org, pr.Repo, pr.Number, opt)
+		v, resp, err := cl.c.PullRequests.ListComments(cl.ctx, pr.Org, pr.Repo, pr.Number, opt)
 		if err != nil {
 			return comments, err
 		}
@@ -119,7 +163,7 @@ func (cl client) GetPRCommits(pr *PRIssue) ([]*atomgit.RepositoryCommit, error)
 		opt.Page = 1
 
 		for {
-			v, resp, err := cl.c.PullRequests.ListCommits(context.Background(), pr.Org, pr.Repo, pr.Number, nil)
+			v, resp, err := cl.c.PullRequests.ListCommits(cl.ctx, pr.Org, pr.Repo, pr.Number, nil)
 			if err != nil {
 				return err
 			}
@@ -159,7 +203,7 @@ func (cl client) GetPRCommits(pr *PRIssue) ([]*atomgit.RepositoryCommit, error)
 
 func (cl client) UpdatePR(pr *PRIssue, request *atomgit.PullRequest) (*atomgit.PullRequest, error) {
 
-	pull, _, err := cl.c.PullRequests.Edit(context.Background(), pr.Org, pr.Repo, pr.Number, request)
+	pull, _, err := cl.c.PullRequests.Edit(cl.ctx, pr.Org, pr.Repo, pr.Number, request)
 	if err != nil {
 		return nil, err
 	}
@@ -174,7 +218,7 @@ func (cl client) GetPullRequests(pr *PRIssue) ([]*atomgit.PullRequest, error) {
 		opt.Page = 1
 
 		for {
-			v, resp, err := cl.c.PullRequests.List(context.Background(), pr.Org, pr.Repo,
+			v, resp, err := cl.c.PullRequests.List(cl.ctx, pr.Org, pr.Repo,
 				&atomgit.PullRequestListOptions{ListOptions: *opt})
 			if err != nil {
 				return err
@@ -221,7 +265,7 @@ func (cl client) ListCollaborator(pr *PRIssue) ([]*atomgit.User, error) {
 		opt.Page = 1
 
 		for {
-			v, resp, err := cl.c.Repositories.ListCollaborators(context.Background(), pr.Org, pr.Repo,
+			v, resp, err := cl.c.Repositories.ListCollaborators(cl.ctx, pr.Org, pr.Repo,
 				&atomgit.ListCollaboratorsOptions{ListOptions: *opt})
 			if err != nil {
 				return err
@@ -261,7 +305,7 @@ func (cl client) ListCollaborator(pr *PRIssue) ([]*atomgit.User, error) {
 }
 
 func (cl client) IsCollaborator(pr *PRIssue, login string) (bool, error) {
-	b, _, err := cl.c.Repositories.IsCollaborator(context.Background(), pr.Org, pr.Repo, login)
+	b, _, err := cl.c.Repositories.IsCollaborator(cl.ctx, pr.Org, pr.Repo, login)
 	if err != nil {
 		return false, err
 	}
@@ -269,7 +313,7 @@ func (cl client) IsCollaborator(pr *PRIssue, login string) (bool, error) {
 }
 
 func (cl client) RemoveRepoMember(pr *PRIssue, login string) error {
-	_, err := cl.c.Repositories.RemoveCollaborator(context.Background(), pr.Org, pr.Repo, login)
+	_, err := cl.c.Repositories.RemoveCollaborator(cl.ctx, pr.Org, pr.Repo, login)
 	if err != nil {
 		return err
 	}
@@ -278,7 +322,7 @@ func (cl client) RemoveRepoMember(pr *PRIssue, login string) error {
 }
 
 func (cl client) AddRepoMember(pr *PRIssue, login, permission string) error {
-	_, _, err := cl.c.Repositories.AddCollaborator(context.Background(), pr.Org, pr.Repo, login,
+	_, _, err := cl.c.Repositories.AddCollaborator(cl.ctx, pr.Org, pr.Repo, login,
 		&atomgit.RepositoryAddCollaboratorOptions{Permission: permission})
 	if err != nil {
 		return err
@@ -295,7 +339,7 @@ func (cl client) GetPullRequestChanges(pr *PRIssue) ([]*atomgit.CommitFile, erro
 		opt.Page = 1
 
 		for {
-			v, resp, err := cl.c.PullRequests.ListFiles(context.Background(), pr.Org, pr.Repo, pr.Number, opt)
+			v, resp, err := cl.c.PullRequests.ListFiles(cl.ctx, pr.Org, pr.Repo, pr.Number, opt)
 			if err != nil {
 				return err
 			}
@@ -334,12 +378,12 @@ func (cl client) GetPullRequestChanges(pr *PRIssue) ([]*atomgit.CommitFile, erro
 }
 
 func (cl client) GetPRLabels(pr *PRIssue) ([]string, error) {
-	pull, _, err := cl.c.PullRequests.Get(context.Background(), pr.Org, pr.Repo, pr.Number)
+	pull, _, err := cl.c.PullRequests.Get(cl.ctx, pr.Org, pr.Repo, pr.Number)
 	if err != nil {
 		return nil, err
 	}
 
-	labels := make([]string, len(pull.Labels))
+	labels := make([]string, 0, len(pull.Labels))
 	for _, p := range pull.Labels {
 		labels = append(labels, *p.Name)
 	}
@@ -354,7 +398,7 @@ func (cl client) GetRepositoryLabels(pr *PRIssue) ([]string, error) {
 		opt.Page = 1
 
 		for {
-			v, resp, err := cl.c.Issues.ListLabels(context.Background(), pr.Org, pr.Repo, opt)
+			v, resp, err := cl.c.Issues.ListLabels(cl.ctx, pr.Org, pr.Repo, opt)
 			if err != nil {
 				return err
 			}
@@ -391,7 +435,7 @@ func (cl client) GetRepositoryLabels(pr *PRIssue) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	labels := make([]string, len(rLabels))
+	labels := make([]string, 0, len(rLabels))
 	for _, r := range rLabels {
 		labels = append(labels, *r.Name)
 	}
@@ -400,7 +444,7 @@ func (cl client) GetRepositoryLabels(pr *PRIssue) ([]string, error) {
 }
 
 func (cl client) UpdatePRComment(pr *PRIssue, commentID int64, ic *atomgit.IssueComment) error {
-	_, _, err := cl.c.Issues.EditComment(context.Background(), pr.Org, pr.Repo, commentID, ic)
+	_, _, err := cl.c.Issues.EditComment(cl.ctx, pr.Org, pr.Repo, commentID, ic)
 	if err != nil {
 		return err
 	}
@@ -410,7 +454,7 @@ func (cl client) UpdatePRComment(pr *PRIssue, commentID int64, ic *atomgit.Issue
 
 func (cl client) ClosePR(pr *PRIssue) error {
 	action := ActionClosed
-	_, _, err := cl.c.PullRequests.Edit(context.Background(), pr.Org, pr.Repo, pr.Number, &atomgit.PullRequest{State: &action})
+	_, _, err := cl.c.PullRequests.Edit(cl.ctx, pr.Org, pr.Repo, pr.Number, &atomgit.PullRequest{State: &action})
 	if err != nil {
 		return err
 	}
@@ -420,7 +464,7 @@ func (cl client) ClosePR(pr *PRIssue) error {
 
 func (cl client) ReopenPR(pr *PRIssue) error {
 	action := "open"
-	_, _, err := cl.c.PullRequests.Edit(context.Background(), pr.Org, pr.Repo, pr.Number, &atomgit.PullRequest{State: &action})
+	_, _, err := cl.c.PullRequests.Edit(cl.ctx, pr.Org, pr.Repo, pr.Number, &atomgit.PullRequest{State: &action})
 	if err != nil {
 		return err
 	}
@@ -429,7 +473,7 @@ func (cl client) ReopenPR(pr *PRIssue) error {
 }
 
 func (cl client) AssignPR(pr *PRIssue, logins []string) error {
-	_, _, err := cl.c.Issues.AddAssignees(context.Background(), pr.Org, pr.Repo, pr.Number, logins)
+	_, _, err := cl.c.Issues.AddAssignees(cl.ctx, pr.Org, pr.Repo, pr.Number, logins)
 	if err != nil {
 		return err
 	}
@@ -438,7 +482,7 @@ func (cl client) AssignPR(pr *PRIssue, logins []string) error {
 }
 
 func (cl client) UnAssignPR(pr *PRIssue, logins []string) error {
-	_, _, err := cl.c.Issues.RemoveAssignees(context.Background(), pr.Org, pr.Repo, pr.Number, logins)
+	_, _, err := cl.c.Issues.RemoveAssignees(cl.ctx, pr.Org, pr.Repo, pr.Number, logins)
 	if err != nil {
 		return err
 	}
@@ -448,7 +492,7 @@ func (cl client) UnAssignPR(pr *PRIssue, logins []string) error {
 
 func (cl client) CloseIssue(pr *PRIssue) error {
 	action := ActionClosed
-	_, _, err := cl.c.Issues.Edit(context.Background(), pr.Org, pr.Repo, pr.Number, &atomgit.IssueRequest{State: &action})
+	_, _, err := cl.c.Issues.Edit(cl.ctx, pr.Org, pr.Repo, pr.Number, &atomgit.IssueRequest{State: &action})
 	if err != nil {
 		return err
 	}
@@ -458,7 +502,7 @@ func (cl client) CloseIssue(pr *PRIssue) error {
 
 func (cl client) ReopenIssue(pr *PRIssue) error {
 	action := "open"
-	_, _, err := cl.c.Issues.Edit(context.Background(), pr.Org, pr.Repo, pr.Number, &atomgit.IssueRequest{State: &action})
+	_, _, err := cl.c.Issues.Edit(cl.ctx, pr.Org, pr.Repo, pr.Number, &atomgit.IssueRequest{State: &action})
 	if err != nil {
 		return err
 	}
@@ -467,7 +511,7 @@ func (cl client) ReopenIssue(pr *PRIssue) error {
 }
 
 func (cl client) MergePR(pr *PRIssue, commitMessage string, opt *atomgit.PullRequestOptions) error {
-	_, _, err := cl.c.PullRequests.Merge(context.Background(), pr.Org, pr.Repo, pr.Number, commitMessage, opt)
+	_, _, err := cl.c.PullRequests.Merge(cl.ctx, pr.Org, pr.Repo, pr.Number, commitMessage, opt)
 	if err != nil {
 		return err
 	}
@@ -483,7 +527,7 @@ func (cl client) GetRepos(org string) ([]*atomgit.Repository, error) {
 		opt.PerPage = 100
 
 		for {
-			v, resp, err := cl.c.Repositories.ListByOrg(context.Background(), org, &atomgit.RepositoryListByOrgOptions{ListOptions: *opt})
+			v, resp, err := cl.c.Repositories.ListByOrg(cl.ctx, org, &atomgit.RepositoryListByOrgOptions{ListOptions: *opt})
 			if err != nil {
 				return err
 			}
@@ -522,7 +566,7 @@ func (cl client) GetRepos(org string) ([]*atomgit.Repository, error) {
 }
 
 func (cl client) GetRepo(org, repo string) (*atomgit.Repository, error) {
-	r, _, err := cl.c.Repositories.Get(context.Background(), org, repo)
+	r, _, err := cl.c.Repositories.Get(cl.ctx, org, repo)
 	if err != nil {
 		return nil, err
 	}
@@ -531,7 +575,7 @@ func (cl client) GetRepo(org, repo string) (*atomgit.Repository, error) {
 }
 
 func (cl client) CreateRepo(org string, r *atomgit.Repository) error {
-	_, _, err := cl.c.Repositories.Create(context.Background(), org, r)
+	_, _, err := cl.c.Repositories.Create(cl.ctx, org, r)
 	if err != nil {
 		return err
 	}
@@ -540,7 +584,7 @@ func (cl client) CreateRepo(org string, r *atomgit.Repository) error {
 }
 
 func (cl client) UpdateRepo(org, repo string, r *atomgit.Repository) error {
-	_, _, err := cl.c.Repositories.Edit(context.Background(), org, repo, r)
+	_, _, err := cl.c.Repositories.Edit(cl.ctx, org, repo, r)
 	if err != nil {
 		return err
 	}
@@ -549,7 +593,7 @@ func (cl client) UpdateRepo(org, repo string, r *atomgit.Repository) error {
 }
 
 func (cl client) CreateRepoLabel(org, repo, label string) error {
-	_, _, err := cl.c.Issues.CreateLabel(context.Background(), org, repo, &atomgit.Label{Name: &label})
+	_, _, err := cl.c.Issues.CreateLabel(cl.ctx, org, repo, &atomgit.Label{Name: &label})
 	if err != nil {
 		return err
 	}
@@ -564,7 +608,7 @@ func (cl client) GetRepoLabels(org, repo string) ([]string, error) {
 		opt.Page = 1
 
 		for {
-			v, resp, err := cl.c.Issues.ListLabels(context.Background(), org, repo, opt)
+			v, resp, err := cl.c.Issues.ListLabels(cl.ctx, org, repo, opt)
 			if err != nil {
 				return err
 			}
@@ -602,7 +646,7 @@ func (cl client) GetRepoLabels(org, repo string) ([]string, error) {
 		return nil, err
 	}
 
-	labels := make([]string, len(lbs))
+	labels := make([]string, 0, len(lbs))
 	for _, l := range lbs {
 		labels = append(labels, *l.Name)
 	}
@@ -611,7 +655,7 @@ func (cl client) GetRepoLabels(org, repo string) ([]string, error) {
 }
 
 func (cl client) AssignSingleIssue(is *PRIssue, login string) error {
-	_, _, err := cl.c.Issues.AddAssignees(context.Background(), is.Org, is.Repo, is.Number, []string{login})
+	_, _, err := cl.c.Issues.AddAssignees(cl.ctx, is.Org, is.Repo, is.Number, []string{login})
 	if err != nil {
 		return err
 	}
@@ -620,7 +664,7 @@ func (cl client) AssignSingleIssue(is *PRIssue, login string) error {
 }
 
 func (cl client) UnAssignSingleIssue(is *PRIssue, login string) error {
-	_, _, err := cl.c.Issues.RemoveAssignees(context.Background(), is.Org, is.Repo, is.Number, []string{login})
+	_, _, err := cl.c.Issues.RemoveAssignees(cl.ctx, is.Org, is.Repo, is.Number, []string{login})
 	if err != nil {
 		return err
 	}
@@ -632,7 +676,7 @@ func (cl client) CreateIssueComment(is *PRIssue, comment string) error {
 	ic := atomgit.IssueComment{
 		Body: atomgit.String(comment),
 	}
-	_, _, err := cl.c.Issues.CreateComment(context.Background(), is.Org, is.Repo, is.Number, &ic)
+	_, _, err := cl.c.Issues.CreateComment(cl.ctx, is.Org, is.Repo, is.Number, &ic)
 	if err != nil {
 		return err
 	}
@@ -641,7 +685,7 @@ func (cl client) CreateIssueComment(is *PRIssue, comment string) error {
 }
 
 func (cl client) UpdateIssueComment(is *PRIssue, commentID int64, c *atomgit.IssueComment) error {
-	_, _, err := cl.c.Issues.EditComment(context.Background(), is.Org, is.Repo, commentID, c)
+	_, _, err := cl.c.Issues.EditComment(cl.ctx, is.Org, is.Repo, commentID, c)
 	if err != nil {
 		return err
 	}
@@ -656,7 +700,7 @@ func (cl client) ListIssueComments(is *PRIssue) ([]*atomgit.IssueComment, error)
 	opt.Page = 1
 
 	for {
-		v, resp, err := cl.c.Issues.ListComments(context.Background(), is.Org, is.Repo, is.Number, opt)
+		v, resp, err := cl.c.Issues.ListComments(cl.ctx, is.Org, is.Repo, is.Number, opt)
 		if err != nil {
 			return comments, err
 		}
@@ -689,7 +733,7 @@ func (cl client) ListIssueComments(is *PRIssue) ([]*atomgit.IssueComment, error)
 }
 
 func (cl client) RemoveIssueLabel(is *PRIssue, label string) error {
-	_, err := cl.c.Issues.RemoveLabelForIssue(context.Background(), is.Org, is.Repo, is.Number, label)
+	_, err := cl.c.Issues.RemoveLabelForIssue(cl.ctx, is.Org, is.Repo, is.Number, label)
 	if err != nil {
 		return err
 	}
@@ -698,7 +742,7 @@ func (cl client) RemoveIssueLabel(is *PRIssue, label string) error {
 }
 
 func (cl client) AddIssueLabel(is *PRIssue, label []string) error {
-	_, _, err := cl.c.Issues.AddLabelsToIssue(context.Background(), is.Org, is.Repo, is.Number, label)
+	_, _, err := cl.c.Issues.AddLabelsToIssue(cl.ctx, is.Org, is.Repo, is.Number, label)
 	if err != nil {
 		return err
 	}
@@ -713,7 +757,7 @@ func (cl client) GetIssueLabels(is *PRIssue) ([]string, error) {
 		opt.Page = 1
 
 		for {
-			v, resp, err := cl.c.Issues.ListLabelsByIssue(context.Background(), is.Org, is.Repo, is.Number, opt)
+			v, resp, err := cl.c.Issues.ListLabelsByIssue(cl.ctx, is.Org, is.Repo, is.Number, opt)
 			if err != nil {
 				return err
 			}
@@ -751,7 +795,7 @@ func (cl client) GetIssueLabels(is *PRIssue) ([]string, error) {
 		return nil, err
 	}
 
-	labels := make([]string, len(lbs))
+	labels := make([]string, 0, len(lbs))
 	for _, l := range lbs {
 		labels = append(labels, *l.Name)
 	}
@@ -760,7 +804,7 @@ func (cl client) GetIssueLabels(is *PRIssue) ([]string, error) {
 }
 
 func (cl client) UpdateIssue(is *PRIssue, iss *atomgit.IssueRequest) error {
-	_, _, err := cl.c.Issues.Edit(context.Background(), is.Org, is.Repo, is.Number, iss)
+	_, _, err := cl.c.Issues.Edit(cl.ctx, is.Org, is.Repo, is.Number, iss)
 	if err != nil {
 		return err
 	}
@@ -769,7 +813,7 @@ func (cl client) UpdateIssue(is *PRIssue, iss *atomgit.IssueRequest) error {
 }
 
 func (cl client) GetSingleIssue(is *PRIssue) (*atomgit.Issue, error) {
-	issue, _, err := cl.c.Issues.Get(context.Background(), is.Org, is.Repo, is.Number)
+	issue, _, err := cl.c.Issues.Get(cl.ctx, is.Org, is.Repo, is.Number)
 	if err != nil {
 		return nil, err
 	}
@@ -777,6 +821,15 @@ func (cl client) GetSingleIssue(is *PRIssue) (*atomgit.Issue, error) {
 	return issue, nil
 }
 
+func (cl client) SearchIssues(query string) (*atomgit.IssuesSearchResult, error) {
+	v, _, err := cl.c.Search.Issues(cl.ctx, query, &atomgit.SearchOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
 func (cl client) ListBranches(org, repo string) ([]*atomgit.Branch, error) {
 	var brs []*atomgit.Branch
 	f := func() error {
@@ -784,7 +837,7 @@ func (cl client) ListBranches(org, repo string) ([]*atomgit.Branch, error) {
 		opt.Page = 1
 
 		for {
-			v, resp, err := cl.c.Repositories.ListBranches(context.Background(), org, repo,
+			v, resp, err := cl.c.Repositories.ListBranches(cl.ctx, org, repo,
 				&atomgit.BranchListOptions{ListOptions: *opt})
 			if err != nil {
 				return err
@@ -832,7 +885,7 @@ func (cl client) ListBranches(org, repo string) ([]*atomgit.Branch, error) {
 }
 
 func (cl client) SetProtectionBranch(org, repo, branch string, pre *atomgit.ProtectionRequest) error {
-	_, _, err := cl.c.Repositories.UpdateBranchProtection(context.Background(), org, repo, branch, pre)
+	_, _, err := cl.c.Repositories.UpdateBranchProtection(cl.ctx, org, repo, branch, pre)
 	if err != nil {
 		return err
 	}
@@ -841,7 +894,7 @@ func (cl client) SetProtectionBranch(org, repo, branch string, pre *atomgit.Prot
 }
 
 func (cl client) RemoveProtectionBranch(org, repo, branch string) error {
-	_, err := cl.c.Repositories.RemoveBranchProtection(context.Background(), org, repo, branch)
+	_, err := cl.c.Repositories.RemoveBranchProtection(cl.ctx, org, repo, branch)
 	if err != nil {
 		return err
 	}
@@ -850,7 +903,7 @@ func (cl client) RemoveProtectionBranch(org, repo, branch string) error {
 }
 
 func (cl client) GetDirectoryTree(org, repo, branch string, recursive bool) ([]*atomgit.TreeEntry, error) {
-	trees, _, err := cl.c.Git.GetTree(context.Background(), org, repo, branch, recursive)
+	trees, _, err := cl.c.Git.GetTree(cl.ctx, org, repo, branch, recursive)
 	if err != nil {
 		return nil, err
 	}
@@ -859,7 +912,7 @@ func (cl client) GetDirectoryTree(org, repo, branch string, recursive bool) ([]*
 }
 
 func (cl client) GetPathContent(org, repo, path, branch string) (*atomgit.RepositoryContent, error) {
-	fc, _, _, err := cl.c.Repositories.GetContents(context.Background(), org, repo, path,
+	fc, _, _, err := cl.c.Repositories.GetContents(cl.ctx, org, repo, path,
 		&atomgit.RepositoryContentGetOptions{Ref: branch})
 	if err != nil {
 		return nil, err
@@ -869,7 +922,7 @@ func (cl client) GetPathContent(org, repo, path, branch string) (*atomgit.Reposi
 }
 
 func (cl client) CreateFile(org, repo, path, branch, commitMSG, sha string, content []byte) error {
-	_, _, err := cl.c.Repositories.CreateFile(context.Background(), org, repo, path,
+	_, _, err := cl.c.Repositories.CreateFile(cl.ctx, org, repo, path,
 		&atomgit.RepositoryContentFileOptions{Content: content, Message: &commitMSG, Branch: &branch, SHA: &sha})
 
 	if err != nil {
@@ -880,7 +933,7 @@ func (cl client) CreateFile(org, repo, path, branch, commitMSG, sha string, cont
 }
 
 func (cl client) GetUserPermissionOfRepo(org, repo, user string) (*atomgit.RepositoryPermissionLevel, error) {
-	permission, _, err := cl.c.Repositories.GetPermissionLevel(context.Background(), org, repo, user)
+	permission, _, err := cl.c.Repositories.GetPermissionLevel(cl.ctx, org, repo, user)
 	if err != nil {
 		return nil, err
 	}
@@ -889,7 +942,7 @@ func (cl client) GetUserPermissionOfRepo(org, repo, user string) (*atomgit.Repos
 }
 
 func (cl client) CreateIssue(org, repo string, request *atomgit.IssueRequest) (*atomgit.Issue, error) {
-	is, _, err := cl.c.Issues.Create(context.Background(), org, repo, request)
+	is, _, err := cl.c.Issues.Create(cl.ctx, org, repo, request)
 	if err != nil {
 		return nil, err
 	}
@@ -898,7 +951,7 @@ func (cl client) CreateIssue(org, repo string, request *atomgit.IssueRequest) (*
 }
 
 func (cl client) GetRef(org, repo, ref string) (*atomgit.Reference, error) {
-	r, _, err := cl.c.Git.GetRef(context.Background(), org, repo, ref)
+	r, _, err := cl.c.Git.GetRef(cl.ctx, org, repo, ref)
 	if err != nil {
 		return nil, err
 	}
@@ -907,7 +960,7 @@ func (cl client) GetRef(org, repo, ref string) (*atomgit.Reference, error) {
 }
 
 func (cl client) CreateBranch(org, repo string, reference *atomgit.Reference) error {
-	_, _, err := cl.c.Git.CreateRef(context.Background(), org, repo, reference)
+	_, _, err := cl.c.Git.CreateRef(cl.ctx, org, repo, reference)
 	if err != nil {
 		return err
 	}
@@ -922,7 +975,7 @@ func (cl client) ListOperationLogs(pr *PRIssue) ([]*atomgit.Timeline, error) {
 		opt.Page = 1
 
 		for {
-			v, resp, err := cl.c.Issues.ListIssueTimeline(context.Background(), pr.Org, pr.Repo, pr.Number, opt)
+			v, resp, err := cl.c.Issues.ListIssueTimeline(cl.ctx, pr.Org, pr.Repo, pr.Number, opt)
 			if err != nil {
 				return err
 			}
@@ -970,7 +1023,7 @@ func (cl client) GetEnterprisesMember(org string) ([]*atomgit.User, error) {
 		opt.Page = 1
 
 		for {
-			v, resp, err := cl.c.Organizations.ListMembers(context.Background(), org,
+			v, resp, err := cl.c.Organizations.ListMembers(cl.ctx, org,
 				&atomgit.ListMembersOptions{ListOptions: *opt})
 			if err != nil {
 				return err
@@ -1013,7 +1066,7 @@ func (cl client) GetEnterprisesMember(org string) ([]*atomgit.User, error) {
 }
 
 func (cl client) GetSinglePR(pr *PRIssue) (*atomgit.PullRequest, error) {
-	p, _, err := cl.c.PullRequests.Get(context.Background(), pr.Org, pr.Repo, pr.Number)
+	p, _, err := cl.c.PullRequests.Get(cl.ctx, pr.Org, pr.Repo, pr.Number)
 	if err != nil {
 		return nil, err
 	}
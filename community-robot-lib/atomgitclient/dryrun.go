@@ -0,0 +1,323 @@
+package atomgitclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/opensourceways/go-atomgit/atomgit"
+	"github.com/sirupsen/logrus"
+)
+
+// maxDryRunCalls is the number of the latest calls kept by DryRunClient.
+const maxDryRunCalls = 1000
+
+// DryRunCall is a call which would have changed AtomGit.
+type DryRunCall struct {
+	Time   time.Time `json:"time"`
+	Method string    `json:"method"`
+	Target string    `json:"target"`
+	Args   string    `json:"args,omitempty"`
+}
+
+// DryRunClient records the calls which change AtomGit instead of executing them.
+// The calls reading AtomGit are still sent to the real api.
+// It serves the recorded calls as a html page, or json if requested by "?format=json".
+type DryRunClient struct {
+	Client
+
+	// calls is shared by the clients returned by WithContext.
+	calls *dryRunCalls
+}
+
+type dryRunCalls struct {
+	mut   sync.RWMutex
+	calls []DryRunCall
+}
+
+// NewDryRunClient wraps c in dry-run mode.
+func NewDryRunClient(c Client) *DryRunClient {
+	return &DryRunClient{Client: c, calls: new(dryRunCalls)}
+}
+
+// WithContext returns the client in dry-run mode which records to the same calls.
+func (cl *DryRunClient) WithContext(ctx context.Context) Client {
+	return &DryRunClient{Client: cl.Client.WithContext(ctx), calls: cl.calls}
+}
+
+// Calls returns the calls recorded from the oldest to the latest.
+func (cl *DryRunClient) Calls() []DryRunCall {
+	cl.calls.mut.RLock()
+	defer cl.calls.mut.RUnlock()
+
+	r := make([]DryRunCall, len(cl.calls.calls))
+	copy(r, cl.calls.calls)
+
+	return r
+}
+
+func (cl *DryRunClient) record(method, target string, args ...interface{}) {
+	c := DryRunCall{
+		Time:   time.Now(),
+		Method: method,
+		Target: target,
+	}
+
+	if len(args) > 0 {
+		if v, err := json.Marshal(args); err == nil {
+			c.Args = string(v)
+		} else {
+			c.Args = fmt.Sprint(args...)
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"method": c.Method,
+		"target": c.Target,
+		"args":   c.Args,
+	}).Info("dry-run")
+
+	calls := cl.calls
+
+	calls.mut.Lock()
+	defer calls.mut.Unlock()
+
+	if len(calls.calls) >= maxDryRunCalls {
+		calls.calls = calls.calls[1:]
+	}
+	calls.calls = append(calls.calls, c)
+}
+
+var dryRunPage = template.Must(template.New("dry-run").Parse(`<!DOCTYPE html>
+<html>
+<head><title>dry-run</title></head>
+<body>
+<h3>{{len .}} calls would have changed AtomGit</h3>
+<table border="1" cellpadding="4">
+<tr><th>Time</th><th>Method</th><th>Target</th><th>Args</th></tr>
+{{range .}}<tr><td>{{.Time.Format "2006-01-02 15:04:05"}}</td><td>{{.Method}}</td><td>{{.Target}}</td><td><pre>{{.Args}}</pre></td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+func (cl *DryRunClient) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	calls := cl.Calls()
+
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(calls)
+
+		return
+	}
+
+	// show the latest call first
+	for i, j := 0, len(calls)-1; i < j; i, j = i+1, j-1 {
+		calls[i], calls[j] = calls[j], calls[i]
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := dryRunPage.Execute(w, calls); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func repoTarget(org, repo string) string {
+	return org + "/" + repo
+}
+
+func (cl *DryRunClient) AddPRLabel(pr *PRIssue, label string) error {
+	cl.record("AddPRLabel", pr.String(), label)
+
+	return nil
+}
+
+func (cl *DryRunClient) RemovePRLabel(pr *PRIssue, label string) error {
+	cl.record("RemovePRLabel", pr.String(), label)
+
+	return nil
+}
+
+func (cl *DryRunClient) CreatePRComment(pr *PRIssue, comment string) error {
+	cl.record("CreatePRComment", pr.String(), comment)
+
+	return nil
+}
+
+func (cl *DryRunClient) CreatePRCommentReply(pr *PRIssue, comment, commentID string) error {
+	cl.record("CreatePRCommentReply", pr.String(), comment, commentID)
+
+	return nil
+}
+
+func (cl *DryRunClient) DeletePRComment(org, repo, commentId string) error {
+	cl.record("DeletePRComment", repoTarget(org, repo), commentId)
+
+	return nil
+}
+
+// UpdatePR returns the pull request unchanged.
+func (cl *DryRunClient) UpdatePR(pr *PRIssue, request *atomgit.PullRequest) (*atomgit.PullRequest, error) {
+	cl.record("UpdatePR", pr.String(), request)
+
+	return cl.Client.GetSinglePR(pr)
+}
+
+func (cl *DryRunClient) RemoveRepoMember(pr *PRIssue, login string) error {
+	cl.record("RemoveRepoMember", repoTarget(pr.Org, pr.Repo), login)
+
+	return nil
+}
+
+func (cl *DryRunClient) AddRepoMember(pr *PRIssue, login, permission string) error {
+	cl.record("AddRepoMember", repoTarget(pr.Org, pr.Repo), login, permission)
+
+	return nil
+}
+
+func (cl *DryRunClient) UpdatePRComment(pr *PRIssue, commentID int64, ic *atomgit.IssueComment) error {
+	cl.record("UpdatePRComment", pr.String(), commentID, ic)
+
+	return nil
+}
+
+func (cl *DryRunClient) ClosePR(pr *PRIssue) error {
+	cl.record("ClosePR", pr.String())
+
+	return nil
+}
+
+func (cl *DryRunClient) ReopenPR(pr *PRIssue) error {
+	cl.record("ReopenPR", pr.String())
+
+	return nil
+}
+
+func (cl *DryRunClient) AssignPR(pr *PRIssue, logins []string) error {
+	cl.record("AssignPR", pr.String(), logins)
+
+	return nil
+}
+
+func (cl *DryRunClient) UnAssignPR(pr *PRIssue, logins []string) error {
+	cl.record("UnAssignPR", pr.String(), logins)
+
+	return nil
+}
+
+func (cl *DryRunClient) CloseIssue(pr *PRIssue) error {
+	cl.record("CloseIssue", pr.String())
+
+	return nil
+}
+
+func (cl *DryRunClient) ReopenIssue(pr *PRIssue) error {
+	cl.record("ReopenIssue", pr.String())
+
+	return nil
+}
+
+func (cl *DryRunClient) MergePR(pr *PRIssue, commitMessage string, opt *atomgit.PullRequestOptions) error {
+	cl.record("MergePR", pr.String(), commitMessage, opt)
+
+	return nil
+}
+
+func (cl *DryRunClient) CreateRepo(org string, r *atomgit.Repository) error {
+	cl.record("CreateRepo", org, r)
+
+	return nil
+}
+
+func (cl *DryRunClient) UpdateRepo(org, repo string, r *atomgit.Repository) error {
+	cl.record("UpdateRepo", repoTarget(org, repo), r)
+
+	return nil
+}
+
+func (cl *DryRunClient) CreateRepoLabel(org, repo, label string) error {
+	cl.record("CreateRepoLabel", repoTarget(org, repo), label)
+
+	return nil
+}
+
+func (cl *DryRunClient) AssignSingleIssue(is *PRIssue, login string) error {
+	cl.record("AssignSingleIssue", is.String(), login)
+
+	return nil
+}
+
+func (cl *DryRunClient) UnAssignSingleIssue(is *PRIssue, login string) error {
+	cl.record("UnAssignSingleIssue", is.String(), login)
+
+	return nil
+}
+
+func (cl *DryRunClient) CreateIssueComment(is *PRIssue, comment string) error {
+	cl.record("CreateIssueComment", is.String(), comment)
+
+	return nil
+}
+
+func (cl *DryRunClient) UpdateIssueComment(is *PRIssue, commentID int64, c *atomgit.IssueComment) error {
+	cl.record("UpdateIssueComment", is.String(), commentID, c)
+
+	return nil
+}
+
+func (cl *DryRunClient) RemoveIssueLabel(is *PRIssue, label string) error {
+	cl.record("RemoveIssueLabel", is.String(), label)
+
+	return nil
+}
+
+func (cl *DryRunClient) AddIssueLabel(is *PRIssue, label []string) error {
+	cl.record("AddIssueLabel", is.String(), label)
+
+	return nil
+}
+
+func (cl *DryRunClient) UpdateIssue(is *PRIssue, iss *atomgit.IssueRequest) error {
+	cl.record("UpdateIssue", is.String(), iss)
+
+	return nil
+}
+
+func (cl *DryRunClient) SetProtectionBranch(org, repo, branch string, pre *atomgit.ProtectionRequest) error {
+	cl.record("SetProtectionBranch", repoTarget(org, repo), branch, pre)
+
+	return nil
+}
+
+func (cl *DryRunClient) RemoveProtectionBranch(org, repo, branch string) error {
+	cl.record("RemoveProtectionBranch", repoTarget(org, repo), branch)
+
+	return nil
+}
+
+func (cl *DryRunClient) CreateFile(org, repo, path, branch, commitMSG, sha string, content []byte) error {
+	cl.record("CreateFile", repoTarget(org, repo), path, branch, commitMSG, sha, string(content))
+
+	return nil
+}
+
+// CreateIssue returns the issue built from request which has no number.
+func (cl *DryRunClient) CreateIssue(org, repo string, request *atomgit.IssueRequest) (*atomgit.Issue, error) {
+	cl.record("CreateIssue", repoTarget(org, repo), request)
+
+	return &atomgit.Issue{
+		Title: request.Title,
+		Body:  request.Body,
+		State: atomgit.String("open"),
+	}, nil
+}
+
+func (cl *DryRunClient) CreateBranch(org, repo string, reference *atomgit.Reference) error {
+	cl.record("CreateBranch", repoTarget(org, repo), reference)
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package atomgitclient_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opensourceways/community-robot-lib/atomgitclient"
+	"github.com/opensourceways/community-robot-lib/fakeatomgit"
+)
+
+func TestDryRunClient(t *testing.T) {
+	s := fakeatomgit.NewServer("ci-robot")
+	n := s.AddPullRequest("openeuler", "community", fakeatomgit.PullRequest{Labels: []string{"kind/bug"}})
+
+	api := httptest.NewServer(s)
+	defer api.Close()
+
+	c, err := atomgitclient.NewClientWithEndpoint(func() []byte { return []byte("token") }, api.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cli := atomgitclient.NewDryRunClient(c)
+	pr := atomgitclient.BuildPRIssue("openeuler", "community", n)
+
+	if err := cli.AddPRLabel(pr, "lgtm"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cli.CreatePRComment(pr, "hello"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the read is sent to the real api.
+	labels, err := cli.GetPRLabels(pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(labels) != 1 || labels[0] != "kind/bug" {
+		t.Errorf("labels = %v, the dry-run call should not change them", labels)
+	}
+
+	if v := s.Calls(); len(v) != 0 {
+		t.Errorf("AtomGit is changed: %v", v)
+	}
+
+	w := httptest.NewRecorder()
+	cli.ServeHTTP(w, httptest.NewRequest("GET", "/dry-run?format=json", nil))
+
+	var calls []atomgitclient.DryRunCall
+	if err := json.Unmarshal(w.Body.Bytes(), &calls); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(calls) != 2 || calls[0].Method != "AddPRLabel" || calls[1].Method != "CreatePRComment" {
+		t.Errorf("unexpected calls: %+v", calls)
+	}
+}
@@ -1,6 +1,8 @@
 package atomgitclient
 
 import (
+	"context"
+
 	"github.com/opensourceways/go-atomgit/atomgit"
 )
 
@@ -44,6 +46,7 @@ type Client interface {
 	GetIssueLabels(is *PRIssue) ([]string, error)
 	UpdateIssue(is *PRIssue, iss *atomgit.IssueRequest) error
 	GetSingleIssue(is *PRIssue) (*atomgit.Issue, error)
+	SearchIssues(query string) (*atomgit.IssuesSearchResult, error)
 	ListBranches(org, repo string) ([]*atomgit.Branch, error)
 	SetProtectionBranch(org, repo, branch string, pre *atomgit.ProtectionRequest) error
 	RemoveProtectionBranch(org, repo, branch string) error
@@ -58,4 +61,8 @@ type Client interface {
 	GetEnterprisesMember(org string) ([]*atomgit.User, error)
 	GetSinglePR(pr *PRIssue) (*atomgit.PullRequest, error)
 	CreatePRCommentReply(pr *PRIssue, comment, commentID string) error
+
+	// WithContext returns the client whose requests are traced as the children of the
+	// span in ctx. The handlers generally call it with the Context of log entry.
+	WithContext(ctx context.Context) Client
 }
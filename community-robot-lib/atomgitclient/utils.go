@@ -5,6 +5,7 @@ import "regexp"
 const (
 	ActionOpened  = "opened"
 	ActionCreated = "created"
+	ActionUpdated = "updated"
 	ActionReopen  = "reopened"
 	ActionClosed  = "closed"
 
@@ -28,13 +28,27 @@ type ConfigAgent struct {
 	b      NewConfig
 	md5Sum string
 	t      utils.Timer
+
+	// err is the error of the last attempt to load the config.
+	err error
 }
 
 func NewConfigAgent(b NewConfig) ConfigAgent {
 	return ConfigAgent{b: b, t: utils.NewTimer()}
 }
 
+// load loads the config and records the error of it.
 func (ca *ConfigAgent) load(path string) error {
+	err := ca.loadFile(path)
+
+	ca.mut.Lock()
+	ca.err = err
+	ca.mut.Unlock()
+
+	return err
+}
+
+func (ca *ConfigAgent) loadFile(path string) error {
 	b, err := os.ReadFile(path)
 	if err != nil {
 		return err
@@ -74,6 +88,15 @@ func (ca *ConfigAgent) GetConfig() (string, Config) {
 	return v, c
 }
 
+// LoadError returns the error of the last attempt to load the config, and nil if it succeeded.
+// The config returned by GetConfig is the last valid one even if it is not nil.
+func (ca *ConfigAgent) LoadError() error {
+	ca.mut.RLock()
+	defer ca.mut.RUnlock()
+
+	return ca.err
+}
+
 // Start starts polling path for plugin config.
 // If the first attempt fails, then start returns the error.
 func (ca *ConfigAgent) Start(path string) error {
@@ -0,0 +1,70 @@
+package fakeatomgit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Call is an api call which changes the AtomGit.
+type Call struct {
+	Method string
+	Path   string
+	Body   string
+}
+
+func (c Call) String() string {
+	if c.Body == "" {
+		return fmt.Sprintf("%s %s", c.Method, c.Path)
+	}
+
+	return fmt.Sprintf("%s %s %s", c.Method, c.Path, c.Body)
+}
+
+// Calls returns the api calls which change the AtomGit in the order they were received.
+func (s *Server) Calls() []Call {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	r := make([]Call, len(s.calls))
+	copy(r, s.calls)
+
+	return r
+}
+
+// ResetCalls clears the api calls recorded.
+func (s *Server) ResetCalls() {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.calls = nil
+}
+
+// recordCall must be called with the lock held.
+func (s *Server) recordCall(r *http.Request) {
+	if r.Method == http.MethodGet {
+		return
+	}
+
+	c := Call{Method: r.Method, Path: r.URL.Path}
+	if r.URL.RawQuery != "" {
+		c.Path += "?" + r.URL.RawQuery
+	}
+
+	if r.Body != nil {
+		b, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(b))
+
+		// compact the body to keep a call in one line.
+		buf := new(bytes.Buffer)
+		if err := json.Compact(buf, b); err == nil {
+			c.Body = buf.String()
+		} else {
+			c.Body = string(b)
+		}
+	}
+
+	s.calls = append(s.calls, c)
+}
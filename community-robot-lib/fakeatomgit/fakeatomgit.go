@@ -0,0 +1,565 @@
+// Package fakeatomgit implements an in-memory AtomGit which serves the REST api
+// used by atomgitclient, so that a robot can be tested end-to-end without AtomGit.
+package fakeatomgit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/opensourceways/go-atomgit/atomgit"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	stateOpen   = "open"
+	stateClosed = "closed"
+
+	// defaultLabelEvent is the event of timeline which records adding a label.
+	defaultLabelEvent = "labeled"
+)
+
+// PullRequest is the seed of a pull request.
+type PullRequest struct {
+	Title  string
+	Body   string
+	Author string
+
+	// Base and Head are the branch names of target and source.
+	Base string
+	Head string
+
+	Labels    []string
+	Assignees []string
+	Reviewers []string
+
+	// Mergeable means there is no conflict to the target branch.
+	Mergeable bool
+
+	Commits []*sdk.RepositoryCommit
+
+	// Files is the changed files of pull request.
+	Files []string
+
+	// Merged means the pull request has been merged by the author, such as an earlier contribution.
+	Merged bool
+}
+
+// Issue is the seed of an issue.
+type Issue struct {
+	Title  string
+	Body   string
+	Author string
+	Labels []string
+}
+
+// Comment is a comment on a pull request or an issue.
+type Comment struct {
+	ID        int64
+	Author    string
+	Body      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Merge records how a pull request was merged.
+type Merge struct {
+	Method        string
+	CommitTitle   string
+	CommitMessage string
+	MergedBy      string
+}
+
+type repository struct {
+	org  string
+	name string
+
+	labels sets.String
+
+	// mergeMethods are the methods allowed to merge pull request, all are allowed if it is nil.
+	mergeMethods sets.String
+
+	// collaborators maps login to the permission of admin, write or read.
+	collaborators map[string]string
+
+	// files maps branch to the files of it.
+	files map[string]map[string][]byte
+
+	items      map[int]*item
+	nextNumber int
+}
+
+func (r *repository) fullName() string {
+	return r.org + "/" + r.name
+}
+
+// item is a pull request or an issue which share the same number space.
+type item struct {
+	number    int
+	isPR      bool
+	title     string
+	body      string
+	author    string
+	state     string
+	labels    sets.String
+	assignees sets.String
+	comments  []*Comment
+	timeline  []*sdk.Timeline
+	createdAt time.Time
+
+	// the fields below belong to pull request only
+	base      string
+	head      string
+	reviewers []string
+	mergeable bool
+	commits   []*sdk.RepositoryCommit
+	files     []string
+	merge     *Merge
+}
+
+// Server is the fake AtomGit. It is safe for concurrent use.
+type Server struct {
+	// Login is the user owning the token of the client. It is recorded as the
+	// author of comments, labels and merges done through the api.
+	Login string
+
+	// LabelEvent is the event of timeline recorded when a label is added.
+	LabelEvent string
+
+	mut    sync.Mutex
+	repos  map[string]*repository
+	nextID int64
+	calls  []Call
+}
+
+// NewServer creates an empty fake AtomGit which acts on behalf of login.
+func NewServer(login string) *Server {
+	return &Server{
+		Login:      login,
+		LabelEvent: defaultLabelEvent,
+		repos:      map[string]*repository{},
+	}
+}
+
+func (s *Server) genID() int64 {
+	s.nextID++
+
+	return s.nextID
+}
+
+func (s *Server) getRepo(org, repo string) *repository {
+	return s.repos[org+"/"+repo]
+}
+
+func (s *Server) getItem(org, repo string, number int) *item {
+	if r := s.getRepo(org, repo); r != nil {
+		return r.items[number]
+	}
+
+	return nil
+}
+
+// AddRepo creates the repository if it does not exist.
+func (s *Server) AddRepo(org, repo string) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.addRepo(org, repo)
+}
+
+func (s *Server) addRepo(org, repo string) *repository {
+	if r := s.getRepo(org, repo); r != nil {
+		return r
+	}
+
+	r := &repository{
+		org:           org,
+		name:          repo,
+		labels:        sets.NewString(),
+		collaborators: map[string]string{},
+		files:         map[string]map[string][]byte{},
+		items:         map[int]*item{},
+		nextNumber:    1,
+	}
+	s.repos[r.fullName()] = r
+
+	return r
+}
+
+// AddCollaborator sets the permission of login on the repository.
+func (s *Server) AddCollaborator(org, repo, login, permission string) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.addRepo(org, repo).collaborators[login] = permission
+}
+
+// SetMergeMethods sets the methods allowed to merge pull request, such as merge, squash and rebase.
+func (s *Server) SetMergeMethods(org, repo string, methods ...string) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.addRepo(org, repo).mergeMethods = sets.NewString(methods...)
+}
+
+// AddRepoLabels creates the labels of repository.
+func (s *Server) AddRepoLabels(org, repo string, labels ...string) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.addRepo(org, repo).labels.Insert(labels...)
+}
+
+// SetFile sets the content of file at path on the branch.
+func (s *Server) SetFile(org, repo, branch, path string, content []byte) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.addRepo(org, repo).setFile(branch, path, content)
+}
+
+func (r *repository) setFile(branch, path string, content []byte) {
+	files, ok := r.files[branch]
+	if !ok {
+		files = map[string][]byte{}
+		r.files[branch] = files
+	}
+
+	files[strings.TrimPrefix(path, "/")] = content
+}
+
+// AddPullRequest creates a pull request and returns its number.
+func (s *Server) AddPullRequest(org, repo string, pr PullRequest) int {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	r := s.addRepo(org, repo)
+	it := r.newItem(pr.Title, pr.Body, pr.Author, pr.Labels)
+	it.isPR = true
+	it.assignees.Insert(pr.Assignees...)
+	it.base = pr.Base
+	it.head = pr.Head
+	it.reviewers = pr.Reviewers
+	it.mergeable = pr.Mergeable
+	it.commits = pr.Commits
+	it.files = pr.Files
+
+	if pr.Merged {
+		it.merge = &Merge{Method: "merge", MergedBy: pr.Author}
+		it.state = stateClosed
+	}
+
+	return it.number
+}
+
+// AddIssue creates an issue and returns its number.
+func (s *Server) AddIssue(org, repo string, is Issue) int {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	return s.addRepo(org, repo).newItem(is.Title, is.Body, is.Author, is.Labels).number
+}
+
+func (r *repository) newItem(title, body, author string, labels []string) *item {
+	return r.addItem(r.nextNumber, title, body, author, labels)
+}
+
+func (r *repository) addItem(number int, title, body, author string, labels []string) *item {
+	it := &item{
+		number:    number,
+		title:     title,
+		body:      body,
+		author:    author,
+		state:     stateOpen,
+		labels:    sets.NewString(labels...),
+		assignees: sets.NewString(),
+		createdAt: time.Now(),
+	}
+	r.items[it.number] = it
+
+	if number >= r.nextNumber {
+		r.nextNumber = number + 1
+	}
+
+	r.labels.Insert(labels...)
+
+	return it
+}
+
+// AddComment adds a comment to the pull request or issue like a user does on the web page.
+func (s *Server) AddComment(org, repo string, number int, author, body string) (int64, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	it := s.getItem(org, repo, number)
+	if it == nil {
+		return 0, fmt.Errorf("%s/%s:%d does not exist", org, repo, number)
+	}
+
+	return s.addComment(it, author, body).ID, nil
+}
+
+func (s *Server) addComment(it *item, author, body string) *Comment {
+	now := time.Now()
+	c := &Comment{
+		ID:        s.genID(),
+		Author:    author,
+		Body:      body,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	it.comments = append(it.comments, c)
+
+	return c
+}
+
+func (s *Server) addLabels(r *repository, it *item, labels []string) {
+	for _, l := range labels {
+		if it.labels.Has(l) {
+			continue
+		}
+
+		it.labels.Insert(l)
+		r.labels.Insert(l)
+
+		it.timeline = append(it.timeline, &sdk.Timeline{
+			ID:        sdk.Int64(s.genID()),
+			Event:     sdk.String(s.LabelEvent),
+			Body:      sdk.String(l),
+			Label:     &sdk.Label{Name: sdk.String(l)},
+			User:      user(s.Login),
+			Actor:     user(s.Login),
+			CreatedAt: &sdk.Timestamp{Time: time.Now()},
+		})
+	}
+}
+
+// GetLabels returns the sorted labels of pull request or issue.
+func (s *Server) GetLabels(org, repo string, number int) []string {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if it := s.getItem(org, repo, number); it != nil {
+		return it.labels.List()
+	}
+
+	return nil
+}
+
+// GetAssignees returns the sorted assignees of pull request or issue.
+func (s *Server) GetAssignees(org, repo string, number int) []string {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if it := s.getItem(org, repo, number); it != nil {
+		return it.assignees.List()
+	}
+
+	return nil
+}
+
+// GetComments returns the comments of pull request or issue in order of creation.
+func (s *Server) GetComments(org, repo string, number int) []Comment {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	it := s.getItem(org, repo, number)
+	if it == nil {
+		return nil
+	}
+
+	r := make([]Comment, len(it.comments))
+	for i, c := range it.comments {
+		r[i] = *c
+	}
+
+	return r
+}
+
+// GetState returns the state of pull request or issue.
+func (s *Server) GetState(org, repo string, number int) string {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if it := s.getItem(org, repo, number); it != nil {
+		return it.state
+	}
+
+	return ""
+}
+
+// GetMerge returns how the pull request was merged, or false if it is not merged.
+func (s *Server) GetMerge(org, repo string, number int) (Merge, bool) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if it := s.getItem(org, repo, number); it != nil && it.merge != nil {
+		return *it.merge, true
+	}
+
+	return Merge{}, false
+}
+
+// GetRepoLabels returns the sorted labels of repository.
+func (s *Server) GetRepoLabels(org, repo string) []string {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if r := s.getRepo(org, repo); r != nil {
+		return r.labels.List()
+	}
+
+	return nil
+}
+
+func user(login string) *sdk.User {
+	return &sdk.User{Login: sdk.String(login)}
+}
+
+func users(logins []string) []*sdk.User {
+	if len(logins) == 0 {
+		return nil
+	}
+
+	r := make([]*sdk.User, len(logins))
+	for i, v := range logins {
+		r[i] = user(v)
+	}
+
+	return r
+}
+
+func toLabels(labels sets.String) []*sdk.Label {
+	v := labels.List()
+	r := make([]*sdk.Label, len(v))
+	for i := range v {
+		r[i] = &sdk.Label{Name: sdk.String(v[i])}
+	}
+
+	return r
+}
+
+func (r *repository) toRepository() *sdk.Repository {
+	v := &sdk.Repository{
+		Name:     sdk.String(r.name),
+		FullName: sdk.String(r.fullName()),
+		Owner:    user(r.org),
+	}
+
+	if r.mergeMethods != nil {
+		v.AllowMergeCommit = sdk.Bool(r.mergeMethods.Has("merge"))
+		v.AllowSquashMerge = sdk.Bool(r.mergeMethods.Has("squash"))
+		v.AllowRebaseMerge = sdk.Bool(r.mergeMethods.Has("rebase"))
+	}
+
+	return v
+}
+
+func (r *repository) allowMergeMethod(method string) bool {
+	if method == "" {
+		method = "merge"
+	}
+
+	return r.mergeMethods == nil || r.mergeMethods.Has(method)
+}
+
+func (r *repository) htmlURL(it *item) string {
+	kind := "issues"
+	if it.isPR {
+		kind = "pulls"
+	}
+
+	return fmt.Sprintf("https://atomgit.com/%s/%s/%d", r.fullName(), kind, it.number)
+}
+
+func (r *repository) toPullRequest(it *item) *sdk.PullRequest {
+	pr := &sdk.PullRequest{
+		Number:             sdk.Int(it.number),
+		State:              sdk.String(it.state),
+		Title:              sdk.String(it.title),
+		Body:               sdk.String(it.body),
+		User:               user(it.author),
+		Labels:             toLabels(it.labels),
+		Assignees:          users(it.assignees.List()),
+		RequestedReviewers: users(it.reviewers),
+		Mergeable:          sdk.Bool(it.mergeable && it.merge == nil),
+		Merged:             sdk.Bool(it.merge != nil),
+		MergeCommitSHA:     sdk.String(fmt.Sprintf("%s-%d", it.head, it.number)),
+		HTMLURL:            sdk.String(r.htmlURL(it)),
+		CreatedAt:          &sdk.Timestamp{Time: it.createdAt},
+		Base: &sdk.PullRequestBranch{
+			Ref:  sdk.String(it.base),
+			Repo: r.toRepository(),
+		},
+		Head: &sdk.PullRequestBranch{
+			Ref:  sdk.String(it.head),
+			Repo: r.toRepository(),
+		},
+	}
+
+	if n := it.assignees.Len(); n > 0 {
+		pr.Assignee = pr.Assignees[0]
+	}
+
+	return pr
+}
+
+func (r *repository) toIssue(it *item) *sdk.Issue {
+	is := &sdk.Issue{
+		Number:     sdk.Int(it.number),
+		State:      sdk.String(it.state),
+		Title:      sdk.String(it.title),
+		Body:       sdk.String(it.body),
+		User:       user(it.author),
+		Labels:     toLabels(it.labels),
+		Assignees:  users(it.assignees.List()),
+		HTMLURL:    sdk.String(r.htmlURL(it)),
+		CreatedAt:  &sdk.Timestamp{Time: it.createdAt},
+		Repository: r.toRepository(),
+	}
+
+	if n := it.assignees.Len(); n > 0 {
+		is.Assignee = is.Assignees[0]
+	}
+
+	if it.isPR {
+		is.PullRequestLinks = &sdk.PullRequestLinks{HTMLURL: sdk.String(r.htmlURL(it))}
+	}
+
+	return is
+}
+
+func (c *Comment) toPRComment() *sdk.PullRequestComment {
+	return &sdk.PullRequestComment{
+		ID:        sdk.String(fmt.Sprintf("%d", c.ID)),
+		Body:      sdk.String(c.Body),
+		User:      user(c.Author),
+		CreatedAt: &sdk.Timestamp{Time: c.CreatedAt},
+		UpdatedAt: &sdk.Timestamp{Time: c.UpdatedAt},
+	}
+}
+
+func (c *Comment) toIssueComment() *sdk.IssueComment {
+	return &sdk.IssueComment{
+		ID:        sdk.Int64(c.ID),
+		Body:      sdk.String(c.Body),
+		User:      user(c.Author),
+		CreatedAt: &sdk.Timestamp{Time: c.CreatedAt},
+		UpdatedAt: &sdk.Timestamp{Time: c.UpdatedAt},
+	}
+}
+
+// sortedFiles returns the paths of files on branch in order.
+func (r *repository) sortedFiles(branch string) []string {
+	files := r.files[branch]
+
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	return paths
+}
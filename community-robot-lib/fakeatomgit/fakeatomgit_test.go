@@ -0,0 +1,166 @@
+package fakeatomgit
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	sdk "github.com/opensourceways/go-atomgit/atomgit"
+
+	"github.com/opensourceways/community-robot-lib/atomgitclient"
+)
+
+const (
+	testOrg  = "openeuler"
+	testRepo = "community"
+	testBot  = "ci-robot"
+)
+
+func newTestClient(t *testing.T, s *Server) atomgitclient.Client {
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+
+	cli, err := atomgitclient.NewClientWithEndpoint(func() []byte { return []byte("token") }, ts.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	return cli
+}
+
+func TestLabelsAndComments(t *testing.T) {
+	s := NewServer(testBot)
+	n := s.AddPullRequest(testOrg, testRepo, PullRequest{Title: "fix", Author: "alice", Labels: []string{"kind/bug"}})
+	cli := newTestClient(t, s)
+	pr := atomgitclient.BuildPRIssue(testOrg, testRepo, n)
+
+	if err := cli.AddPRLabel(pr, "lgtm"); err != nil {
+		t.Fatalf("add label: %v", err)
+	}
+
+	if err := cli.RemovePRLabel(pr, "kind/bug"); err != nil {
+		t.Fatalf("remove label: %v", err)
+	}
+
+	// removing a missing label is not an error for the client.
+	if err := cli.RemovePRLabel(pr, "kind/bug"); err != nil {
+		t.Errorf("remove missing label: %v", err)
+	}
+
+	labels, err := cli.GetPRLabels(pr)
+	if err != nil {
+		t.Fatalf("get labels: %v", err)
+	}
+
+	if want := []string{"lgtm"}; !reflect.DeepEqual(labels, want) {
+		t.Errorf("labels = %v, want %v", labels, want)
+	}
+
+	logs, err := cli.ListOperationLogs(pr)
+	if err != nil {
+		t.Fatalf("list timeline: %v", err)
+	}
+
+	if len(logs) != 1 || logs[0].GetBody() != "lgtm" || logs[0].GetUser().GetLogin() != testBot {
+		t.Errorf("unexpected timeline: %v", logs)
+	}
+
+	if err := cli.CreatePRComment(pr, "hello"); err != nil {
+		t.Fatalf("create comment: %v", err)
+	}
+
+	comments, err := cli.GetPRComments(pr)
+	if err != nil {
+		t.Fatalf("get comments: %v", err)
+	}
+
+	if len(comments) != 1 || comments[0].GetBody() != "hello" {
+		t.Fatalf("unexpected comments: %v", comments)
+	}
+
+	if err := cli.DeletePRComment(testOrg, testRepo, comments[0].GetID()); err != nil {
+		t.Fatalf("delete comment: %v", err)
+	}
+
+	if v := s.GetComments(testOrg, testRepo, n); len(v) != 0 {
+		t.Errorf("comments are not deleted: %v", v)
+	}
+}
+
+func TestMergePR(t *testing.T) {
+	s := NewServer(testBot)
+	conflicted := s.AddPullRequest(testOrg, testRepo, PullRequest{Title: "a"})
+	n := s.AddPullRequest(testOrg, testRepo, PullRequest{Title: "b", Mergeable: true})
+	cli := newTestClient(t, s)
+
+	opt := &sdk.PullRequestOptions{MergeMethod: "squash"}
+	if err := cli.MergePR(atomgitclient.BuildPRIssue(testOrg, testRepo, conflicted), "msg", opt); err == nil {
+		t.Error("expected an error when merging a conflicted pull request")
+	}
+
+	pr := atomgitclient.BuildPRIssue(testOrg, testRepo, n)
+	if err := cli.MergePR(pr, "msg", opt); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	m, ok := s.GetMerge(testOrg, testRepo, n)
+	if !ok || m.Method != "squash" || m.CommitMessage != "msg" {
+		t.Errorf("unexpected merge: %+v, %v", m, ok)
+	}
+
+	if err := cli.MergePR(pr, "msg", opt); err == nil {
+		t.Error("expected an error when merging twice")
+	}
+
+	if v := s.GetState(testOrg, testRepo, n); v != stateClosed {
+		t.Errorf("state = %s, want %s", v, stateClosed)
+	}
+}
+
+func TestCollaboratorsAndContents(t *testing.T) {
+	s := NewServer(testBot)
+	s.AddCollaborator(testOrg, testRepo, "bob", permissionAdmin)
+	s.SetFile(testOrg, testRepo, "master", "sig/infra/sig-info.yaml", []byte("name: infra"))
+	cli := newTestClient(t, s)
+	pr := atomgitclient.BuildPRIssue(testOrg, testRepo, 0)
+
+	if b, err := cli.IsCollaborator(pr, "bob"); err != nil || !b {
+		t.Errorf("bob should be a collaborator: %v, %v", b, err)
+	}
+
+	if b, err := cli.IsCollaborator(pr, "alice"); err != nil || b {
+		t.Errorf("alice should not be a collaborator: %v, %v", b, err)
+	}
+
+	users, err := cli.ListCollaborator(pr)
+	if err != nil {
+		t.Fatalf("list collaborators: %v", err)
+	}
+
+	if len(users) != 1 || !users[0].GetPermissions()["Admin"] {
+		t.Errorf("unexpected collaborators: %v", users)
+	}
+
+	c, err := cli.GetPathContent(testOrg, testRepo, "sig/infra/sig-info.yaml", "master")
+	if err != nil {
+		t.Fatalf("get content: %v", err)
+	}
+
+	if v, err := c.GetContent(); err != nil || v != "name: infra" {
+		t.Errorf("content = %q", v)
+	}
+
+	entries, err := cli.GetDirectoryTree(testOrg, testRepo, "master", true)
+	if err != nil {
+		t.Fatalf("get tree: %v", err)
+	}
+
+	paths := make([]string, len(entries))
+	for i, e := range entries {
+		paths[i] = e.GetPath()
+	}
+
+	if want := []string{"sig", "sig/infra", "sig/infra/sig-info.yaml"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("tree = %v, want %v", paths, want)
+	}
+}
@@ -0,0 +1,153 @@
+package fakeatomgit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	sdk "github.com/opensourceways/go-atomgit/atomgit"
+
+	"github.com/opensourceways/community-robot-lib/atomgitclient"
+	framework "github.com/opensourceways/community-robot-lib/robot-atomgit-framework"
+)
+
+const (
+	EventTypePullRequest   = "pull_request"
+	EventTypeReviewComment = "pull_request_review_comment"
+	EventTypeIssues        = "issues"
+	EventTypeIssueComment  = "issue_comment"
+)
+
+// SendHook delivers the event to endpoint like the access robot does,
+// endpoint is generally the url of "/atomgit-hook" of a robot.
+func SendHook(endpoint, eventType string, event interface{}) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return sendPayload(endpoint, eventType, payload)
+}
+
+func sendPayload(endpoint, eventType string, payload []byte) error {
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewBuffer(payload))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", framework.UserAgentHeader)
+	req.Header.Set("X-AtomGit-Event", eventType)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		rb, _ := io.ReadAll(resp.Body)
+
+		return fmt.Errorf("response has status:%s and body:%q", resp.Status, rb)
+	}
+
+	return nil
+}
+
+// PullRequestEvent builds the pull request event of action with the current state of it.
+func (s *Server) PullRequestEvent(org, repo string, number int, action string) (*sdk.PullRequestEvent, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	r, it, err := s.mustGetItem(org, repo, number, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sdk.PullRequestEvent{
+		Action:      sdk.String(action),
+		Number:      sdk.Int(number),
+		PullRequest: r.toPullRequest(it),
+		Repo:        r.toRepository(),
+		Sender:      user(it.author),
+	}, nil
+}
+
+// CommentOnPullRequest adds the comment to pull request and builds the event of it.
+func (s *Server) CommentOnPullRequest(
+	org, repo string, number int, commenter, body string,
+) (*sdk.PullRequestReviewCommentEvent, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	r, it, err := s.mustGetItem(org, repo, number, true)
+	if err != nil {
+		return nil, err
+	}
+
+	c := s.addComment(it, commenter, body).toPRComment()
+	c.HTMLURL = sdk.String(r.htmlURL(it))
+
+	return &sdk.PullRequestReviewCommentEvent{
+		// the robots only handle the comment of which action is opened.
+		Action:      sdk.String(atomgitclient.ActionOpened),
+		PullRequest: r.toPullRequest(it),
+		Comment:     c,
+		Repo:        r.toRepository(),
+		Sender:      user(commenter),
+	}, nil
+}
+
+// IssuesEvent builds the issue event of action with the current state of it.
+func (s *Server) IssuesEvent(org, repo string, number int, action string) (*sdk.IssuesEvent, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	r, it, err := s.mustGetItem(org, repo, number, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sdk.IssuesEvent{
+		Action: sdk.String(action),
+		Issue:  r.toIssue(it),
+		Repo:   r.toRepository(),
+		Sender: user(it.author),
+	}, nil
+}
+
+// CommentOnIssue adds the comment to issue and builds the event of it.
+func (s *Server) CommentOnIssue(org, repo string, number int, commenter, body string) (*sdk.IssueCommentEvent, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	r, it, err := s.mustGetItem(org, repo, number, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sdk.IssueCommentEvent{
+		Action:  sdk.String(sdk.ActionStateCreated),
+		Issue:   r.toIssue(it),
+		Comment: s.addComment(it, commenter, body).toIssueComment(),
+		Repo:    r.toRepository(),
+		Sender:  user(commenter),
+	}, nil
+}
+
+func (s *Server) mustGetItem(org, repo string, number int, onlyPR bool) (*repository, *item, error) {
+	r := s.getRepo(org, repo)
+	if r == nil {
+		return nil, nil, fmt.Errorf("%s/%s does not exist", org, repo)
+	}
+
+	it := r.items[number]
+	if it == nil || (onlyPR && !it.isPR) {
+		return nil, nil, fmt.Errorf("%s/%s:%d does not exist", org, repo, number)
+	}
+
+	return r, it, nil
+}
@@ -0,0 +1,193 @@
+package fakeatomgit
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sdk "github.com/opensourceways/go-atomgit/atomgit"
+
+	"github.com/opensourceways/community-robot-lib/atomgitclient"
+	"github.com/opensourceways/community-robot-lib/config"
+	framework "github.com/opensourceways/community-robot-lib/robot-atomgit-framework"
+)
+
+// ReplayCase describes the fixtures which are replayed through a robot.
+type ReplayCase struct {
+	// FixtureDir is the directory of fixtures recorded by the framework.
+	FixtureDir string
+
+	// GoldenFile holds the api calls expected, one call per line.
+	GoldenFile string
+
+	// ConfigFile is the config of robot.
+	ConfigFile string
+
+	// Login is the user of robot.
+	Login string
+
+	// Setup seeds the fake AtomGit before replaying. It is optional.
+	Setup func(*Server)
+
+	// NewRobot creates the robot with the client of fake AtomGit.
+	NewRobot func(atomgitclient.Client) framework.Robot
+
+	// Update writes the api calls to the golden file instead of comparing them.
+	Update bool
+}
+
+// Replay delivers the fixtures to the robot one by one, and compares the api
+// calls which change the AtomGit with the golden file.
+func Replay(t testing.TB, c ReplayCase) {
+	t.Helper()
+
+	s := NewServer(c.Login)
+	if c.Setup != nil {
+		c.Setup(s)
+	}
+
+	api := httptest.NewServer(s)
+	defer api.Close()
+
+	cli, err := atomgitclient.NewClientWithEndpoint(func() []byte { return []byte("token") }, api.URL)
+	if err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+
+	bot := c.NewRobot(cli)
+
+	agent := config.NewConfigAgent(bot.NewConfig)
+	if err := agent.Start(c.ConfigFile); err != nil {
+		t.Fatalf("load config %s: %v", c.ConfigFile, err)
+	}
+	defer agent.Stop()
+
+	h := framework.NewWebhookHandler(bot, &agent, nil)
+	hook := httptest.NewServer(h)
+	defer hook.Close()
+
+	fixtures, err := framework.LoadFixtures(c.FixtureDir)
+	if err != nil {
+		t.Fatalf("load fixtures: %v", err)
+	}
+
+	for i := range fixtures {
+		f := &fixtures[i]
+
+		if err := s.ImportEvent(f.EventType(), f.Payload); err != nil {
+			t.Fatalf("import fixture %d: %v", i, err)
+		}
+
+		if err := sendPayload(hook.URL, f.EventType(), f.Payload); err != nil {
+			t.Fatalf("send fixture %d: %v", i, err)
+		}
+
+		h.Wait()
+	}
+
+	calls := s.Calls()
+	lines := make([]string, len(calls))
+	for i := range calls {
+		lines[i] = calls[i].String()
+	}
+	got := strings.Join(lines, "\n") + "\n"
+
+	if c.Update {
+		if err := os.MkdirAll(filepath.Dir(c.GoldenFile), 0o755); err != nil {
+			t.Fatalf("create dir of golden file: %v", err)
+		}
+
+		if err := os.WriteFile(c.GoldenFile, []byte(got), 0o644); err != nil {
+			t.Fatalf("update golden file: %v", err)
+		}
+
+		return
+	}
+
+	want, err := os.ReadFile(c.GoldenFile)
+	if err != nil {
+		t.Fatalf("read golden file: %v", err)
+	}
+
+	if got != string(want) {
+		t.Errorf("api calls mismatch the golden file %s\ngot:\n%s\nwant:\n%s", c.GoldenFile, got, want)
+	}
+}
+
+// ImportEvent creates the repository and the pull request or issue carried by
+// the webhook if they do not exist, so that a recorded webhook can be replayed.
+func (s *Server) ImportEvent(eventType string, payload []byte) error {
+	e, err := sdk.ParseWebHook(eventType, payload)
+	if err != nil {
+		return err
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	switch v := e.(type) {
+	case *sdk.PullRequestEvent:
+		s.importPR(v.GetRepo(), v.GetPullRequest())
+	case *sdk.PullRequestReviewCommentEvent:
+		s.importPR(v.GetRepo(), v.GetPullRequest())
+	case *sdk.IssuesEvent:
+		s.importIssue(v.GetRepo(), v.GetIssue())
+	case *sdk.IssueCommentEvent:
+		s.importIssue(v.GetRepo(), v.GetIssue())
+	}
+
+	return nil
+}
+
+func (s *Server) importPR(repo *sdk.Repository, pr *sdk.PullRequest) {
+	org, name := repo.GetOrgAndRepo()
+	r := s.addRepo(org, name)
+	if r.items[pr.GetNumber()] != nil {
+		return
+	}
+
+	it := r.addItem(pr.GetNumber(), pr.GetTitle(), pr.GetBody(), pr.GetUser().GetLogin(), labelNames(pr.Labels))
+	it.isPR = true
+	if v := pr.GetState(); v != "" {
+		it.state = v
+	}
+	it.base = pr.GetBase().GetRef()
+	it.head = pr.GetHead().GetRef()
+	it.mergeable = pr.GetMergeable()
+	it.assignees.Insert(logins(pr.Assignees)...)
+	it.reviewers = logins(pr.RequestedReviewers)
+}
+
+func (s *Server) importIssue(repo *sdk.Repository, is *sdk.Issue) {
+	org, name := repo.GetOrgAndRepo()
+	r := s.addRepo(org, name)
+	if r.items[is.GetNumber()] != nil {
+		return
+	}
+
+	it := r.addItem(is.GetNumber(), is.GetTitle(), is.GetBody(), is.GetUser().GetLogin(), labelNames(is.Labels))
+	if v := is.GetState(); v != "" {
+		it.state = v
+	}
+	it.assignees.Insert(logins(is.Assignees)...)
+}
+
+func labelNames(labels []*sdk.Label) []string {
+	r := make([]string, len(labels))
+	for i := range labels {
+		r[i] = labels[i].GetName()
+	}
+
+	return r
+}
+
+func logins(users []*sdk.User) []string {
+	r := make([]string, len(users))
+	for i := range users {
+		r[i] = users[i].GetLogin()
+	}
+
+	return r
+}
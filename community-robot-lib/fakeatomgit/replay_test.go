@@ -0,0 +1,80 @@
+package fakeatomgit
+
+import (
+	"flag"
+	"fmt"
+	"testing"
+
+	sdk "github.com/opensourceways/go-atomgit/atomgit"
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/community-robot-lib/atomgitclient"
+	"github.com/opensourceways/community-robot-lib/config"
+	framework "github.com/opensourceways/community-robot-lib/robot-atomgit-framework"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files")
+
+type testConfig struct {
+	Repos []string `json:"repos"`
+}
+
+func (c *testConfig) Validate() error { return nil }
+
+func (c *testConfig) SetDefault() {}
+
+// testRobot welcomes the author of pull request and holds it on demand.
+type testRobot struct {
+	cli atomgitclient.Client
+}
+
+func (bot *testRobot) NewConfig() config.Config {
+	return &testConfig{}
+}
+
+func (bot *testRobot) RegisterEventHandler(f framework.HandlerRegister) {
+	f.RegisterPullRequestHandler(bot.handlePullRequest)
+	f.RegisterReviewCommentEventHandler(bot.handleReviewComment)
+}
+
+func (bot *testRobot) handlePullRequest(e *sdk.PullRequestEvent, _ config.Config, _ *logrus.Entry) error {
+	if e.GetAction() != atomgitclient.PRActionOpened {
+		return nil
+	}
+
+	org, repo := e.GetRepo().GetOrgAndRepo()
+	pr := atomgitclient.BuildPRIssue(org, repo, e.GetNumber())
+
+	if err := bot.cli.AddPRLabel(pr, "needs-review"); err != nil {
+		return err
+	}
+
+	return bot.cli.CreatePRComment(pr, fmt.Sprintf("Thanks @%s", e.GetPullRequest().GetUser().GetLogin()))
+}
+
+func (bot *testRobot) handleReviewComment(
+	e *sdk.PullRequestReviewCommentEvent, _ config.Config, _ *logrus.Entry,
+) error {
+	if e.GetComment().GetBody() != "/hold" {
+		return nil
+	}
+
+	org, repo := e.GetRepo().GetOrgAndRepo()
+
+	return bot.cli.AddPRLabel(
+		atomgitclient.BuildPRIssue(org, repo, e.GetPullRequest().GetNumber()), "do-not-merge/hold",
+	)
+}
+
+func TestReplay(t *testing.T) {
+	Replay(t, ReplayCase{
+		FixtureDir: "testdata/replay/fixtures",
+		GoldenFile: "testdata/replay/golden.txt",
+		ConfigFile: "testdata/replay/config.yaml",
+		Login:      testBot,
+		NewRobot: func(cli atomgitclient.Client) framework.Robot {
+			return &testRobot{cli: cli}
+		},
+		Update: *updateGolden,
+	})
+}
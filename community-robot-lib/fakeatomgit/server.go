@@ -0,0 +1,829 @@
+package fakeatomgit
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	sdk "github.com/opensourceways/go-atomgit/atomgit"
+)
+
+const (
+	permissionAdmin = "admin"
+	permissionWrite = "write"
+	permissionRead  = "read"
+	permissionNone  = "none"
+)
+
+type handleFunc func(s *Server, w http.ResponseWriter, r *http.Request, args []string)
+
+type route struct {
+	method string
+	path   *regexp.Regexp
+	handle handleFunc
+}
+
+func newRoute(method, path string, h handleFunc) route {
+	return route{
+		method: method,
+		path:   regexp.MustCompile("^/" + path + "$"),
+		handle: h,
+	}
+}
+
+// the first two arguments of each route below are org and repo.
+var routes = []route{
+	newRoute(http.MethodGet, `repos/([^/]+)/([^/]+)`, getRepo),
+	newRoute(http.MethodGet, `repos/([^/]+)/([^/]+)/labels`, listRepoLabels),
+	newRoute(http.MethodPost, `repos/([^/]+)/([^/]+)/labels`, createRepoLabel),
+
+	newRoute(http.MethodGet, `repos/([^/]+)/([^/]+)/issues/(\d+)`, getIssue),
+	newRoute(http.MethodPatch, `repos/([^/]+)/([^/]+)/issues/(\d+)`, updateIssue),
+	newRoute(http.MethodGet, `repos/([^/]+)/([^/]+)/issues/(\d+)/labels`, listLabels),
+	newRoute(http.MethodPost, `repos/([^/]+)/([^/]+)/issues/(\d+)/labels`, addLabels),
+	newRoute(http.MethodDelete, `repos/([^/]+)/([^/]+)/issues/(\d+)/labels/(.+)`, removeLabel),
+	newRoute(http.MethodPost, `repos/([^/]+)/([^/]+)/issues/(\d+)/assignees`, addAssignees),
+	newRoute(http.MethodDelete, `repos/([^/]+)/([^/]+)/issues/(\d+)/assignees`, removeAssignees),
+	newRoute(http.MethodGet, `repos/([^/]+)/([^/]+)/issues/(\d+)/comments`, listIssueComments),
+	newRoute(http.MethodPost, `repos/([^/]+)/([^/]+)/issues/(\d+)/comments`, createIssueComment),
+	newRoute(http.MethodPatch, `repos/([^/]+)/([^/]+)/issues/comments/(\d+)`, editComment),
+	newRoute(http.MethodGet, `repos/([^/]+)/([^/]+)/issues/(\d+)/timeline`, listTimeline),
+
+	newRoute(http.MethodGet, `repos/([^/]+)/([^/]+)/pulls`, listPullRequests),
+	newRoute(http.MethodGet, `repos/([^/]+)/([^/]+)/pulls/(\d+)`, getPullRequest),
+	newRoute(http.MethodPatch, `repos/([^/]+)/([^/]+)/pulls/(\d+)`, updatePullRequest),
+	newRoute(http.MethodGet, `repos/([^/]+)/([^/]+)/pulls/(\d+)/comments`, listPRComments),
+	newRoute(http.MethodPost, `repos/([^/]+)/([^/]+)/pulls/(\d+)/comments`, createPRComment),
+	newRoute(http.MethodPost, `repos/([^/]+)/([^/]+)/pulls/(\d+)/comments/(\d+)/replies`, createPRComment),
+	newRoute(http.MethodDelete, `repos/([^/]+)/([^/]+)/pulls/comments/(\d+)`, deleteComment),
+	newRoute(http.MethodGet, `repos/([^/]+)/([^/]+)/pulls/(\d+)/commits`, listCommits),
+	newRoute(http.MethodGet, `repos/([^/]+)/([^/]+)/pulls/(\d+)/files`, listFiles),
+	newRoute(http.MethodPut, `repos/([^/]+)/([^/]+)/pulls/(\d+)/merge`, mergePullRequest),
+
+	newRoute(http.MethodGet, `repos/([^/]+)/([^/]+)/collaborators`, listCollaborators),
+	newRoute(http.MethodGet, `repos/([^/]+)/([^/]+)/collaborators/([^/]+)`, isCollaborator),
+	newRoute(http.MethodPut, `repos/([^/]+)/([^/]+)/collaborators/([^/]+)`, addCollaborator),
+	newRoute(http.MethodDelete, `repos/([^/]+)/([^/]+)/collaborators/([^/]+)`, removeCollaborator),
+	newRoute(http.MethodGet, `repos/([^/]+)/([^/]+)/collaborators/([^/]+)/permission`, getPermission),
+
+	newRoute(http.MethodGet, `repos/([^/]+)/([^/]+)/contents/(.+)`, getContent),
+	newRoute(http.MethodPut, `repos/([^/]+)/([^/]+)/contents/(.+)`, createFile),
+	newRoute(http.MethodGet, `repos/([^/]+)/([^/]+)/git/trees/(.+)`, getTree),
+
+	// the argument is org.
+	newRoute(http.MethodGet, `orgs/([^/]+)/repos`, listOrgRepos),
+
+	newRoute(http.MethodGet, `search/issues`, searchIssues),
+}
+
+// ServeHTTP serves the api of AtomGit.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	for i := range routes {
+		rt := &routes[i]
+		if rt.method != r.Method {
+			continue
+		}
+
+		if m := rt.path.FindStringSubmatch(r.URL.Path); m != nil {
+			s.mut.Lock()
+			defer s.mut.Unlock()
+
+			s.recordCall(r)
+			rt.handle(s, w, r, m[1:])
+
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, fmt.Sprintf("unsupported api: %s %s", r.Method, r.URL.Path))
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	if v != nil {
+		_ = json.NewEncoder(w).Encode(v)
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"message": msg})
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+
+		return false
+	}
+
+	return true
+}
+
+func (s *Server) repoOf(w http.ResponseWriter, args []string) *repository {
+	if r := s.getRepo(args[0], args[1]); r != nil {
+		return r
+	}
+
+	writeError(w, http.StatusNotFound, "repository not found")
+
+	return nil
+}
+
+// itemOf returns the repository and the pull request or issue whose number is args[2].
+func (s *Server) itemOf(w http.ResponseWriter, args []string, onlyPR bool) (*repository, *item) {
+	r := s.repoOf(w, args)
+	if r == nil {
+		return nil, nil
+	}
+
+	n, _ := strconv.Atoi(args[2])
+	if it := r.items[n]; it != nil && (it.isPR || !onlyPR) {
+		return r, it
+	}
+
+	writeError(w, http.StatusNotFound, "not found")
+
+	return nil, nil
+}
+
+func getRepo(s *Server, w http.ResponseWriter, _ *http.Request, args []string) {
+	if r := s.repoOf(w, args); r != nil {
+		writeJSON(w, http.StatusOK, r.toRepository())
+	}
+}
+
+func listOrgRepos(s *Server, w http.ResponseWriter, _ *http.Request, args []string) {
+	names := make([]string, 0, len(s.repos))
+	for k, r := range s.repos {
+		if r.org == args[0] {
+			names = append(names, k)
+		}
+	}
+
+	sort.Strings(names)
+
+	v := make([]*sdk.Repository, len(names))
+	for i, k := range names {
+		v[i] = s.repos[k].toRepository()
+	}
+
+	writeJSON(w, http.StatusOK, v)
+}
+
+// searchIssues supports the qualifiers of is:pr, is:issue, is:open, is:closed,
+// is:merged, author, org and repo. The other terms of query are ignored.
+func searchIssues(s *Server, w http.ResponseWriter, req *http.Request, _ []string) {
+	match := func(r *repository, it *item) bool {
+		for _, term := range strings.Fields(req.URL.Query().Get("q")) {
+			k, v, _ := strings.Cut(term, ":")
+
+			ok := true
+			switch k + ":" + v {
+			case "is:pr":
+				ok = it.isPR
+			case "is:issue":
+				ok = !it.isPR
+			case "is:open", "is:closed":
+				ok = it.state == v
+			case "is:merged":
+				ok = it.merge != nil
+			default:
+				switch k {
+				case "author":
+					ok = strings.EqualFold(it.author, v)
+				case "org":
+					ok = strings.EqualFold(r.org, v)
+				case "repo":
+					ok = strings.EqualFold(r.fullName(), v)
+				}
+			}
+
+			if !ok {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	names := make([]string, 0, len(s.repos))
+	for k := range s.repos {
+		names = append(names, k)
+	}
+
+	sort.Strings(names)
+
+	v := []*sdk.Issue{}
+	for _, k := range names {
+		r := s.repos[k]
+
+		numbers := make([]int, 0, len(r.items))
+		for n := range r.items {
+			numbers = append(numbers, n)
+		}
+
+		sort.Ints(numbers)
+
+		for _, n := range numbers {
+			if it := r.items[n]; match(r, it) {
+				v = append(v, r.toIssue(it))
+			}
+		}
+	}
+
+	writeJSON(w, http.StatusOK, &sdk.IssuesSearchResult{
+		Total:             sdk.Int(len(v)),
+		IncompleteResults: sdk.Bool(false),
+		Issues:            v,
+	})
+}
+
+func listRepoLabels(s *Server, w http.ResponseWriter, _ *http.Request, args []string) {
+	if r := s.repoOf(w, args); r != nil {
+		writeJSON(w, http.StatusOK, toLabels(r.labels))
+	}
+}
+
+func createRepoLabel(s *Server, w http.ResponseWriter, req *http.Request, args []string) {
+	r := s.repoOf(w, args)
+	if r == nil {
+		return
+	}
+
+	var l sdk.Label
+	if !readJSON(w, req, &l) {
+		return
+	}
+
+	if r.labels.Has(l.GetName()) {
+		writeError(w, http.StatusUnprocessableEntity, "label already exists")
+
+		return
+	}
+
+	r.labels.Insert(l.GetName())
+	writeJSON(w, http.StatusCreated, &l)
+}
+
+func getIssue(s *Server, w http.ResponseWriter, _ *http.Request, args []string) {
+	if r, it := s.itemOf(w, args, false); it != nil {
+		writeJSON(w, http.StatusOK, r.toIssue(it))
+	}
+}
+
+func updateIssue(s *Server, w http.ResponseWriter, req *http.Request, args []string) {
+	r, it := s.itemOf(w, args, false)
+	if it == nil {
+		return
+	}
+
+	var v sdk.IssueRequest
+	if !readJSON(w, req, &v) {
+		return
+	}
+
+	if v.Title != nil {
+		it.title = *v.Title
+	}
+
+	if v.Body != nil {
+		it.body = *v.Body
+	}
+
+	if v.State != nil {
+		it.state = *v.State
+	}
+
+	if v.Labels != nil {
+		it.labels.Delete(it.labels.UnsortedList()...)
+		s.addLabels(r, it, *v.Labels)
+	}
+
+	if v.Assignees != nil {
+		it.assignees.Delete(it.assignees.UnsortedList()...)
+		it.assignees.Insert(*v.Assignees...)
+	}
+
+	writeJSON(w, http.StatusOK, r.toIssue(it))
+}
+
+func listLabels(s *Server, w http.ResponseWriter, _ *http.Request, args []string) {
+	if _, it := s.itemOf(w, args, false); it != nil {
+		writeJSON(w, http.StatusOK, toLabels(it.labels))
+	}
+}
+
+func addLabels(s *Server, w http.ResponseWriter, req *http.Request, args []string) {
+	r, it := s.itemOf(w, args, false)
+	if it == nil {
+		return
+	}
+
+	var v []string
+	if !readJSON(w, req, &v) {
+		return
+	}
+
+	s.addLabels(r, it, v)
+	writeJSON(w, http.StatusOK, toLabels(it.labels))
+}
+
+func removeLabel(s *Server, w http.ResponseWriter, _ *http.Request, args []string) {
+	_, it := s.itemOf(w, args, false)
+	if it == nil {
+		return
+	}
+
+	if !it.labels.Has(args[3]) {
+		writeError(w, http.StatusNotFound, "label does not exist")
+
+		return
+	}
+
+	it.labels.Delete(args[3])
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+type assigneesRequest struct {
+	Assignees []string `json:"assignees"`
+}
+
+func addAssignees(s *Server, w http.ResponseWriter, req *http.Request, args []string) {
+	r, it := s.itemOf(w, args, false)
+	if it == nil {
+		return
+	}
+
+	var v assigneesRequest
+	if !readJSON(w, req, &v) {
+		return
+	}
+
+	it.assignees.Insert(v.Assignees...)
+	writeJSON(w, http.StatusCreated, r.toIssue(it))
+}
+
+func removeAssignees(s *Server, w http.ResponseWriter, req *http.Request, args []string) {
+	r, it := s.itemOf(w, args, false)
+	if it == nil {
+		return
+	}
+
+	var v assigneesRequest
+	if !readJSON(w, req, &v) {
+		return
+	}
+
+	it.assignees.Delete(v.Assignees...)
+	writeJSON(w, http.StatusOK, r.toIssue(it))
+}
+
+func listIssueComments(s *Server, w http.ResponseWriter, _ *http.Request, args []string) {
+	_, it := s.itemOf(w, args, false)
+	if it == nil {
+		return
+	}
+
+	v := make([]*sdk.IssueComment, len(it.comments))
+	for i, c := range it.comments {
+		v[i] = c.toIssueComment()
+	}
+
+	writeJSON(w, http.StatusOK, v)
+}
+
+func createIssueComment(s *Server, w http.ResponseWriter, req *http.Request, args []string) {
+	_, it := s.itemOf(w, args, false)
+	if it == nil {
+		return
+	}
+
+	var v sdk.IssueComment
+	if !readJSON(w, req, &v) {
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, s.addComment(it, s.Login, v.GetBody()).toIssueComment())
+}
+
+func (r *repository) findComment(id int64) (*item, int) {
+	for _, it := range r.items {
+		for i, c := range it.comments {
+			if c.ID == id {
+				return it, i
+			}
+		}
+	}
+
+	return nil, -1
+}
+
+func editComment(s *Server, w http.ResponseWriter, req *http.Request, args []string) {
+	r := s.repoOf(w, args)
+	if r == nil {
+		return
+	}
+
+	id, _ := strconv.ParseInt(args[2], 10, 64)
+	it, i := r.findComment(id)
+	if it == nil {
+		writeError(w, http.StatusNotFound, "comment not found")
+
+		return
+	}
+
+	var v sdk.IssueComment
+	if !readJSON(w, req, &v) {
+		return
+	}
+
+	c := it.comments[i]
+	c.Body = v.GetBody()
+	c.UpdatedAt = time.Now()
+
+	writeJSON(w, http.StatusOK, c.toIssueComment())
+}
+
+func deleteComment(s *Server, w http.ResponseWriter, _ *http.Request, args []string) {
+	r := s.repoOf(w, args)
+	if r == nil {
+		return
+	}
+
+	id, _ := strconv.ParseInt(args[2], 10, 64)
+	it, i := r.findComment(id)
+	if it == nil {
+		writeError(w, http.StatusNotFound, "comment not found")
+
+		return
+	}
+
+	it.comments = append(it.comments[:i], it.comments[i+1:]...)
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+func listTimeline(s *Server, w http.ResponseWriter, _ *http.Request, args []string) {
+	if _, it := s.itemOf(w, args, false); it != nil {
+		writeJSON(w, http.StatusOK, it.timeline)
+	}
+}
+
+func listPullRequests(s *Server, w http.ResponseWriter, req *http.Request, args []string) {
+	r := s.repoOf(w, args)
+	if r == nil {
+		return
+	}
+
+	state := req.URL.Query().Get("state")
+	if state == "" {
+		state = stateOpen
+	}
+
+	v := make([]*sdk.PullRequest, 0, len(r.items))
+	for n := 1; n < r.nextNumber; n++ {
+		if it := r.items[n]; it != nil && it.isPR && (state == "all" || it.state == state) {
+			v = append(v, r.toPullRequest(it))
+		}
+	}
+
+	writeJSON(w, http.StatusOK, v)
+}
+
+func getPullRequest(s *Server, w http.ResponseWriter, _ *http.Request, args []string) {
+	if r, it := s.itemOf(w, args, true); it != nil {
+		writeJSON(w, http.StatusOK, r.toPullRequest(it))
+	}
+}
+
+func updatePullRequest(s *Server, w http.ResponseWriter, req *http.Request, args []string) {
+	r, it := s.itemOf(w, args, true)
+	if it == nil {
+		return
+	}
+
+	var v struct {
+		Title *string `json:"title,omitempty"`
+		Body  *string `json:"body,omitempty"`
+		State *string `json:"state,omitempty"`
+		Base  *string `json:"base,omitempty"`
+	}
+	if !readJSON(w, req, &v) {
+		return
+	}
+
+	if v.Title != nil {
+		it.title = *v.Title
+	}
+
+	if v.Body != nil {
+		it.body = *v.Body
+	}
+
+	if v.State != nil {
+		it.state = *v.State
+	}
+
+	if v.Base != nil {
+		it.base = *v.Base
+	}
+
+	writeJSON(w, http.StatusOK, r.toPullRequest(it))
+}
+
+func listPRComments(s *Server, w http.ResponseWriter, _ *http.Request, args []string) {
+	_, it := s.itemOf(w, args, true)
+	if it == nil {
+		return
+	}
+
+	v := make([]*sdk.PullRequestComment, len(it.comments))
+	for i, c := range it.comments {
+		v[i] = c.toPRComment()
+	}
+
+	writeJSON(w, http.StatusOK, v)
+}
+
+func createPRComment(s *Server, w http.ResponseWriter, req *http.Request, args []string) {
+	_, it := s.itemOf(w, args, true)
+	if it == nil {
+		return
+	}
+
+	var v sdk.PullRequestComment
+	if !readJSON(w, req, &v) {
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, s.addComment(it, s.Login, v.GetBody()).toPRComment())
+}
+
+func listCommits(s *Server, w http.ResponseWriter, _ *http.Request, args []string) {
+	if _, it := s.itemOf(w, args, true); it != nil {
+		writeJSON(w, http.StatusOK, it.commits)
+	}
+}
+
+func listFiles(s *Server, w http.ResponseWriter, _ *http.Request, args []string) {
+	_, it := s.itemOf(w, args, true)
+	if it == nil {
+		return
+	}
+
+	v := make([]*sdk.CommitFile, len(it.files))
+	for i := range it.files {
+		v[i] = &sdk.CommitFile{Filename: sdk.String(it.files[i])}
+	}
+
+	writeJSON(w, http.StatusOK, v)
+}
+
+func mergePullRequest(s *Server, w http.ResponseWriter, req *http.Request, args []string) {
+	r, it := s.itemOf(w, args, true)
+	if it == nil {
+		return
+	}
+
+	var v struct {
+		CommitMessage string `json:"commit_message,omitempty"`
+		CommitTitle   string `json:"commit_title,omitempty"`
+		MergeMethod   string `json:"merge_method,omitempty"`
+	}
+	if !readJSON(w, req, &v) {
+		return
+	}
+
+	if it.merge != nil || it.state != stateOpen {
+		writeError(w, http.StatusMethodNotAllowed, "pull request is not open")
+
+		return
+	}
+
+	if !it.mergeable {
+		writeError(w, http.StatusMethodNotAllowed, "there are conflicting files")
+
+		return
+	}
+
+	if !r.allowMergeMethod(v.MergeMethod) {
+		writeError(w, http.StatusMethodNotAllowed, "merge method is not allowed")
+
+		return
+	}
+
+	it.merge = &Merge{
+		Method:        v.MergeMethod,
+		CommitTitle:   v.CommitTitle,
+		CommitMessage: v.CommitMessage,
+		MergedBy:      s.Login,
+	}
+	it.state = stateClosed
+
+	writeJSON(w, http.StatusOK, &sdk.PullRequestMergeResult{
+		Merged:  sdk.Bool(true),
+		Message: sdk.String("Pull Request successfully merged"),
+	})
+}
+
+// permissionsOf returns the permission map of collaborator which
+// the robots read, such as the welcome robot.
+func permissionsOf(permission string) map[string]bool {
+	return map[string]bool{
+		"Admin": permission == permissionAdmin,
+		"Push":  permission == permissionAdmin || permission == permissionWrite,
+		"Pull":  permission != permissionNone,
+	}
+}
+
+func listCollaborators(s *Server, w http.ResponseWriter, _ *http.Request, args []string) {
+	r := s.repoOf(w, args)
+	if r == nil {
+		return
+	}
+
+	logins := make([]string, 0, len(r.collaborators))
+	for k := range r.collaborators {
+		logins = append(logins, k)
+	}
+
+	sort.Strings(logins)
+
+	v := users(logins)
+	for _, u := range v {
+		u.Permissions = permissionsOf(r.collaborators[u.GetLogin()])
+	}
+
+	writeJSON(w, http.StatusOK, v)
+}
+
+func isCollaborator(s *Server, w http.ResponseWriter, _ *http.Request, args []string) {
+	r := s.repoOf(w, args)
+	if r == nil {
+		return
+	}
+
+	if _, ok := r.collaborators[args[2]]; ok {
+		writeJSON(w, http.StatusNoContent, nil)
+	} else {
+		writeError(w, http.StatusNotFound, "not a collaborator")
+	}
+}
+
+func addCollaborator(s *Server, w http.ResponseWriter, req *http.Request, args []string) {
+	r := s.repoOf(w, args)
+	if r == nil {
+		return
+	}
+
+	var v sdk.RepositoryAddCollaboratorOptions
+	if !readJSON(w, req, &v) {
+		return
+	}
+
+	if v.Permission == "" {
+		v.Permission = permissionWrite
+	}
+
+	r.collaborators[args[2]] = v.Permission
+	writeJSON(w, http.StatusNoContent, nil)
+}
+
+func removeCollaborator(s *Server, w http.ResponseWriter, _ *http.Request, args []string) {
+	if r := s.repoOf(w, args); r != nil {
+		delete(r.collaborators, args[2])
+		writeJSON(w, http.StatusNoContent, nil)
+	}
+}
+
+func getPermission(s *Server, w http.ResponseWriter, _ *http.Request, args []string) {
+	r := s.repoOf(w, args)
+	if r == nil {
+		return
+	}
+
+	p, ok := r.collaborators[args[2]]
+	if !ok {
+		p = permissionRead
+	}
+
+	writeJSON(w, http.StatusOK, &sdk.RepositoryPermissionLevel{
+		Permission: sdk.String(p),
+		User:       user(args[2]),
+	})
+}
+
+func getContent(s *Server, w http.ResponseWriter, req *http.Request, args []string) {
+	r := s.repoOf(w, args)
+	if r == nil {
+		return
+	}
+
+	path := args[2]
+	c, ok := r.files[req.URL.Query().Get("ref")][path]
+	if !ok {
+		writeError(w, http.StatusNotFound, "file not found")
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &sdk.RepositoryContent{
+		Type:     sdk.String("file"),
+		Encoding: sdk.String("base64"),
+		Size:     sdk.Int(len(c)),
+		Name:     sdk.String(path[strings.LastIndex(path, "/")+1:]),
+		Path:     sdk.String(path),
+		Content:  sdk.String(base64.StdEncoding.EncodeToString(c)),
+		SHA:      sdk.String(blobSHA(c)),
+	})
+}
+
+func createFile(s *Server, w http.ResponseWriter, req *http.Request, args []string) {
+	r := s.repoOf(w, args)
+	if r == nil {
+		return
+	}
+
+	var v sdk.RepositoryContentFileOptions
+	if !readJSON(w, req, &v) {
+		return
+	}
+
+	r.setFile(v.GetBranch(), args[2], v.Content)
+	writeJSON(w, http.StatusCreated, &sdk.RepositoryContentResponse{
+		Content: &sdk.RepositoryContent{
+			Path: sdk.String(args[2]),
+			SHA:  sdk.String(blobSHA(v.Content)),
+		},
+	})
+}
+
+func getTree(s *Server, w http.ResponseWriter, req *http.Request, args []string) {
+	r := s.repoOf(w, args)
+	if r == nil {
+		return
+	}
+
+	branch := args[2]
+	if _, ok := r.files[branch]; !ok {
+		writeError(w, http.StatusNotFound, "branch not found")
+
+		return
+	}
+
+	recursive := req.URL.Query().Get("recursive") != ""
+	entries := []*sdk.TreeEntry{}
+	dirs := map[string]bool{}
+
+	for _, p := range r.sortedFiles(branch) {
+		items := strings.Split(p, "/")
+		if !recursive && len(items) > 1 {
+			if !dirs[items[0]] {
+				dirs[items[0]] = true
+				entries = append(entries, treeEntry(items[0], "tree"))
+			}
+
+			continue
+		}
+
+		for i := 1; i < len(items); i++ {
+			if dir := strings.Join(items[:i], "/"); !dirs[dir] {
+				dirs[dir] = true
+				entries = append(entries, treeEntry(dir, "tree"))
+			}
+		}
+
+		entries = append(entries, treeEntry(p, "blob"))
+	}
+
+	writeJSON(w, http.StatusOK, &sdk.Tree{
+		SHA:       sdk.String(branch),
+		Entries:   entries,
+		Truncated: sdk.Bool(false),
+	})
+}
+
+func treeEntry(path, kind string) *sdk.TreeEntry {
+	return &sdk.TreeEntry{
+		Path: sdk.String(path),
+		Type: sdk.String(kind),
+	}
+}
+
+// blobSHA computes the sha of content as git does.
+func blobSHA(content []byte) string {
+	h := sha1.New()
+	_, _ = fmt.Fprintf(h, "blob %d\x00", len(content))
+	_, _ = h.Write(content)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -7,10 +7,10 @@ import (
 )
 
 type event struct {
-	m    *mq.Message
-	km   *sarama.ConsumerMessage
-	err  error
-	sess sarama.ConsumerGroupSession
+	m       *mq.Message
+	km      *sarama.ConsumerMessage
+	err     error
+	tracker *offsetTracker
 }
 
 func (e *event) Topic() string {
@@ -25,8 +25,9 @@ func (e *event) Message() *mq.Message {
 	return e.m
 }
 
+// Ack acks the message, and its offset is committed after the messages before it are acked.
 func (e *event) Ack() error {
-	e.sess.MarkMessage(e.km, "")
+	e.tracker.ack(e.km.Offset)
 
 	return nil
 }
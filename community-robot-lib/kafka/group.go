@@ -17,6 +17,9 @@ type groupConsumer struct {
 	handler mq.Handler
 	subOpts mq.SubscribeOptions
 
+	// publish publishes the dead letters.
+	publish func(topic string, m *mq.Message) error
+
 	notifyReady func()
 }
 
@@ -34,24 +37,30 @@ func (gc *groupConsumer) Cleanup(sarama.ConsumerGroupSession) error {
 
 // ConsumeClaim must start a groupConsumer loop of ConsumerGroupClaim's Messages().
 func (gc *groupConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
-	handle := gc.genHanler(session)
+	tracker := newOffsetTracker(func(offset int64) {
+		session.MarkOffset(claim.Topic(), claim.Partition(), offset, "")
+	})
+
+	handle := gc.genHanler(session, tracker)
 
 	for {
 		select {
-		case message := <-claim.Messages():
-			handle(message)
-
-			if gc.subOpts.AutoAck {
-				session.MarkMessage(message, "")
+		case message, ok := <-claim.Messages():
+			if !ok {
+				return nil
 			}
 
+			handle(message)
+
 		case <-session.Context().Done():
 			return nil
 		}
 	}
 }
 
-func (gc *groupConsumer) genHanler(session sarama.ConsumerGroupSession) func(*sarama.ConsumerMessage) {
+func (gc *groupConsumer) genHanler(
+	session sarama.ConsumerGroupSession, tracker *offsetTracker,
+) func(*sarama.ConsumerMessage) {
 	handler := gc.handler
 	if handler == nil {
 		handler = func(event mq.Event) error {
@@ -71,35 +80,115 @@ func (gc *groupConsumer) genHanler(session sarama.ConsumerGroupSession) func(*sa
 	}
 
 	unmarshal := gc.kOpts.Codec.Unmarshal
+	opts := &gc.subOpts
+
+	// fail sends the message to the dead letter topic and acks it, or holds it if there is no such topic.
+	fail := func(ke *event) {
+		if err := eh(ke); err != nil {
+			log.Error(err)
+		}
+
+		if opts.DeadLetterTopic == "" {
+			return
+		}
+
+		dl := mq.NewDeadLetter(ke.km.Topic, ke.m, ke.err)
+		if err := gc.publish(opts.DeadLetterTopic, dl); err != nil {
+			log.Errorf("publish to dead letter topic:%s, err:%s", opts.DeadLetterTopic, err.Error())
+
+			return
+		}
+
+		_ = ke.Ack()
+	}
 
 	return func(msg *sarama.ConsumerMessage) {
+		tracker.add(msg.Offset)
+
 		ke := &event{
-			km:   msg,
-			m:    new(mq.Message),
-			sess: session,
+			km:      msg,
+			m:       new(mq.Message),
+			tracker: tracker,
 		}
 
 		if err := unmarshal(msg.Value, ke.m); err != nil {
 			ke.err = fmt.Errorf("unmarshal msg failed, err: %v", err)
 			ke.m.Body = msg.Value
 
-			if err := eh(ke); err != nil {
-				log.Error(err)
-			}
+			fail(ke)
 
 			return
 		}
 
-		if err := handler(ke); err != nil {
-			ke.err = fmt.Errorf("handle event, err: %v", err)
+		if len(msg.Key) > 0 {
+			ke.m.SetMessageKey(string(msg.Key))
+		}
 
-			if err := eh(ke); err != nil {
-				log.Error(err)
+		if err := opts.Retry.Handle(session.Context(), ke, handler); err != nil {
+			// the retries are interrupted, and it will be consumed again after the rebalance.
+			if session.Context().Err() != nil {
+				return
 			}
+
+			ke.err = fmt.Errorf("handle event, err: %v", err)
+
+			fail(ke)
+
+			return
+		}
+
+		if opts.AutoAck {
+			_ = ke.Ack()
 		}
 	}
 }
 
+// offsetTracker marks the offset of partition only when all the messages before it
+// are acked, so that the message not acked is consumed again after the restart or
+// rebalance, even if the messages after it are acked.
+type offsetTracker struct {
+	mark func(offset int64)
+
+	mut sync.Mutex
+	// pending is the offsets of the messages consumed but not marked, in order.
+	pending []int64
+	acked   map[int64]bool
+}
+
+func newOffsetTracker(mark func(int64)) *offsetTracker {
+	return &offsetTracker{
+		mark:  mark,
+		acked: map[int64]bool{},
+	}
+}
+
+func (t *offsetTracker) add(offset int64) {
+	t.mut.Lock()
+	t.pending = append(t.pending, offset)
+	t.mut.Unlock()
+}
+
+func (t *offsetTracker) ack(offset int64) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	t.acked[offset] = true
+
+	i := 0
+	for ; i < len(t.pending) && t.acked[t.pending[i]]; i++ {
+		delete(t.acked, t.pending[i])
+	}
+
+	if i == 0 {
+		return
+	}
+
+	// mark the offset of the next message to consume.
+	t.mark(t.pending[i-1] + 1)
+
+	t.pending = t.pending[i:]
+}
+
 type subscriber struct {
 	cli sarama.Client
 	cg  sarama.ConsumerGroup
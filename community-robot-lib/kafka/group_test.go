@@ -0,0 +1,27 @@
+package kafka
+
+import "testing"
+
+func TestOffsetTracker(t *testing.T) {
+	var marked []int64
+	tracker := newOffsetTracker(func(offset int64) {
+		marked = append(marked, offset)
+	})
+
+	for _, v := range []int64{3, 4, 6} {
+		tracker.add(v)
+	}
+
+	// the offset is held by the message 3 not acked.
+	tracker.ack(4)
+	if len(marked) != 0 {
+		t.Fatalf("marked = %v", marked)
+	}
+
+	tracker.ack(3)
+	tracker.ack(6)
+
+	if len(marked) != 2 || marked[0] != 5 || marked[1] != 7 {
+		t.Errorf("marked = %v", marked)
+	}
+}
@@ -117,6 +117,10 @@ func (kMQ *kfkMQ) Disconnect() error {
 
 // Publish a message to a topic in the kafka cluster.
 func (kMQ *kfkMQ) Publish(topic string, msg *mq.Message, opts ...mq.PublishOption) error {
+	if !kMQ.isConnected() {
+		return errors.New("mq is not connected")
+	}
+
 	d, err := kMQ.opts.Codec.Marshal(msg)
 	if err != nil {
 		return err
@@ -165,6 +169,9 @@ func (kMQ *kfkMQ) Subscribe(topics string, h mq.Handler, opts ...mq.SubscribeOpt
 		handler: h,
 		subOpts: opt,
 		kOpts:   kMQ.opts,
+		publish: func(topic string, m *mq.Message) error {
+			return kMQ.Publish(topic, m)
+		},
 	}
 
 	s := newSubscriber(topics, c, g, gc)
@@ -0,0 +1,390 @@
+package localmq
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/community-robot-lib/utils"
+)
+
+const (
+	topicsDir   = "topics"
+	offsetsFile = "offsets.json"
+	logSuffix   = ".log"
+
+	// compactRecords is the least number of messages dropped to rewrite the file of partition.
+	compactRecords = 1024
+)
+
+// diskStore saves each partition of topic as a file of json lines under dir/topics/<topic>,
+// and the committed offsets of the named groups in dir/offsets.json. All the messages kept
+// are loaded into memory on connecting, so it suits the small volume of messages. The file
+// is rewritten without the messages which all the groups have consumed.
+type diskStore struct {
+	dir string
+
+	// files maps the topic to the files of its partitions opened to append.
+	files map[string][]*os.File
+
+	// starts maps the topic to the offsets of the first messages in the files of its partitions.
+	starts map[string][]int64
+}
+
+func (s *diskStore) topicDir(topic string) string {
+	return filepath.Join(s.dir, topicsDir, url.PathEscape(topic))
+}
+
+func (s *diskStore) partitionFile(topic string, partition int) string {
+	return filepath.Join(s.topicDir(topic), strconv.Itoa(partition)+logSuffix)
+}
+
+// load loads the topics and the committed offsets, and opens the files to append.
+func (s *diskStore) load() (map[string]*topic, error) {
+	s.files = map[string][]*os.File{}
+	s.starts = map[string][]int64{}
+
+	if err := os.MkdirAll(filepath.Join(s.dir, topicsDir), 0o755); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(s.dir, topicsDir))
+	if err != nil {
+		return nil, err
+	}
+
+	topics := map[string]*topic{}
+
+	for _, item := range entries {
+		if !item.IsDir() {
+			continue
+		}
+
+		name, err := url.PathUnescape(item.Name())
+		if err != nil {
+			continue
+		}
+
+		t, err := s.loadTopic(name)
+		if err != nil {
+			_ = s.close()
+
+			return nil, err
+		}
+
+		topics[name] = t
+	}
+
+	if err := s.loadOffsets(topics); err != nil {
+		_ = s.close()
+
+		return nil, err
+	}
+
+	return topics, nil
+}
+
+func (s *diskStore) loadTopic(name string) (*topic, error) {
+	n := 0
+	for {
+		if _, err := os.Stat(s.partitionFile(name, n)); err != nil {
+			break
+		}
+
+		n++
+	}
+
+	if n == 0 {
+		return nil, fmt.Errorf("no partition of topic: %s", name)
+	}
+
+	t := newTopic(name, n)
+	files := make([]*os.File, n)
+
+	for i := range files {
+		records, f, err := s.loadPartition(s.partitionFile(name, i))
+		if err != nil {
+			for _, v := range files[:i] {
+				_ = v.Close()
+			}
+
+			return nil, err
+		}
+
+		t.partitions[i] = records
+		if len(records) > 0 {
+			t.base[i] = records[0].Offset
+		}
+
+		files[i] = f
+	}
+
+	s.files[name] = files
+	s.starts[name] = append([]int64(nil), t.base...)
+
+	return t, nil
+}
+
+// loadPartition reads the records of partition. The incomplete record at the end,
+// which is left by a crash during writing, is truncated.
+func (s *diskStore) loadPartition(path string) ([]record, *os.File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var records []record
+	var size int64
+
+	r := bufio.NewReader(f)
+
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			_ = f.Close()
+
+			return nil, nil, err
+		}
+
+		var v record
+		if err := json.Unmarshal(bytes.TrimSpace(line), &v); err != nil {
+			logrus.WithError(err).Warnf("invalid record at offset %d of %s", len(records), path)
+
+			break
+		}
+
+		records = append(records, v)
+		size += int64(len(line))
+	}
+
+	if err := f.Truncate(size); err != nil {
+		_ = f.Close()
+
+		return nil, nil, err
+	}
+
+	if _, err := f.Seek(size, io.SeekStart); err != nil {
+		_ = f.Close()
+
+		return nil, nil, err
+	}
+
+	return records, f, nil
+}
+
+func (s *diskStore) loadOffsets(topics map[string]*topic) error {
+	b, err := os.ReadFile(filepath.Join(s.dir, offsetsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	var v map[string]map[string][]int64
+	if err := json.Unmarshal(b, &v); err != nil {
+		return fmt.Errorf("invalid offsets file, err: %s", err.Error())
+	}
+
+	for name, groups := range v {
+		t, ok := topics[name]
+		if !ok {
+			continue
+		}
+
+		for gn, offsets := range groups {
+			g := t.newGroup(gn, false)
+
+			// the group starts from the first message kept if the offsets are stale.
+			for i := range g.committed {
+				if i < len(offsets) && offsets[i] < g.committed[i] {
+					g.committed[i] = offsets[i]
+				}
+
+				if g.committed[i] < t.base[i] {
+					g.committed[i] = t.base[i]
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *diskStore) createTopic(name string, partitions int) error {
+	if _, ok := s.files[name]; ok {
+		return nil
+	}
+
+	if err := os.MkdirAll(s.topicDir(name), 0o755); err != nil {
+		return err
+	}
+
+	files := make([]*os.File, partitions)
+
+	for i := range files {
+		f, err := os.OpenFile(s.partitionFile(name, i), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			for _, v := range files[:i] {
+				_ = v.Close()
+			}
+
+			return err
+		}
+
+		files[i] = f
+	}
+
+	s.files[name] = files
+	s.starts[name] = make([]int64, partitions)
+
+	return nil
+}
+
+// append writes the record to the file of partition, and syncs it so that it is not lost.
+func (s *diskStore) append(topic string, partition int, r *record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	f := s.files[topic][partition]
+
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		return err
+	}
+
+	return f.Sync()
+}
+
+// compact rewrites the file of partition with the records kept, whose first offset is base,
+// if the records dropped from the file are not less than the ones kept and compactRecords,
+// or if force is true. So each record is rewritten in the amortized constant time.
+func (s *diskStore) compact(topic string, partition int, base int64, records []record, force bool) error {
+	dropped := base - s.starts[topic][partition]
+	if dropped <= 0 || (!force && (dropped < compactRecords || dropped < int64(len(records)))) {
+		return nil
+	}
+
+	path := s.partitionFile(topic, partition)
+
+	tmp, err := os.CreateTemp(s.topicDir(topic), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+
+	for i := range records {
+		if err = enc.Encode(&records[i]); err != nil {
+			break
+		}
+	}
+
+	if err == nil {
+		err = w.Flush()
+	}
+
+	if err == nil {
+		err = tmp.Sync()
+	}
+
+	if e := tmp.Close(); err == nil {
+		err = e
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	_ = s.files[topic][partition].Close()
+	s.files[topic][partition] = f
+	s.starts[topic][partition] = base
+
+	return nil
+}
+
+// saveOffsets writes to a temporary file and renames it, so that the offsets are not corrupted by a crash.
+func (s *diskStore) saveOffsets(topics map[string]*topic) error {
+	v := map[string]map[string][]int64{}
+
+	for name, t := range topics {
+		groups := map[string][]int64{}
+
+		for gn, g := range t.groups {
+			if !g.anonymous {
+				groups[gn] = g.committed
+			}
+		}
+
+		if len(groups) > 0 {
+			v[name] = groups
+		}
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, offsetsFile+".*")
+	if err != nil {
+		return err
+	}
+
+	if _, err = tmp.Write(b); err == nil {
+		err = tmp.Close()
+	} else {
+		_ = tmp.Close()
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(s.dir, offsetsFile))
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+
+	return err
+}
+
+func (s *diskStore) close() error {
+	mErr := utils.MultiError{}
+
+	for _, files := range s.files {
+		for _, f := range files {
+			mErr.AddError(f.Close())
+		}
+	}
+
+	s.files = nil
+	s.starts = nil
+
+	return mErr.Err()
+}
@@ -0,0 +1,38 @@
+package localmq
+
+import "github.com/opensourceways/community-robot-lib/mq"
+
+type event struct {
+	s   *subscriber
+	r   record
+	m   *mq.Message
+	err error
+
+	partition int
+	offset    int64
+}
+
+func (e *event) Topic() string {
+	return e.s.t.name
+}
+
+func (e *event) Message() *mq.Message {
+	return e.m
+}
+
+// Ack acks the message, and the offset is committed after the messages before it are acked.
+func (e *event) Ack() error {
+	return e.s.m.ack(e.s.t, e.s.g, e.partition, e.offset)
+}
+
+func (e *event) Error() error {
+	return e.err
+}
+
+func (e *event) Extra() map[string]interface{} {
+	return map[string]interface{}{
+		"time":      e.r.Time,
+		"offset":    e.offset,
+		"partition": e.partition,
+	}
+}
@@ -0,0 +1,517 @@
+// Package localmq implements mq.MQ in the process. The messages are kept in memory,
+// or in a directory of the local disk so that they survive the restart. It is for the
+// local development, the tests and the single node deployments.
+//
+// It works like kafka. The topic is split into partitions, and the messages of the
+// same key are in the same partition. The subscribers of the same queue share the
+// partitions, and a new queue starts from the newest messages. The offset of a
+// partition is committed when the messages before it are all acked, and the messages
+// after the committed offset are delivered again when the partitions are assigned to
+// another subscriber. The messages which all the queues have consumed are dropped.
+package localmq
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/community-robot-lib/mq"
+)
+
+const defaultPartitions = 1
+
+var errNotConnected = errors.New("mq is not connected")
+
+type partitionsKey struct{}
+
+// Partitions sets the number of partitions of the topics created. The default is 1.
+// It does not change the topics which exist on the disk.
+func Partitions(n int) mq.Option {
+	return mq.ContextWithValue(partitionsKey{}, n)
+}
+
+// NewMemoryMQ returns the mq whose messages are kept in memory. The messages are
+// kept after disconnecting, and they are available after connecting again.
+func NewMemoryMQ(opts ...mq.Option) mq.MQ {
+	return newLocalMQ(nil, opts...)
+}
+
+// NewDiskMQ returns the mq whose messages and committed offsets are saved in dir.
+func NewDiskMQ(dir string, opts ...mq.Option) mq.MQ {
+	return newLocalMQ(&diskStore{dir: dir}, opts...)
+}
+
+func newLocalMQ(store *diskStore, opts ...mq.Option) *localMQ {
+	options := mq.Options{
+		Codec:   mq.JsonCodec{},
+		Context: context.Background(),
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if options.Log == nil {
+		options.Log = logrus.New().WithField("function", "local mq")
+	}
+
+	return &localMQ{
+		opts:   options,
+		store:  store,
+		topics: map[string]*topic{},
+		subs:   map[*subscriber]struct{}{},
+	}
+}
+
+type localMQ struct {
+	opts  mq.Options
+	store *diskStore
+
+	// mutex protects all the states below, including the topics, the groups and the subscribers.
+	mutex     sync.Mutex
+	connected bool
+	topics    map[string]*topic
+	subs      map[*subscriber]struct{}
+}
+
+func (m *localMQ) Init(opts ...mq.Option) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.connected {
+		return errors.New("mq is connected and can't do init")
+	}
+
+	for _, o := range opts {
+		o(&m.opts)
+	}
+
+	if m.opts.Context == nil {
+		m.opts.Context = context.Background()
+	}
+
+	if m.opts.Codec == nil {
+		m.opts.Codec = mq.JsonCodec{}
+	}
+
+	return nil
+}
+
+func (m *localMQ) Options() mq.Options {
+	return m.opts
+}
+
+func (m *localMQ) Address() string {
+	if m.store != nil {
+		return m.store.dir
+	}
+
+	return ""
+}
+
+func (m *localMQ) Connect() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.connected {
+		return nil
+	}
+
+	if m.store != nil {
+		topics, err := m.store.load()
+		if err != nil {
+			return err
+		}
+
+		m.topics = topics
+	}
+
+	m.connected = true
+
+	if m.store == nil {
+		return nil
+	}
+
+	// the files are rewritten without the messages committed before, because they
+	// have been loaded anyway.
+	for _, t := range m.topics {
+		for p := range t.partitions {
+			if err := m.compact(t, p, true); err != nil {
+				m.connected = false
+				m.topics = map[string]*topic{}
+				_ = m.store.close()
+
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Disconnect stops all the subscribers and closes the files of disk.
+func (m *localMQ) Disconnect() error {
+	m.mutex.Lock()
+
+	if !m.connected {
+		m.mutex.Unlock()
+
+		return nil
+	}
+
+	subs := make([]*subscriber, 0, len(m.subs))
+	for s := range m.subs {
+		subs = append(subs, s)
+	}
+
+	m.mutex.Unlock()
+
+	// the subscribers may ack the messages being handled before they exit.
+	for _, s := range subs {
+		_ = s.Unsubscribe()
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.connected = false
+
+	if m.store == nil {
+		return nil
+	}
+
+	m.topics = map[string]*topic{}
+
+	return m.store.close()
+}
+
+// Publish appends the message to the partition of topic which its key is hashed to,
+// or to the partitions in turn if it has no key.
+func (m *localMQ) Publish(topicName string, msg *mq.Message, opts ...mq.PublishOption) error {
+	d, err := m.opts.Codec.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if !m.connected {
+		return errNotConnected
+	}
+
+	t, err := m.getTopic(topicName)
+	if err != nil {
+		return err
+	}
+
+	p := t.partitionOf(msg.MessageKey())
+	r := record{Key: msg.MessageKey(), Value: d, Time: time.Now(), Offset: t.end(p)}
+
+	if m.store != nil {
+		if err := m.store.append(t.name, p, &r); err != nil {
+			return err
+		}
+	}
+
+	t.partitions[p] = append(t.partitions[p], r)
+	t.wakeup()
+
+	return nil
+}
+
+// Subscribe joins the queue of topic, and each subscription has its own queue if it is not set.
+func (m *localMQ) Subscribe(topicName string, h mq.Handler, opts ...mq.SubscribeOption) (mq.Subscriber, error) {
+	opt := mq.NewSubscribeOptions(opts...)
+	if opt.Context == nil {
+		opt.Context = context.Background()
+	}
+
+	anonymous := opt.Queue == ""
+	if anonymous {
+		opt.Queue = uuid.New().String()
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if !m.connected {
+		return nil, errNotConnected
+	}
+
+	t, err := m.getTopic(topicName)
+	if err != nil {
+		return nil, err
+	}
+
+	g, ok := t.groups[opt.Queue]
+	if !ok {
+		g = t.newGroup(opt.Queue, anonymous)
+	}
+
+	s := newSubscriber(m, t, g, h, opt)
+
+	g.members = append(g.members, s)
+	t.rebalance(g)
+	m.subs[s] = struct{}{}
+
+	go s.run()
+
+	return s, nil
+}
+
+func (m *localMQ) String() string {
+	if m.store != nil {
+		return "disk"
+	}
+
+	return "memory"
+}
+
+// getTopic returns the topic, and creates it if it does not exist.
+func (m *localMQ) getTopic(name string) (*topic, error) {
+	if t, ok := m.topics[name]; ok {
+		return t, nil
+	}
+
+	n, _ := m.opts.Context.Value(partitionsKey{}).(int)
+	if n <= 0 {
+		n = defaultPartitions
+	}
+
+	if m.store != nil {
+		if err := m.store.createTopic(name, n); err != nil {
+			return nil, err
+		}
+	}
+
+	t := newTopic(name, n)
+	m.topics[name] = t
+
+	return t, nil
+}
+
+// ack acks the message at offset of partition for the group, and commits the offset
+// after the messages acked in a row from the committed one.
+func (m *localMQ) ack(t *topic, g *group, partition int, offset int64) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	committed := g.committed[partition]
+	if offset < committed {
+		return nil
+	}
+
+	acked := g.acked[partition]
+	acked[offset] = true
+
+	for acked[committed] {
+		delete(acked, committed)
+		committed++
+	}
+
+	if committed == g.committed[partition] {
+		return nil
+	}
+
+	g.committed[partition] = committed
+
+	if m.store != nil && !g.anonymous && m.connected {
+		if err := m.store.saveOffsets(m.topics); err != nil {
+			return err
+		}
+	}
+
+	return m.compact(t, partition, false)
+}
+
+// compact drops the messages of partition which all the groups have consumed, so that
+// they don't grow without bound. A new group starts from the newest messages, so all
+// the messages are dropped if there is no group. The file of partition is rewritten
+// when enough messages are dropped, or at once if force is true.
+func (m *localMQ) compact(t *topic, partition int, force bool) error {
+	// the messages acked after the committed offset are delivered again after the
+	// reassignment, so the cursors of subscribers may be before the committed offset.
+	min := t.end(partition)
+	for _, g := range t.groups {
+		if v := g.committed[partition]; v < min {
+			min = v
+		}
+
+		for _, s := range g.members {
+			if v, ok := s.cursors[partition]; ok && v < min {
+				min = v
+			}
+		}
+	}
+
+	n := int(min - t.base[partition])
+	if n > 0 {
+		records := t.partitions[partition]
+
+		// release the values of messages dropped, because the array is kept by the slice.
+		for i := range records[:n] {
+			records[i] = record{}
+		}
+
+		t.partitions[partition] = records[n:]
+		t.base[partition] = min
+	}
+
+	if m.store == nil || !m.connected {
+		return nil
+	}
+
+	return m.store.compact(t.name, partition, t.base[partition], t.partitions[partition], force)
+}
+
+// leave removes the subscriber from its group, and the partitions are assigned to the rest.
+func (m *localMQ) leave(s *subscriber) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.subs, s)
+
+	g := s.g
+	for i, v := range g.members {
+		if v == s {
+			g.members = append(g.members[:i], g.members[i+1:]...)
+
+			break
+		}
+	}
+
+	if len(g.members) == 0 && g.anonymous {
+		delete(s.t.groups, g.name)
+
+		// the messages kept for the group are not needed any more.
+		for p := range s.t.partitions {
+			if err := m.compact(s.t, p, false); err != nil {
+				m.opts.Log.WithError(err).Error("compact the partition")
+			}
+		}
+
+		return
+	}
+
+	s.t.rebalance(g)
+}
+
+type record struct {
+	Key   string    `json:"key,omitempty"`
+	Value []byte    `json:"value"`
+	Time  time.Time `json:"time"`
+
+	// Offset is the offset of message in the partition.
+	Offset int64 `json:"offset"`
+}
+
+type topic struct {
+	name       string
+	partitions [][]record
+	groups     map[string]*group
+
+	// base is the offset of the first message kept of each partition.
+	base []int64
+
+	// next is the partition to append the message without key.
+	next int
+
+	// notify is closed to wake up the subscribers when there are new messages or the partitions are reassigned.
+	notify chan struct{}
+}
+
+func newTopic(name string, partitions int) *topic {
+	return &topic{
+		name:       name,
+		partitions: make([][]record, partitions),
+		groups:     map[string]*group{},
+		base:       make([]int64, partitions),
+		notify:     make(chan struct{}),
+	}
+}
+
+func (t *topic) partitionOf(key string) int {
+	n := len(t.partitions)
+
+	if key == "" {
+		p := t.next
+		t.next = (t.next + 1) % n
+
+		return p
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+
+	return int(h.Sum32() % uint32(n))
+}
+
+// end returns the offset of the next message to append to the partition.
+func (t *topic) end(partition int) int64 {
+	return t.base[partition] + int64(len(t.partitions[partition]))
+}
+
+// get returns the message at offset of partition, which must be kept.
+func (t *topic) get(partition int, offset int64) record {
+	return t.partitions[partition][offset-t.base[partition]]
+}
+
+func (t *topic) wakeup() {
+	close(t.notify)
+	t.notify = make(chan struct{})
+}
+
+// newGroup creates the group which starts from the newest messages.
+func (t *topic) newGroup(name string, anonymous bool) *group {
+	g := &group{
+		name:      name,
+		anonymous: anonymous,
+		committed: make([]int64, len(t.partitions)),
+		acked:     make([]map[int64]bool, len(t.partitions)),
+	}
+
+	for i := range t.partitions {
+		g.committed[i] = t.end(i)
+		g.acked[i] = map[int64]bool{}
+	}
+
+	t.groups[name] = g
+
+	return g
+}
+
+// rebalance assigns the partitions to the members of group in turn, and each member
+// consumes its partitions from the committed offsets.
+func (t *topic) rebalance(g *group) {
+	n := len(g.members)
+
+	for i, s := range g.members {
+		cursors := map[int]int64{}
+		for p := i; p < len(t.partitions); p += n {
+			cursors[p] = g.committed[p]
+		}
+
+		s.cursors = cursors
+	}
+
+	t.wakeup()
+}
+
+// group is the consumer group of the subscribers with the same queue.
+type group struct {
+	name      string
+	anonymous bool
+	members   []*subscriber
+
+	// committed is the next offset to consume of each partition.
+	committed []int64
+
+	// acked is the offsets acked after the committed one of each partition.
+	acked []map[int64]bool
+}
@@ -0,0 +1,445 @@
+package localmq
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opensourceways/community-robot-lib/mq"
+)
+
+// collector records the bodies of messages received by each subscriber.
+type collector struct {
+	mut  sync.Mutex
+	msgs map[string][]string
+	n    int
+	done chan struct{}
+}
+
+func newCollector(n int) *collector {
+	return &collector{msgs: map[string][]string{}, n: n, done: make(chan struct{})}
+}
+
+func (c *collector) handler(name string) mq.Handler {
+	return func(e mq.Event) error {
+		c.mut.Lock()
+		defer c.mut.Unlock()
+
+		c.msgs[name] = append(c.msgs[name], string(e.Message().Body))
+
+		if c.n--; c.n == 0 {
+			close(c.done)
+		}
+
+		return nil
+	}
+}
+
+func (c *collector) wait(t *testing.T) {
+	select {
+	case <-c.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout to wait for the messages")
+	}
+}
+
+func publish(t *testing.T, m mq.MQ, topic, key string, bodies ...string) {
+	for _, b := range bodies {
+		msg := &mq.Message{Body: []byte(b)}
+		msg.SetMessageKey(key)
+
+		if err := m.Publish(topic, msg); err != nil {
+			t.Fatalf("Unexpected publish error: %v", err)
+		}
+	}
+}
+
+func TestBroker(t *testing.T) {
+	m := NewMemoryMQ()
+	if err := m.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Disconnect()
+
+	c := newCollector(4)
+
+	// each subscription without queue gets all the messages.
+	for _, name := range []string{"a", "b"} {
+		if _, err := m.Subscribe("test", c.handler(name)); err != nil {
+			t.Fatalf("Unexpected subscribe error: %v", err)
+		}
+	}
+
+	publish(t, m, "test", "", "1", "2")
+	c.wait(t)
+
+	for _, name := range []string{"a", "b"} {
+		if v := fmt.Sprint(c.msgs[name]); v != "[1 2]" {
+			t.Errorf("subscriber %s got %s", name, v)
+		}
+	}
+}
+
+func TestQueueWithKey(t *testing.T) {
+	m := NewMemoryMQ(Partitions(4))
+	if err := m.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Disconnect()
+
+	c := newCollector(20)
+
+	for _, name := range []string{"a", "b"} {
+		if _, err := m.Subscribe("test", c.handler(name), mq.Queue("group")); err != nil {
+			t.Fatalf("Unexpected subscribe error: %v", err)
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		publish(t, m, "test", "x", fmt.Sprintf("x%d", i))
+		publish(t, m, "test", "y", fmt.Sprintf("y%d", i))
+	}
+
+	c.wait(t)
+
+	// the messages of the same key are consumed in order by the same subscriber.
+	for _, key := range []string{"x", "y"} {
+		found := 0
+
+		for _, msgs := range c.msgs {
+			next := 0
+
+			for _, v := range msgs {
+				if v[:1] != key {
+					continue
+				}
+
+				if v != fmt.Sprintf("%s%d", key, next) {
+					t.Fatalf("message %s is out of order: %v", v, msgs)
+				}
+
+				next++
+			}
+
+			if next > 0 {
+				found++
+			}
+		}
+
+		if found != 1 {
+			t.Errorf("the messages of key %s are consumed by %d subscribers", key, found)
+		}
+	}
+}
+
+func TestRedeliverUnacked(t *testing.T) {
+	m := NewMemoryMQ()
+	if err := m.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Disconnect()
+
+	c := newCollector(3)
+	h := c.handler("a")
+
+	s, err := m.Subscribe("test", func(e mq.Event) error {
+		// ack the first message only.
+		if string(e.Message().Body) == "1" {
+			if err := e.Ack(); err != nil {
+				t.Error(err)
+			}
+		}
+
+		return h(e)
+	}, mq.Queue("group"), mq.DisableAutoAck())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publish(t, m, "test", "", "1", "2", "3")
+	c.wait(t)
+
+	_ = s.Unsubscribe()
+
+	c = newCollector(2)
+	if _, err := m.Subscribe("test", c.handler("b"), mq.Queue("group")); err != nil {
+		t.Fatal(err)
+	}
+
+	c.wait(t)
+
+	if v := fmt.Sprint(c.msgs["b"]); v != "[2 3]" {
+		t.Errorf("the messages redelivered are %s", v)
+	}
+}
+
+func TestDiskMQ(t *testing.T) {
+	dir := t.TempDir()
+
+	m := NewDiskMQ(dir, Partitions(2))
+	if err := m.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	c := newCollector(2)
+	if _, err := m.Subscribe("test", c.handler("a"), mq.Queue("group")); err != nil {
+		t.Fatal(err)
+	}
+
+	publish(t, m, "test", "k", "1", "2")
+	c.wait(t)
+
+	if err := m.Disconnect(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the messages published when the robot is down are consumed after restart.
+	m = NewDiskMQ(dir)
+	if err := m.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	publish(t, m, "test", "k", "3")
+	if err := m.Disconnect(); err != nil {
+		t.Fatal(err)
+	}
+
+	m = NewDiskMQ(dir)
+	if err := m.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Disconnect()
+
+	c = newCollector(1)
+	if _, err := m.Subscribe("test", c.handler("b"), mq.Queue("group")); err != nil {
+		t.Fatal(err)
+	}
+
+	c.wait(t)
+
+	if v := fmt.Sprint(c.msgs["b"]); v != "[3]" {
+		t.Errorf("the messages consumed after restart are %s", v)
+	}
+}
+
+func TestDeadLetter(t *testing.T) {
+	m := NewMemoryMQ()
+	if err := m.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Disconnect()
+
+	c := newCollector(1)
+	if _, err := m.Subscribe("dead", func(e mq.Event) error {
+		h := e.Message().Header
+		if h[mq.HeaderRetryCount] != "2" || h[mq.HeaderOriginalTopic] != "test" || h[mq.HeaderError] == "" {
+			t.Errorf("header of dead letter = %v", h)
+		}
+
+		return c.handler("dead")(e)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	if _, err := m.Subscribe("test", func(e mq.Event) error {
+		if string(e.Message().Body) == "bad" {
+			calls++
+
+			return fmt.Errorf("failed")
+		}
+
+		return nil
+	}, mq.Queue("group"), mq.Retry(2, time.Millisecond, 0), mq.DeadLetterTopic("dead")); err != nil {
+		t.Fatal(err)
+	}
+
+	publish(t, m, "test", "", "bad")
+	c.wait(t)
+
+	if calls != 3 || fmt.Sprint(c.msgs["dead"]) != "[bad]" {
+		t.Errorf("calls = %d, dead letters = %v", calls, c.msgs["dead"])
+	}
+}
+
+func TestHoldFailed(t *testing.T) {
+	m := NewMemoryMQ()
+	if err := m.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Disconnect()
+
+	c := newCollector(2)
+	h := c.handler("a")
+
+	s, err := m.Subscribe("test", func(e mq.Event) error {
+		_ = h(e)
+
+		if string(e.Message().Body) == "bad" {
+			return fmt.Errorf("failed")
+		}
+
+		return nil
+	}, mq.Queue("group"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publish(t, m, "test", "", "bad", "good")
+	c.wait(t)
+
+	_ = s.Unsubscribe()
+
+	// the message after the failed one is delivered again though it is acked.
+	c = newCollector(2)
+	if _, err := m.Subscribe("test", c.handler("b"), mq.Queue("group")); err != nil {
+		t.Fatal(err)
+	}
+
+	c.wait(t)
+
+	if v := fmt.Sprint(c.msgs["b"]); v != "[bad good]" {
+		t.Errorf("the messages redelivered are %s", v)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	m := NewMemoryMQ()
+	if err := m.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Disconnect()
+
+	// the group without subscribers keeps the messages after its committed offset.
+	idle, err := m.Subscribe("test", func(mq.Event) error { return nil }, mq.Queue("idle"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = idle.Unsubscribe()
+
+	c := newCollector(3)
+	s, err := m.Subscribe("test", c.handler("a"), mq.Queue("group"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publish(t, m, "test", "", "1", "2", "3")
+	c.wait(t)
+	_ = s.Unsubscribe()
+
+	lm := m.(*localMQ)
+	kept := func() (int, int64) {
+		lm.mutex.Lock()
+		defer lm.mutex.Unlock()
+
+		tp := lm.topics["test"]
+
+		return len(tp.partitions[0]), tp.base[0]
+	}
+
+	if n, base := kept(); n != 3 || base != 0 {
+		t.Errorf("kept %d messages from offset %d, but the idle group has not consumed them", n, base)
+	}
+
+	// the messages are dropped after all the groups consume them.
+	c = newCollector(3)
+	if _, err := m.Subscribe("test", c.handler("b"), mq.Queue("idle")); err != nil {
+		t.Fatal(err)
+	}
+	c.wait(t)
+
+	for i := 0; i < 50; i++ {
+		if n, _ := kept(); n == 0 {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if n, base := kept(); n != 0 || base != 3 {
+		t.Errorf("kept %d messages from offset %d after they are consumed", n, base)
+	}
+
+	c = newCollector(1)
+	if _, err := m.Subscribe("test", c.handler("c"), mq.Queue("group")); err != nil {
+		t.Fatal(err)
+	}
+
+	publish(t, m, "test", "", "4")
+	c.wait(t)
+
+	if v := fmt.Sprint(c.msgs["c"]); v != "[4]" {
+		t.Errorf("the messages consumed after compaction are %s", v)
+	}
+}
+
+func TestDiskCompact(t *testing.T) {
+	dir := t.TempDir()
+
+	m := NewDiskMQ(dir)
+	if err := m.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	c := newCollector(3)
+	if _, err := m.Subscribe("test", c.handler("a"), mq.Queue("group")); err != nil {
+		t.Fatal(err)
+	}
+
+	publish(t, m, "test", "", "1", "2", "3")
+	c.wait(t)
+
+	if err := m.Disconnect(); err != nil {
+		t.Fatal(err)
+	}
+
+	file := (&diskStore{dir: dir}).partitionFile("test", 0)
+
+	size := func() int64 {
+		info, err := os.Stat(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return info.Size()
+	}
+
+	if size() == 0 {
+		t.Fatal("the file should not be rewritten for a few messages")
+	}
+
+	// the file is rewritten without the messages consumed on connecting.
+	m = NewDiskMQ(dir)
+	if err := m.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := size(); n != 0 {
+		t.Errorf("the size of file compacted is %d", n)
+	}
+
+	publish(t, m, "test", "", "4")
+	if err := m.Disconnect(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the offsets go on after the file is rewritten.
+	m = NewDiskMQ(dir)
+	if err := m.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Disconnect()
+
+	c = newCollector(1)
+	if _, err := m.Subscribe("test", c.handler("b"), mq.Queue("group")); err != nil {
+		t.Fatal(err)
+	}
+
+	c.wait(t)
+
+	if v := fmt.Sprint(c.msgs["b"]); v != "[4]" {
+		t.Errorf("the messages consumed after compaction are %s", v)
+	}
+}
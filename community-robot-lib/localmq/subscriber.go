@@ -0,0 +1,193 @@
+package localmq
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/opensourceways/community-robot-lib/mq"
+)
+
+type subscriber struct {
+	m       *localMQ
+	t       *topic
+	g       *group
+	handler mq.Handler
+	opts    mq.SubscribeOptions
+
+	// cursors maps the partitions assigned to the next offsets to deliver.
+	// It is protected by the mutex of mq.
+	cursors map[int]int64
+	// last is the partition delivered last time, so that the partitions are consumed in turn.
+	last int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	once   sync.Once
+	done   chan struct{}
+}
+
+func newSubscriber(m *localMQ, t *topic, g *group, h mq.Handler, opts mq.SubscribeOptions) *subscriber {
+	if h == nil {
+		h = func(mq.Event) error {
+			return nil
+		}
+	}
+
+	ctx, cancel := context.WithCancel(opts.Context)
+
+	return &subscriber{
+		m:       m,
+		t:       t,
+		g:       g,
+		handler: h,
+		opts:    opts,
+		last:    -1,
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+}
+
+func (s *subscriber) Options() mq.SubscribeOptions {
+	return s.opts
+}
+
+func (s *subscriber) Topic() string {
+	return s.t.name
+}
+
+// Unsubscribe waits for the message being handled, and then leaves the group.
+func (s *subscriber) Unsubscribe() error {
+	s.once.Do(func() {
+		s.cancel()
+
+		<-s.done
+
+		s.m.leave(s)
+	})
+
+	return nil
+}
+
+func (s *subscriber) run() {
+	defer close(s.done)
+
+	for {
+		e, notify := s.next()
+		if e == nil {
+			select {
+			case <-notify:
+				continue
+			case <-s.ctx.Done():
+				return
+			}
+		}
+
+		s.handle(e)
+
+		if s.ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// next returns the next message of the partitions assigned, or the channel
+// to wait on if there is no message.
+func (s *subscriber) next() (*event, chan struct{}) {
+	s.m.mutex.Lock()
+	defer s.m.mutex.Unlock()
+
+	partitions := make([]int, 0, len(s.cursors))
+	for p := range s.cursors {
+		partitions = append(partitions, p)
+	}
+
+	sort.Ints(partitions)
+
+	// start from the one after the last partition delivered.
+	i := sort.SearchInts(partitions, s.last+1)
+
+	for j := range partitions {
+		p := partitions[(i+j)%len(partitions)]
+
+		offset := s.cursors[p]
+		if offset >= s.t.end(p) {
+			continue
+		}
+
+		s.cursors[p] = offset + 1
+		s.last = p
+
+		return &event{
+			s:         s,
+			r:         s.t.get(p, offset),
+			partition: p,
+			offset:    offset,
+		}, nil
+	}
+
+	return nil, s.t.notify
+}
+
+func (s *subscriber) handle(e *event) {
+	opts := &s.m.opts
+	log := opts.Log
+
+	eh := opts.ErrorHandler
+	if eh == nil {
+		eh = func(e mq.Event) error {
+			log.Error(e.Error())
+
+			return nil
+		}
+	}
+
+	e.m = new(mq.Message)
+
+	if err := opts.Codec.Unmarshal(e.r.Value, e.m); err != nil {
+		e.err = fmt.Errorf("unmarshal msg failed, err: %v", err)
+		e.m.Body = e.r.Value
+	} else {
+		e.m.SetMessageKey(e.r.Key)
+
+		if err := s.opts.Retry.Handle(s.ctx, e, s.handler); err != nil {
+			// it is delivered again to the subscriber which the partition is assigned to.
+			if s.ctx.Err() != nil {
+				return
+			}
+
+			e.err = fmt.Errorf("handle event, err: %v", err)
+		}
+	}
+
+	if e.err == nil {
+		if s.opts.AutoAck {
+			s.ack(e)
+		}
+
+		return
+	}
+
+	if err := eh(e); err != nil {
+		log.Error(err)
+	}
+
+	// the message failed is held if there is no dead letter topic.
+	if topic := s.opts.DeadLetterTopic; topic != "" {
+		if err := s.m.Publish(topic, mq.NewDeadLetter(s.t.name, e.m, e.err)); err != nil {
+			log.Errorf("publish to dead letter topic:%s, err:%s", topic, err.Error())
+
+			return
+		}
+
+		s.ack(e)
+	}
+}
+
+func (s *subscriber) ack(e *event) {
+	if err := e.Ack(); err != nil {
+		s.m.opts.Log.Errorf("ack message, err: %v", err)
+	}
+}
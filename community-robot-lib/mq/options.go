@@ -3,6 +3,7 @@ package mq
 import (
 	"context"
 	"crypto/tls"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -107,6 +108,14 @@ type SubscribeOptions struct {
 	// receives a subset of messages.
 	Queue string
 
+	// Retry is the policy to retry handling the message which fails.
+	Retry RetryPolicy
+
+	// DeadLetterTopic is the topic which the message is published to when
+	// it still fails after the retries or it can't be decoded. If it is not
+	// set, the message failed is not acked, and the offset is held there.
+	DeadLetterTopic string
+
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
@@ -141,6 +150,25 @@ func Queue(name string) SubscribeOption {
 	}
 }
 
+// Retry retries handling the message which fails at most maxRetries times. The delay
+// before the first retry is backoff, and it doubles for each retry up to maxBackoff.
+func Retry(maxRetries int, backoff, maxBackoff time.Duration) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.Retry = RetryPolicy{
+			MaxRetries: maxRetries,
+			Backoff:    backoff,
+			MaxBackoff: maxBackoff,
+		}
+	}
+}
+
+// DeadLetterTopic sets the topic to publish the message which keeps failing.
+func DeadLetterTopic(topic string) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.DeadLetterTopic = topic
+	}
+}
+
 // SubscribeContext set context
 func SubscribeContext(ctx context.Context) SubscribeOption {
 	return func(o *SubscribeOptions) {
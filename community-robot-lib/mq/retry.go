@@ -0,0 +1,98 @@
+package mq
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+const (
+	// HeaderRetryCount is the times which the message has been retried.
+	HeaderRetryCount = "X-MQ-Retry-Count"
+
+	// HeaderError is the error of the last attempt to handle the dead letter.
+	HeaderError = "X-MQ-Error"
+
+	// HeaderOriginalTopic is the topic which the dead letter comes from.
+	HeaderOriginalTopic = "X-MQ-Original-Topic"
+)
+
+// RetryPolicy is the policy to retry handling the message which fails.
+type RetryPolicy struct {
+	// MaxRetries is the times to retry after the first failure. It does not retry if not positive.
+	MaxRetries int
+
+	// Backoff is the delay before the first retry, and it doubles for each retry after.
+	Backoff time.Duration
+
+	// MaxBackoff limits the delay, and the delay is not limited if it is not positive.
+	MaxBackoff time.Duration
+}
+
+func (p *RetryPolicy) delay(n int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < n; i++ {
+		d *= 2
+
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			break
+		}
+	}
+
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+
+	return d
+}
+
+// Handle handles e by h, and retries until it succeeds, the retries run out or ctx is done.
+// The retry count is set in the header of message before each retry. It returns the last error.
+func (p *RetryPolicy) Handle(ctx context.Context, e Event, h Handler) error {
+	err := h(e)
+
+	for n := 1; err != nil && n <= p.MaxRetries; n++ {
+		t := time.NewTimer(p.delay(n))
+
+		select {
+		case <-ctx.Done():
+			t.Stop()
+
+			return err
+
+		case <-t.C:
+		}
+
+		m := e.Message()
+		if m.Header == nil {
+			m.Header = map[string]string{}
+		}
+
+		m.Header[HeaderRetryCount] = strconv.Itoa(n)
+
+		err = h(e)
+	}
+
+	return err
+}
+
+// NewDeadLetter returns the message to publish to the dead letter topic for the message
+// of topic which fails by err. It carries the retry count and the error in the header.
+func NewDeadLetter(topic string, m *Message, err error) *Message {
+	h := make(map[string]string, len(m.Header)+3)
+	for k, v := range m.Header {
+		h[k] = v
+	}
+
+	if _, ok := h[HeaderRetryCount]; !ok {
+		h[HeaderRetryCount] = "0"
+	}
+
+	h[HeaderError] = err.Error()
+	h[HeaderOriginalTopic] = topic
+
+	d := &Message{Header: h, Body: m.Body}
+	d.SetMessageKey(m.MessageKey())
+
+	return d
+}
@@ -0,0 +1,78 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type testEvent struct {
+	m *Message
+}
+
+func (e *testEvent) Topic() string                 { return "test" }
+func (e *testEvent) Message() *Message             { return e.m }
+func (e *testEvent) Ack() error                    { return nil }
+func (e *testEvent) Error() error                  { return nil }
+func (e *testEvent) Extra() map[string]interface{} { return nil }
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, v := range want {
+		if d := p.delay(i + 1); d != v {
+			t.Errorf("delay of retry %d = %v, want %v", i+1, d, v)
+		}
+	}
+}
+
+func TestRetryPolicyHandle(t *testing.T) {
+	p := RetryPolicy{MaxRetries: 3, Backoff: time.Millisecond}
+	e := &testEvent{m: &Message{}}
+
+	var counts []string
+	err := p.Handle(context.Background(), e, func(e Event) error {
+		counts = append(counts, e.Message().Header[HeaderRetryCount])
+
+		if len(counts) < 3 {
+			return errors.New("failed")
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(counts) != 3 || counts[0] != "" || counts[1] != "1" || counts[2] != "2" {
+		t.Errorf("retry counts = %q", counts)
+	}
+
+	calls := 0
+	err = p.Handle(context.Background(), e, func(Event) error {
+		calls++
+
+		return errors.New("failed")
+	})
+	if err == nil || calls != 4 {
+		t.Errorf("err = %v, calls = %d", err, calls)
+	}
+}
+
+func TestNewDeadLetter(t *testing.T) {
+	m := &Message{Header: map[string]string{HeaderRetryCount: "3", "k": "v"}, Body: []byte("body")}
+	m.SetMessageKey("key")
+
+	d := NewDeadLetter("events", m, errors.New("failed"))
+
+	if d.Header[HeaderRetryCount] != "3" || d.Header[HeaderError] != "failed" ||
+		d.Header[HeaderOriginalTopic] != "events" || d.Header["k"] != "v" {
+		t.Errorf("header = %v", d.Header)
+	}
+
+	if d.MessageKey() != "key" || string(d.Body) != "body" {
+		t.Errorf("dead letter = %v", d)
+	}
+}
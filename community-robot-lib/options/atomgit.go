@@ -2,6 +2,7 @@ package options
 
 import (
 	"flag"
+	"fmt"
 )
 
 // AtomGitOptions holds options for interacting with AtomGit.
@@ -36,9 +37,15 @@ func (o *AtomGitOptions) addFlags(defaultAtomGitTokenPath string, fs *flag.FlagS
 		defaultAtomGitTokenPath,
 		"Path to the file containing the AtomGit OAuth secret.",
 	)
+	fs.StringVar(&o.RepoCacheDir, "repo-cache-dir", "", "Path to which clone repo.")
+	fs.BoolVar(&o.CacheRepoOnPV, "cache-repo-on-pv", false, "Specify whether to cache repo on persistent volume.")
 }
 
 // Validate validates AtomGit options.
 func (o AtomGitOptions) Validate() error {
+	if o.CacheRepoOnPV && o.RepoCacheDir == "" {
+		return fmt.Errorf("must set repo-cache-dir if caching repo on persistent volume")
+	}
+
 	return nil
 }
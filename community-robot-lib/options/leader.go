@@ -0,0 +1,89 @@
+package options
+
+import (
+	"flag"
+	"fmt"
+	"time"
+)
+
+const (
+	LeaseBackendFile       = "file"
+	LeaseBackendKubernetes = "kubernetes"
+)
+
+// LeaderElectionOptions holds options for electing the leader among the replicas of robot.
+type LeaderElectionOptions struct {
+	// Enabled means only the leader runs the periodic jobs.
+	Enabled bool
+
+	// HandleEvents means only the leader handles the events too. The follower rejects
+	// the webhooks with 503 so that they are retried, and skips the events of message
+	// queue, which requires each replica to consume all the events without a group.
+	HandleEvents bool
+
+	// Identity is the identity of replica, which is hostname_pid if it is empty.
+	Identity string
+
+	// Backend is where the lease is kept, file or kubernetes.
+	Backend string
+
+	// LeaseFile is the path of lease file shared by the replicas, such as on the same volume.
+	LeaseFile string
+
+	// LeaseName is the name of Lease object of kubernetes.
+	LeaseName string
+
+	// LeaseNamespace is the namespace of Lease object, which is the one of pod if it is empty.
+	LeaseNamespace string
+
+	// LeaseDuration is the time the others wait for before taking the lease not renewed.
+	LeaseDuration time.Duration
+
+	// RetryPeriod is the interval of renewing or trying to acquire the lease.
+	RetryPeriod time.Duration
+}
+
+// AddFlags injects leader election options into the given FlagSet.
+func (o *LeaderElectionOptions) AddFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&o.Enabled, "leader-elect", false, "Elect the leader among the replicas, and only the leader runs the periodic jobs.")
+	fs.BoolVar(&o.HandleEvents, "leader-handle-events", false, "Only the leader handles the events. The others reject the webhooks with 503, and skip the events of message queue which must be consumed without mq-group.")
+	fs.StringVar(&o.Identity, "leader-identity", "", "The identity of replica in the election, it is hostname_pid by default.")
+	fs.StringVar(&o.Backend, "leader-lease-backend", LeaseBackendFile, "Where the lease is kept, file or kubernetes.")
+	fs.StringVar(&o.LeaseFile, "leader-lease-file", "", "Path to the lease file shared by the replicas.")
+	fs.StringVar(&o.LeaseName, "leader-lease-name", "", "The name of Lease object of kubernetes.")
+	fs.StringVar(&o.LeaseNamespace, "leader-lease-namespace", "", "The namespace of Lease object, it is the one of pod by default.")
+	fs.DurationVar(&o.LeaseDuration, "leader-lease-duration", 15*time.Second, "The time the others wait for before taking the lease not renewed.")
+	fs.DurationVar(&o.RetryPeriod, "leader-retry-period", 2*time.Second, "The interval of renewing or trying to acquire the lease.")
+}
+
+// Validate validates leader election options.
+func (o *LeaderElectionOptions) Validate() error {
+	if !o.Enabled {
+		if o.HandleEvents {
+			return fmt.Errorf("must set leader-elect if leader-handle-events is set")
+		}
+
+		return nil
+	}
+
+	switch o.Backend {
+	case LeaseBackendFile:
+		if o.LeaseFile == "" {
+			return fmt.Errorf("must set leader-lease-file if leader-lease-backend is file")
+		}
+
+	case LeaseBackendKubernetes:
+		if o.LeaseName == "" {
+			return fmt.Errorf("must set leader-lease-name if leader-lease-backend is kubernetes")
+		}
+
+	default:
+		return fmt.Errorf("unknown leader-lease-backend: %s", o.Backend)
+	}
+
+	if o.RetryPeriod <= 0 || o.LeaseDuration <= o.RetryPeriod {
+		return fmt.Errorf("leader-lease-duration must be greater than leader-retry-period")
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package options
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MQOptions holds options for transporting the events through a topic of message queue,
+// from the access robot to the others.
+type MQOptions struct {
+	// Addresses is the comma separated addresses of the message queue.
+	Addresses string
+
+	// Topic is the topic of events. The message queue is not used if it is empty.
+	Topic string
+
+	// Group is the consumer group. The robots in the same group share the events.
+	Group string
+
+	// MaxRetries is the max times to retry the event which fails to be handled by the consumer.
+	MaxRetries int
+
+	// RetryBackoff is the delay before the first retry, and it doubles for each retry.
+	RetryBackoff time.Duration
+
+	// DeadLetterTopic is the topic to publish the event which keeps failing. If it is
+	// empty, the event failed is not acked, and the offset is held there.
+	DeadLetterTopic string
+}
+
+// AddFlags injects MQ options into the given FlagSet.
+func (o *MQOptions) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.Addresses, "mq-addresses", "", "Comma separated addresses of the message queue.")
+	fs.StringVar(&o.Topic, "mq-topic", "", "The topic of events in the message queue. The message queue is not used if it is empty.")
+	fs.StringVar(&o.Group, "mq-group", "", "The consumer group of events. The robots in the same group share the events.")
+	fs.IntVar(&o.MaxRetries, "mq-max-retries", 3, "The max times to retry the event which fails to be handled.")
+	fs.DurationVar(&o.RetryBackoff, "mq-retry-backoff", time.Second, "The delay before the first retry of event, it doubles for each retry.")
+	fs.StringVar(&o.DeadLetterTopic, "mq-dead-letter-topic", "", "The topic to publish the event which keeps failing. If empty, the event failed is not acked and the consumer stops at it.")
+}
+
+// Enabled reports whether the events are transported through the message queue.
+func (o *MQOptions) Enabled() bool {
+	return o.Topic != ""
+}
+
+// AddressList returns the addresses of the message queue.
+func (o *MQOptions) AddressList() []string {
+	var r []string
+	for _, v := range strings.Split(o.Addresses, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			r = append(r, v)
+		}
+	}
+
+	return r
+}
+
+// Validate validates MQ options.
+func (o *MQOptions) Validate() error {
+	if o.Enabled() && len(o.AddressList()) == 0 {
+		return fmt.Errorf("must set mq-addresses if mq-topic is set")
+	}
+
+	if o.MaxRetries < 0 {
+		return fmt.Errorf("mq-max-retries must not be negative")
+	}
+
+	return nil
+}
@@ -10,6 +10,110 @@ type ServiceOptions struct {
 	Port        int
 	ConfigFile  string
 	GracePeriod time.Duration
+
+	// RecordDir is the directory where the webhooks received are saved as fixtures.
+	// Recording is disabled when it is empty.
+	RecordDir string
+
+	// Censor removes the secrets from the webhooks before they are recorded.
+	Censor func([]byte) []byte
+
+	// DryRun means the calls which change AtomGit are recorded instead of executed.
+	DryRun bool
+
+	// ForwardSecretPath is the path of the secret file which the events forwarded by
+	// the access robot are signed with.
+	ForwardSecretPath string
+
+	// PreviousForwardSecretPath is the path of the previous secret file, which is still
+	// accepted when verifying the forwarded events during the rotation of secret.
+	PreviousForwardSecretPath string
+
+	// DedupWindow is the time window in which the same delivery of webhook is handled once.
+	// The deduplication is disabled if it is not positive.
+	DedupWindow time.Duration
+
+	// DedupSize is the max number of deliveries kept to deduplicate.
+	DedupSize int
+
+	// MaxPendingEvents is the number of events being handled at which the robot is not ready.
+	// It is not checked if it is not positive.
+	MaxPendingEvents int
+
+	// CheckAPI means the robot is not ready when the api of AtomGit is unreachable.
+	CheckAPI bool
+
+	// ForwardSecret returns the secret to sign the forwarded events, which is generally
+	// loaded from ForwardSecretPath by LoadSecrets. The events are not signed if it is nil.
+	ForwardSecret func() []byte
+
+	// ForwardSecrets returns the secrets to verify the forwarded events, which are generally
+	// loaded from the paths above by secret.Agent. The events are not verified if it is nil.
+	ForwardSecrets func() [][]byte
+
+	// AdminTokenPath is the path of the token file which authorizes the requests to
+	// the admin api. The admin api is disabled if it is empty.
+	AdminTokenPath string
+
+	// AdminToken returns the token of admin api, which is generally loaded from
+	// AdminTokenPath by secret.Agent. The admin api is disabled if it is nil.
+	AdminToken func() []byte
+
+	// Tracing is the options of exporting the traces of events.
+	Tracing TracingOptions
+
+	// LeaderElection is the options of electing the leader among the replicas.
+	LeaderElection LeaderElectionOptions
+}
+
+// ForwardSecretPaths returns the paths of forward secrets which are set.
+func (o *ServiceOptions) ForwardSecretPaths() []string {
+	var r []string
+
+	for _, v := range []string{o.ForwardSecretPath, o.PreviousForwardSecretPath} {
+		if v != "" {
+			r = append(r, v)
+		}
+	}
+
+	return r
+}
+
+// SecretPaths returns the paths of forward secrets and admin token which are set,
+// which should be loaded by secret.Agent.
+func (o *ServiceOptions) SecretPaths() []string {
+	r := o.ForwardSecretPaths()
+
+	if o.AdminTokenPath != "" {
+		r = append(r, o.AdminTokenPath)
+	}
+
+	return r
+}
+
+// SecretLoader is the methods of secret.Agent which LoadSecrets uses.
+type SecretLoader interface {
+	Censor(content []byte) []byte
+	GetTokenGenerator(path string) func() []byte
+	GetSecretsGenerator(paths ...string) func() [][]byte
+}
+
+// LoadSecrets sets the censor, the forward secrets and the admin token by the agent,
+// which should have been started with SecretPaths.
+func (o *ServiceOptions) LoadSecrets(agent SecretLoader) {
+	o.Censor = agent.Censor
+
+	if o.ForwardSecretPath != "" {
+		o.ForwardSecret = agent.GetTokenGenerator(o.ForwardSecretPath)
+	}
+
+	if paths := o.ForwardSecretPaths(); len(paths) > 0 {
+		o.ForwardSecrets = agent.GetSecretsGenerator(paths...)
+	}
+
+	if o.AdminTokenPath != "" {
+		o.AdminToken = agent.GetTokenGenerator(o.AdminTokenPath)
+	}
 }
 
 func (o *ServiceOptions) Validate() error {
@@ -17,11 +121,35 @@ func (o *ServiceOptions) Validate() error {
 		return fmt.Errorf("missing config-file")
 	}
 
-	return nil
+	if o.ForwardSecretPath == "" && o.PreviousForwardSecretPath != "" {
+		return fmt.Errorf("missing forward-secret-path")
+	}
+
+	if o.DedupWindow > 0 && o.DedupSize <= 0 {
+		return fmt.Errorf("dedup-size must be positive")
+	}
+
+	if err := o.Tracing.Validate(); err != nil {
+		return err
+	}
+
+	return o.LeaderElection.Validate()
 }
 
 func (o *ServiceOptions) AddFlags(fs *flag.FlagSet) {
 	fs.IntVar(&o.Port, "port", 8888, "Port to listen on.")
 	fs.StringVar(&o.ConfigFile, "config-file", "", "Path to config file.")
 	fs.DurationVar(&o.GracePeriod, "grace-period", 180*time.Second, "On shutdown, try to handle remaining events for the specified duration.")
+	fs.StringVar(&o.RecordDir, "record-dir", "", "Path to the directory where the sanitized webhooks are saved as fixtures.")
+	fs.BoolVar(&o.DryRun, "dry-run", false, "Record the calls which change AtomGit instead of executing them.")
+	fs.DurationVar(&o.DedupWindow, "dedup-window", 10*time.Minute, "The time window in which the same delivery of webhook is handled once, 0 disables it.")
+	fs.IntVar(&o.DedupSize, "dedup-size", 10000, "The max number of deliveries kept to deduplicate.")
+	fs.IntVar(&o.MaxPendingEvents, "max-pending-events", 0, "The number of events being handled at which the robot is not ready, 0 disables the check.")
+	fs.BoolVar(&o.CheckAPI, "readiness-check-api", false, "Check whether the api of AtomGit is reachable in the readiness probe.")
+	fs.StringVar(&o.ForwardSecretPath, "forward-secret-path", "", "Path to the secret file to sign and verify the events forwarded by the access robot.")
+	fs.StringVar(&o.PreviousForwardSecretPath, "previous-forward-secret-path", "", "Path to the previous forward secret file which is still accepted during the rotation.")
+	fs.StringVar(&o.AdminTokenPath, "admin-token-path", "", "Path to the token file which authorizes the requests to the admin api, the api is disabled if it is empty.")
+
+	o.Tracing.AddFlags(fs)
+	o.LeaderElection.AddFlags(fs)
 }
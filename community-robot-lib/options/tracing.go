@@ -0,0 +1,74 @@
+package options
+
+import (
+	"flag"
+	"fmt"
+)
+
+const (
+	TraceExporterOTLP = "otlp"
+	TraceExporterFile = "file"
+)
+
+// TracingOptions holds options for exporting the traces of events handled by robot.
+type TracingOptions struct {
+	// Exporter is where the spans are exported, otlp or file. Tracing is disabled if it is empty.
+	Exporter string
+
+	// OTLPEndpoint is the host:port of the OTLP/HTTP collector.
+	OTLPEndpoint string
+
+	// OTLPInsecure means the spans are sent to the collector over plain HTTP.
+	OTLPInsecure bool
+
+	// File is the path of file where the spans are written as JSON.
+	File string
+
+	// ServiceName is the name of robot in the spans. It is the name of program if it is empty.
+	ServiceName string
+
+	// SampleRatio is the ratio of the deliveries traced, the ones propagated keep the decision.
+	SampleRatio float64
+}
+
+// AddFlags injects tracing options into the given FlagSet.
+func (o *TracingOptions) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.Exporter, "trace-exporter", "", "Where the traces are exported, otlp or file. Tracing is disabled if it is empty.")
+	fs.StringVar(&o.OTLPEndpoint, "trace-otlp-endpoint", "localhost:4318", "The host:port of the OTLP/HTTP collector.")
+	fs.BoolVar(&o.OTLPInsecure, "trace-otlp-insecure", false, "Send the traces to the OTLP collector over plain HTTP.")
+	fs.StringVar(&o.File, "trace-file", "", "Path to the file where the traces are written.")
+	fs.StringVar(&o.ServiceName, "trace-service-name", "", "The name of robot in the traces, it is the name of program by default.")
+	fs.Float64Var(&o.SampleRatio, "trace-sample-ratio", 1, "The ratio of the deliveries traced.")
+}
+
+// Enabled reports whether the traces are exported.
+func (o *TracingOptions) Enabled() bool {
+	return o.Exporter != ""
+}
+
+// Validate validates tracing options.
+func (o *TracingOptions) Validate() error {
+	switch o.Exporter {
+	case "":
+		return nil
+
+	case TraceExporterOTLP:
+		if o.OTLPEndpoint == "" {
+			return fmt.Errorf("must set trace-otlp-endpoint if trace-exporter is otlp")
+		}
+
+	case TraceExporterFile:
+		if o.File == "" {
+			return fmt.Errorf("must set trace-file if trace-exporter is file")
+		}
+
+	default:
+		return fmt.Errorf("unknown trace-exporter: %s", o.Exporter)
+	}
+
+	if o.SampleRatio < 0 || o.SampleRatio > 1 {
+		return fmt.Errorf("trace-sample-ratio must be in [0, 1]")
+	}
+
+	return nil
+}
@@ -0,0 +1,217 @@
+// Package repocache maintains mirrored clones of AtomGit repositories on local disk,
+// so that robots can read files, trees and diffs without calling the api.
+package repocache
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	sdk "github.com/opensourceways/go-atomgit/atomgit"
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/community-robot-lib/config"
+	"github.com/opensourceways/community-robot-lib/options"
+)
+
+// RemoteFunc returns the url of repository to clone.
+type RemoteFunc func(org, repo string) string
+
+// DefaultRemote is the remote of repository on AtomGit.
+func DefaultRemote(org, repo string) string {
+	return fmt.Sprintf("https://atomgit.com/%s/%s.git", org, repo)
+}
+
+// Cache keeps a bare mirror for each repository under a directory.
+// It is safe for concurrent use.
+type Cache struct {
+	dir      string
+	tempDir  bool
+	remote   RemoteFunc
+	getToken func() []byte
+
+	mut   sync.Mutex
+	locks map[string]*sync.RWMutex
+}
+
+// NewCache creates the cache with the options of AtomGit. The mirrors are kept under
+// RepoCacheDir and reused after restarting if CacheRepoOnPV is true. Otherwise, they
+// are kept in a temporary directory which is removed by Clean.
+// The DefaultRemote is used if remote is nil.
+func NewCache(opt options.AtomGitOptions, remote RemoteFunc) (*Cache, error) {
+	if err := opt.Validate(); err != nil {
+		return nil, err
+	}
+
+	if remote == nil {
+		remote = DefaultRemote
+	}
+
+	c := &Cache{
+		remote:   remote,
+		getToken: opt.TokenGenerator,
+		locks:    map[string]*sync.RWMutex{},
+	}
+
+	if opt.CacheRepoOnPV {
+		if err := os.MkdirAll(opt.RepoCacheDir, 0o755); err != nil {
+			return nil, err
+		}
+
+		c.dir = opt.RepoCacheDir
+
+		return c, nil
+	}
+
+	dir, err := os.MkdirTemp(opt.RepoCacheDir, "repocache")
+	if err != nil {
+		return nil, err
+	}
+
+	c.dir = dir
+	c.tempDir = true
+
+	return c, nil
+}
+
+// Clean removes the mirrors if they are not cached on persistent volume.
+func (c *Cache) Clean() error {
+	if !c.tempDir {
+		return nil
+	}
+
+	return os.RemoveAll(c.dir)
+}
+
+func (c *Cache) lockOf(org, repo string) *sync.RWMutex {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	k := org + "/" + repo
+	l, ok := c.locks[k]
+	if !ok {
+		l = new(sync.RWMutex)
+		c.locks[k] = l
+	}
+
+	return l
+}
+
+func (c *Cache) repoDir(org, repo string) string {
+	return filepath.Join(c.dir, org, repo+".git")
+}
+
+func (c *Cache) isCached(org, repo string) bool {
+	_, err := os.Stat(c.repoDir(org, repo))
+
+	return err == nil
+}
+
+// Sync clones the repository if it is not cached, or fetches the latest refs of it.
+func (c *Cache) Sync(org, repo string) error {
+	l := c.lockOf(org, repo)
+	l.Lock()
+	defer l.Unlock()
+
+	return c.sync(org, repo)
+}
+
+func (c *Cache) sync(org, repo string) error {
+	dir := c.repoDir(org, repo)
+
+	if c.isCached(org, repo) {
+		_, err := c.git(dir, "remote", "update", "--prune")
+
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return err
+	}
+
+	if _, err := c.git("", "clone", "--mirror", c.remote(org, repo), dir); err != nil {
+		_ = os.RemoveAll(dir)
+
+		return err
+	}
+
+	return nil
+}
+
+// HandlePushEvent fetches the repository pushed if it has been cached.
+// It can be registered as the push event handler of a robot directly.
+func (c *Cache) HandlePushEvent(e *sdk.PushEvent, _ config.Config, log *logrus.Entry) error {
+	org := e.GetRepo().GetOwner().GetLogin()
+	repo := e.GetRepo().GetName()
+
+	if !c.isCached(org, repo) {
+		return nil
+	}
+
+	log.Debugf("fetch %s/%s for the push to %s", org, repo, e.GetRef())
+
+	return c.Sync(org, repo)
+}
+
+// query runs f on the mirror of repository, and clones it at first if needed.
+func (c *Cache) query(org, repo string, f func(dir string) error) error {
+	l := c.lockOf(org, repo)
+
+	if !c.isCached(org, repo) {
+		l.Lock()
+		err := func() error {
+			defer l.Unlock()
+
+			if c.isCached(org, repo) {
+				return nil
+			}
+
+			return c.sync(org, repo)
+		}()
+		if err != nil {
+			return err
+		}
+	}
+
+	l.RLock()
+	defer l.RUnlock()
+
+	return f(c.repoDir(org, repo))
+}
+
+func (c *Cache) git(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	// pass the token by environment instead of arguments to keep it secret.
+	if c.getToken != nil {
+		if t := c.getToken(); len(t) > 0 {
+			auth := base64.StdEncoding.EncodeToString(append([]byte("oauth2:"), t...))
+			cmd.Env = append(
+				cmd.Env,
+				"GIT_CONFIG_COUNT=1",
+				"GIT_CONFIG_KEY_0=http.extraHeader",
+				"GIT_CONFIG_VALUE_0=Authorization: Basic "+auth,
+			)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf(
+			"git %s, err:%s, stderr:%s",
+			strings.Join(args, " "), err.Error(), strings.TrimSpace(stderr.String()),
+		)
+	}
+
+	return stdout.Bytes(), nil
+}
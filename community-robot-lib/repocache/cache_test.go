@@ -0,0 +1,129 @@
+package repocache
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	sdk "github.com/opensourceways/go-atomgit/atomgit"
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/community-robot-lib/options"
+)
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(
+		os.Environ(),
+		"GIT_AUTHOR_NAME=robot", "GIT_AUTHOR_EMAIL=robot@example.com",
+		"GIT_COMMITTER_NAME=robot", "GIT_COMMITTER_EMAIL=robot@example.com",
+	)
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v, %s", args, err, out)
+	}
+}
+
+func writeFile(t *testing.T, dir, path, content string) {
+	t.Helper()
+
+	p := filepath.Join(dir, path)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCache(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	// the source is the repository openeuler/community.
+	src := t.TempDir()
+	runGit(t, src, "init", "-q", "-b", "master")
+	writeFile(t, src, "README.md", "readme")
+	writeFile(t, src, "sig/infra/OWNERS", "maintainers: [alice]")
+	runGit(t, src, "add", "-A")
+	runGit(t, src, "commit", "-q", "-m", "init")
+
+	runGit(t, src, "checkout", "-q", "-b", "feature")
+	writeFile(t, src, "sig/infra/sig-info.yaml", "name: infra")
+	runGit(t, src, "rm", "-q", "README.md")
+	runGit(t, src, "add", "-A")
+	runGit(t, src, "commit", "-q", "-m", "feature")
+
+	c, err := NewCache(
+		options.AtomGitOptions{RepoCacheDir: t.TempDir(), CacheRepoOnPV: true},
+		func(org, repo string) string { return src },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := c.ReadFile("openeuler", "community", "master", "sig/infra/OWNERS")
+	if err != nil || string(b) != "maintainers: [alice]" {
+		t.Fatalf("read file: %q, %v", b, err)
+	}
+
+	entries, err := c.ListTree("openeuler", "community", "feature", "sig", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths := make([]string, len(entries))
+	for i, e := range entries {
+		paths[i] = e.GetPath()
+	}
+
+	want := []string{"sig/infra", "sig/infra/OWNERS", "sig/infra/sig-info.yaml"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("tree = %v, want %v", paths, want)
+	}
+
+	changes, err := c.ChangedFiles("openeuler", "community", "master", "feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantChanges := []FileChange{
+		{Status: "D", Path: "README.md"},
+		{Status: "A", Path: "sig/infra/sig-info.yaml"},
+	}
+	if !reflect.DeepEqual(changes, wantChanges) {
+		t.Errorf("changes = %+v, want %+v", changes, wantChanges)
+	}
+
+	base, err := c.MergeBase("openeuler", "community", "master", "feature")
+	if err != nil || len(base) != 40 {
+		t.Errorf("merge base: %q, %v", base, err)
+	}
+
+	// the push is fetched by the cache.
+	runGit(t, src, "checkout", "-q", "master")
+	writeFile(t, src, "README.md", "new readme")
+	runGit(t, src, "commit", "-q", "-am", "update")
+
+	e := &sdk.PushEvent{
+		Ref: sdk.String("refs/heads/master"),
+		Repo: &sdk.PushEventRepository{
+			Name:  sdk.String("community"),
+			Owner: &sdk.User{Login: sdk.String("openeuler")},
+		},
+	}
+	if err := c.HandlePushEvent(e, nil, logrus.NewEntry(logrus.New())); err != nil {
+		t.Fatal(err)
+	}
+
+	if b, err = c.ReadFile("openeuler", "community", "master", "README.md"); err != nil || string(b) != "new readme" {
+		t.Errorf("read file after push: %q, %v", b, err)
+	}
+}
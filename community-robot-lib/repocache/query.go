@@ -0,0 +1,185 @@
+package repocache
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	sdk "github.com/opensourceways/go-atomgit/atomgit"
+)
+
+// FileChange is a file changed between two revisions.
+type FileChange struct {
+	// Status is the first letter of git status, such as A, M, D, R.
+	Status string
+
+	Path string
+
+	// OldPath is set when the file is renamed or copied.
+	OldPath string
+}
+
+// ReadFile returns the content of file at path on the revision.
+func (c *Cache) ReadFile(org, repo, rev, path string) (content []byte, err error) {
+	err = c.query(org, repo, func(dir string) error {
+		content, err = c.git(dir, "cat-file", "blob", rev+":"+strings.TrimPrefix(path, "/"))
+
+		return err
+	})
+
+	return
+}
+
+// ListTree returns the entries under the directory of path on the revision,
+// the path is the root of repository if it is empty. The entries are the same
+// as the ones returned by atomgitclient.GetDirectoryTree.
+func (c *Cache) ListTree(org, repo, rev, path string, recursive bool) (entries []*sdk.TreeEntry, err error) {
+	path = strings.Trim(path, "/")
+
+	args := []string{"ls-tree", "-z", "--long"}
+	if recursive {
+		args = append(args, "-r", "-t")
+	}
+
+	if path == "" {
+		args = append(args, rev)
+	} else {
+		args = append(args, rev+":"+path)
+	}
+
+	err = c.query(org, repo, func(dir string) error {
+		out, err := c.git(dir, args...)
+		if err != nil {
+			return err
+		}
+
+		entries, err = parseTree(out, path)
+
+		return err
+	})
+
+	return
+}
+
+// parseTree parses the output of "ls-tree -z --long" which is in the format of
+// "<mode> SP <type> SP <object> SP+ <size> TAB <file>" for each entry.
+func parseTree(out []byte, dir string) ([]*sdk.TreeEntry, error) {
+	var r []*sdk.TreeEntry
+
+	for _, line := range bytes.Split(out, []byte{0}) {
+		if len(line) == 0 {
+			continue
+		}
+
+		meta, name, ok := strings.Cut(string(line), "\t")
+		if !ok {
+			return nil, fmt.Errorf("invalid tree entry:%s", line)
+		}
+
+		items := strings.Fields(meta)
+		if len(items) != 4 {
+			return nil, fmt.Errorf("invalid tree entry:%s", line)
+		}
+
+		if dir != "" {
+			name = dir + "/" + name
+		}
+
+		e := &sdk.TreeEntry{
+			Mode: sdk.String(items[0]),
+			Type: sdk.String(items[1]),
+			SHA:  sdk.String(items[2]),
+			Path: sdk.String(name),
+		}
+
+		if n, err := strconv.Atoi(items[3]); err == nil {
+			e.Size = sdk.Int(n)
+		}
+
+		r = append(r, e)
+	}
+
+	return r, nil
+}
+
+// ChangedFiles returns the files changed on head since it forked from base,
+// which are the changes of a pull request from head to base.
+func (c *Cache) ChangedFiles(org, repo, base, head string) (changes []FileChange, err error) {
+	err = c.query(org, repo, func(dir string) error {
+		out, err := c.git(dir, "diff", "--name-status", "-z", base+"..."+head)
+		if err != nil {
+			return err
+		}
+
+		changes, err = parseNameStatus(out)
+
+		return err
+	})
+
+	return
+}
+
+func parseNameStatus(out []byte) ([]FileChange, error) {
+	var r []FileChange
+
+	items := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	for i := 0; i < len(items); i++ {
+		if items[i] == "" {
+			continue
+		}
+
+		status := items[i][:1]
+		if i+1 >= len(items) {
+			return nil, fmt.Errorf("invalid name status:%s", out)
+		}
+
+		fc := FileChange{Status: status, Path: items[i+1]}
+		i++
+
+		// the renamed or copied file has the old and new paths.
+		if status == "R" || status == "C" {
+			if i+1 >= len(items) {
+				return nil, fmt.Errorf("invalid name status:%s", out)
+			}
+
+			fc.OldPath = fc.Path
+			fc.Path = items[i+1]
+			i++
+		}
+
+		r = append(r, fc)
+	}
+
+	return r, nil
+}
+
+// Diff returns the patch of head since it forked from base. Only the files
+// under paths are compared if they are specified.
+func (c *Cache) Diff(org, repo, base, head string, paths ...string) (patch string, err error) {
+	args := []string{"diff", base + "..." + head}
+	if len(paths) > 0 {
+		args = append(append(args, "--"), paths...)
+	}
+
+	err = c.query(org, repo, func(dir string) error {
+		out, err := c.git(dir, args...)
+		patch = string(out)
+
+		return err
+	})
+
+	return
+}
+
+// MergeBase returns the best common ancestor of the two revisions.
+func (c *Cache) MergeBase(org, repo, rev1, rev2 string) (sha string, err error) {
+	err = c.query(org, repo, func(dir string) error {
+		out, err := c.git(dir, "merge-base", rev1, rev2)
+		sha = strings.TrimSpace(string(out))
+
+		return err
+	})
+
+	return
+}
@@ -0,0 +1,327 @@
+package framework
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/opensourceways/go-atomgit/atomgit"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/opensourceways/community-robot-lib/atomgitclient"
+	"github.com/opensourceways/community-robot-lib/tracing"
+)
+
+const (
+	// AdminHandlersPath lists the events which the robot handles.
+	AdminHandlersPath = "/admin/handlers"
+
+	// AdminTriggerPath runs the handler with the event built from the current state of
+	// a pull request or an issue, and responds the logs and error of handler.
+	AdminTriggerPath = "/admin/trigger"
+
+	AdminEventPullRequest = "pull_request"
+	AdminEventIssues      = "issues"
+
+	adminDefaultAction = "edited"
+)
+
+// adminClient is the methods of atomgitclient.Client used by the admin api.
+type adminClient interface {
+	GetSinglePR(pr *atomgitclient.PRIssue) (*sdk.PullRequest, error)
+	GetSingleIssue(is *atomgitclient.PRIssue) (*sdk.Issue, error)
+}
+
+// AdminRequest is the body of request to AdminTriggerPath.
+type AdminRequest struct {
+	// Event is pull_request or issues.
+	Event  string `json:"event"`
+	Org    string `json:"org"`
+	Repo   string `json:"repo"`
+	Number int    `json:"number"`
+
+	// Action is the action of event, it is edited if empty.
+	Action string `json:"action,omitempty"`
+
+	// Sender is the login of user who sends the event, it is empty by default.
+	Sender string `json:"sender,omitempty"`
+}
+
+func (r *AdminRequest) validate() error {
+	if r.Event != AdminEventPullRequest && r.Event != AdminEventIssues {
+		return fmt.Errorf("unsupported event: %q, it must be %s or %s", r.Event, AdminEventPullRequest, AdminEventIssues)
+	}
+
+	if r.Org == "" || r.Repo == "" || r.Number <= 0 {
+		return errors.New("missing org, repo or number")
+	}
+
+	return nil
+}
+
+// AdminLog is a log written by the handler.
+type AdminLog struct {
+	Time    time.Time              `json:"time"`
+	Level   string                 `json:"level"`
+	Message string                 `json:"msg"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
+// AdminResponse is the result of handling the event triggered.
+type AdminResponse struct {
+	Event  string     `json:"event"`
+	Action string     `json:"action"`
+	URL    string     `json:"url,omitempty"`
+	Logs   []AdminLog `json:"logs"`
+	Error  string     `json:"error,omitempty"`
+}
+
+// registerAdminEndpoints serves the admin api which is authorized by the bearer token.
+func registerAdminEndpoints(mux *http.ServeMux, d *dispatcher, cli adminClient, token func() []byte) {
+	a := &admin{d: d, cli: cli}
+
+	mux.Handle(AdminHandlersPath, authorizeAdmin(token, http.MethodGet, a.serveHandlers))
+	mux.Handle(AdminTriggerPath, authorizeAdmin(token, http.MethodPost, a.serveTrigger))
+}
+
+func authorizeAdmin(token func() []byte, method string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		v := r.Header.Get("Authorization")
+		t := token()
+
+		if len(t) == 0 || !strings.HasPrefix(v, "Bearer ") ||
+			subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(v, "Bearer ")), t) != 1 {
+			http.Error(w, "401 Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		h(w, r)
+	}
+}
+
+type admin struct {
+	d   *dispatcher
+	cli adminClient
+}
+
+func (a *admin) serveHandlers(w http.ResponseWriter, r *http.Request) {
+	h := &a.d.h
+
+	registered := []struct {
+		event string
+		ok    bool
+	}{
+		{"access", h.accessHandlers != nil},
+		{AdminEventIssues, h.issueHandlers != nil},
+		{AdminEventPullRequest, h.pullRequestHandler != nil},
+		{"push", h.pushEventHandler != nil},
+		{"issue_comment", h.issueCommentHandler != nil},
+		{"pull_request_review", h.reviewEventHandler != nil},
+		{"pull_request_review_comment", h.reviewCommentEventHandler != nil},
+	}
+
+	events := []string{}
+	for _, v := range registered {
+		if v.ok {
+			events = append(events, v.event)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string][]string{"events": events})
+}
+
+// serveTrigger responds 200 if the handler succeeds, and 422 with the error if it fails.
+func (a *admin) serveTrigger(w http.ResponseWriter, r *http.Request) {
+	var req AdminRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "400 Bad Request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := req.validate(); err != nil {
+		http.Error(w, "400 Bad Request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Action == "" {
+		req.Action = adminDefaultAction
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"event":  req.Event,
+		"target": fmt.Sprintf("%s/%s/%d", req.Org, req.Repo, req.Number),
+		"action": req.Action,
+	}).Info("trigger the handler by admin api")
+
+	resp, code := a.trigger(&req)
+
+	writeJSON(w, code, resp)
+}
+
+func (a *admin) trigger(req *AdminRequest) (*AdminResponse, int) {
+	resp := &AdminResponse{Event: req.Event, Action: req.Action, Logs: []AdminLog{}}
+
+	fail := func(code int, err error) (*AdminResponse, int) {
+		resp.Error = err.Error()
+
+		return resp, code
+	}
+
+	h := &a.d.h
+	if (req.Event == AdminEventPullRequest && h.pullRequestHandler == nil) ||
+		(req.Event == AdminEventIssues && h.issueHandlers == nil) {
+		return fail(http.StatusNotFound, fmt.Errorf("the robot doesn't handle the %s event", req.Event))
+	}
+
+	ctx, span := tracing.Start(
+		context.Background(), "admin "+req.Event,
+		trace.WithAttributes(attribute.String("atomgit.event", req.Event)),
+	)
+
+	hook := &logHook{}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	logger.SetLevel(logrus.GetLevel())
+	logger.AddHook(hook)
+
+	l := logrus.NewEntry(logger).WithFields(logrus.Fields{
+		LogFieldEventType: req.Event,
+		"admin":           true,
+	}).WithContext(ctx)
+
+	pi := atomgitclient.BuildPRIssue(req.Org, req.Repo, req.Number)
+	repo := adminRepo(req.Org, req.Repo)
+
+	var sender *sdk.User
+	if req.Sender != "" {
+		sender = &sdk.User{Login: sdk.String(req.Sender)}
+	}
+
+	a.d.start()
+	defer a.d.done()
+
+	var err error
+
+	switch req.Event {
+	case AdminEventPullRequest:
+		var pr *sdk.PullRequest
+		if pr, err = a.cli.GetSinglePR(pi); err != nil {
+			tracing.End(span, err)
+
+			return fail(http.StatusBadGateway, fmt.Errorf("get the pull request, err: %s", err.Error()))
+		}
+
+		if v := pr.GetBase().GetRepo(); v.GetFullName() != "" {
+			repo = v
+		}
+
+		resp.URL = pr.GetHTMLURL()
+
+		err = a.d.handlePullRequestEvent(&sdk.PullRequestEvent{
+			Action:      sdk.String(req.Action),
+			Number:      sdk.Int(req.Number),
+			PullRequest: pr,
+			Repo:        repo,
+			Sender:      sender,
+		}, l)
+
+	case AdminEventIssues:
+		var issue *sdk.Issue
+		if issue, err = a.cli.GetSingleIssue(pi); err != nil {
+			tracing.End(span, err)
+
+			return fail(http.StatusBadGateway, fmt.Errorf("get the issue, err: %s", err.Error()))
+		}
+
+		if v := issue.GetRepository(); v.GetFullName() != "" {
+			repo = v
+		}
+
+		resp.URL = issue.GetHTMLURL()
+
+		err = a.d.handleIssueEvent(&sdk.IssuesEvent{
+			Action: sdk.String(req.Action),
+			Issue:  issue,
+			Repo:   repo,
+			Sender: sender,
+		}, l)
+	}
+
+	tracing.End(span, err)
+
+	resp.Logs = hook.logs()
+
+	if err != nil {
+		return fail(http.StatusUnprocessableEntity, err)
+	}
+
+	return resp, http.StatusOK
+}
+
+// adminRepo builds the repository of event if the one fetched is incomplete.
+func adminRepo(org, repo string) *sdk.Repository {
+	return &sdk.Repository{
+		Name:     sdk.String(repo),
+		FullName: sdk.String(org + "/" + repo),
+		Owner:    &sdk.User{Login: sdk.String(org)},
+	}
+}
+
+// logHook collects the logs written by the handler, and writes them to the standard
+// logger too, so that they are kept in the logs of robot.
+type logHook struct {
+	mut     sync.Mutex
+	entries []AdminLog
+}
+
+func (h *logHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *logHook) Fire(e *logrus.Entry) error {
+	fields := make(map[string]interface{}, len(e.Data))
+	for k, v := range e.Data {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+
+		fields[k] = v
+	}
+
+	h.mut.Lock()
+	h.entries = append(h.entries, AdminLog{
+		Time:    e.Time,
+		Level:   e.Level.String(),
+		Message: e.Message,
+		Fields:  fields,
+	})
+	h.mut.Unlock()
+
+	if e.Level > logrus.PanicLevel {
+		logrus.WithFields(e.Data).WithContext(e.Context).Log(e.Level, e.Message)
+	}
+
+	return nil
+}
+
+func (h *logHook) logs() []AdminLog {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	return append([]AdminLog{}, h.entries...)
+}
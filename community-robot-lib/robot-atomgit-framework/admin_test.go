@@ -0,0 +1,184 @@
+package framework
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opensourceways/go-atomgit/atomgit"
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/community-robot-lib/atomgitclient"
+	"github.com/opensourceways/community-robot-lib/config"
+	"github.com/opensourceways/community-robot-lib/options"
+)
+
+type adminRobot struct{}
+
+func (bot adminRobot) NewConfig() config.Config {
+	return nil
+}
+
+func (bot adminRobot) RegisterEventHandler(f HandlerRegister) {
+	f.RegisterPullRequestHandler(func(e *atomgit.PullRequestEvent, _ config.Config, log *logrus.Entry) error {
+		org, repo := e.GetRepo().GetOrgAndRepo()
+		log.Infof("check %s/%s#%d", org, repo, e.GetPullRequest().GetNumber())
+
+		if e.GetPullRequest().GetNumber() == 2 {
+			return errors.New("failed")
+		}
+
+		return nil
+	})
+}
+
+type fakeAdminClient struct{}
+
+func (c fakeAdminClient) GetSinglePR(pr *atomgitclient.PRIssue) (*atomgit.PullRequest, error) {
+	return &atomgit.PullRequest{Number: atomgit.Int(pr.Number)}, nil
+}
+
+func (c fakeAdminClient) GetSingleIssue(is *atomgitclient.PRIssue) (*atomgit.Issue, error) {
+	return &atomgit.Issue{Number: atomgit.Int(is.Number)}, nil
+}
+
+// fakeClient is the client of AtomGit whose methods used by the admin api are faked.
+type fakeClient struct {
+	atomgitclient.Client
+	fakeAdminClient
+}
+
+func (c fakeClient) GetSinglePR(pr *atomgitclient.PRIssue) (*atomgit.PullRequest, error) {
+	return c.fakeAdminClient.GetSinglePR(pr)
+}
+
+func (c fakeClient) GetSingleIssue(is *atomgitclient.PRIssue) (*atomgit.Issue, error) {
+	return c.fakeAdminClient.GetSingleIssue(is)
+}
+
+func TestAdminClientWithoutTokenGenerator(t *testing.T) {
+	servOpt := options.ServiceOptions{AdminToken: func() []byte { return []byte("token") }}
+
+	// the robots which don't set the token generator of AtomGit options must set the client.
+	var ro runOptions
+	if _, err := ro.atomgitClient(servOpt, options.AtomGitOptions{}); err == nil {
+		t.Fatal("expect an error when neither the client nor the token generator is set")
+	}
+
+	WithClient(fakeClient{})(&ro)
+
+	cli, err := ro.atomgitClient(servOpt, options.AtomGitOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	agent := config.NewConfigAgent(adminRobot{}.NewConfig)
+	d := newDispatcher(adminRobot{}, &agent, nil)
+
+	mux := http.NewServeMux()
+	registerAdminEndpoints(mux, d, cli, servOpt.AdminToken)
+
+	body, _ := json.Marshal(AdminRequest{Event: AdminEventPullRequest, Org: "openeuler", Repo: "community", Number: 1})
+
+	r := httptest.NewRequest(http.MethodPost, AdminTriggerPath, bytes.NewReader(body))
+	r.Header.Set("Authorization", "Bearer token")
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("code = %d, body = %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAdminTrigger(t *testing.T) {
+	agent := config.NewConfigAgent(adminRobot{}.NewConfig)
+	d := newDispatcher(adminRobot{}, &agent, nil)
+
+	mux := http.NewServeMux()
+	registerAdminEndpoints(mux, d, fakeAdminClient{}, func() []byte { return []byte("token") })
+
+	trigger := func(token string, req AdminRequest) (int, AdminResponse) {
+		body, _ := json.Marshal(req)
+
+		r := httptest.NewRequest(http.MethodPost, AdminTriggerPath, bytes.NewReader(body))
+		r.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+
+		var resp AdminResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+
+		return w.Code, resp
+	}
+
+	pr := AdminRequest{Event: AdminEventPullRequest, Org: "openeuler", Repo: "community", Number: 1}
+
+	if code, _ := trigger("wrong", pr); code != http.StatusUnauthorized {
+		t.Errorf("code with wrong token = %d", code)
+	}
+
+	code, resp := trigger("token", pr)
+	if code != http.StatusOK || resp.Action != adminDefaultAction || resp.Error != "" {
+		t.Fatalf("code = %d, resp = %+v", code, resp)
+	}
+
+	if len(resp.Logs) == 0 || resp.Logs[0].Message != "check openeuler/community#1" {
+		t.Errorf("logs = %+v", resp.Logs)
+	}
+
+	pr.Number = 2
+	if code, resp = trigger("token", pr); code != http.StatusUnprocessableEntity || resp.Error != "failed" {
+		t.Errorf("code = %d, resp = %+v", code, resp)
+	}
+
+	issue := AdminRequest{Event: AdminEventIssues, Org: "openeuler", Repo: "community", Number: 1}
+	if code, _ = trigger("token", issue); code != http.StatusNotFound {
+		t.Errorf("code of event not handled = %d", code)
+	}
+
+	if code, _ = trigger("token", AdminRequest{Event: "push"}); code != http.StatusBadRequest {
+		t.Errorf("code of unsupported event = %d", code)
+	}
+}
+
+func TestAdminHandlers(t *testing.T) {
+	agent := config.NewConfigAgent(adminRobot{}.NewConfig)
+	d := newDispatcher(adminRobot{}, &agent, nil)
+
+	var token []byte
+
+	mux := http.NewServeMux()
+	registerAdminEndpoints(mux, d, fakeAdminClient{}, func() []byte { return token })
+
+	get := func(auth string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, AdminHandlersPath, nil)
+		r.Header.Set("Authorization", "Bearer "+auth)
+
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+
+		return w
+	}
+
+	// the admin api is closed if the token is missing.
+	if w := get(""); w.Code != http.StatusUnauthorized {
+		t.Errorf("code without token = %d", w.Code)
+	}
+
+	token = []byte("token")
+
+	w := get("token")
+	if w.Code != http.StatusOK {
+		t.Fatalf("code = %d", w.Code)
+	}
+
+	var v map[string][]string
+	if err := json.Unmarshal(w.Body.Bytes(), &v); err != nil || len(v["events"]) != 1 || v["events"][0] != AdminEventPullRequest {
+		t.Errorf("handlers = %s", w.Body.String())
+	}
+}
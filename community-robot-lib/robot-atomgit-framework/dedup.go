@@ -0,0 +1,120 @@
+package framework
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DeliveryStore records the deliveries of webhook seen. It can be implemented by
+// a shared storage, such as redis, so that the replicas of robot skip the same delivery.
+type DeliveryStore interface {
+	// CheckAndAdd records the delivery, and returns true if it has been seen in the time window.
+	CheckAndAdd(delivery string) (bool, error)
+
+	// Remove forgets the delivery, so that it is handled when it is retried or redelivered.
+	// It is called if the delivery fails to be handled.
+	Remove(delivery string) error
+}
+
+// NewMemoryDeliveryStore returns the store which keeps the deliveries seen in the window
+// in memory. It keeps at most size deliveries, and the oldest ones are removed first.
+func NewMemoryDeliveryStore(size int, window time.Duration) DeliveryStore {
+	return &memoryDeliveryStore{
+		size:   size,
+		window: window,
+		seen:   map[string]time.Time{},
+	}
+}
+
+type deliveryItem struct {
+	delivery string
+	expires  time.Time
+}
+
+type memoryDeliveryStore struct {
+	size   int
+	window time.Duration
+
+	mut  sync.Mutex
+	seen map[string]time.Time
+	// queue is the deliveries in the order of being seen.
+	queue []deliveryItem
+}
+
+func (s *memoryDeliveryStore) CheckAndAdd(delivery string) (bool, error) {
+	now := time.Now()
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	for len(s.queue) > 0 && !now.Before(s.queue[0].expires) {
+		s.pop()
+	}
+
+	if _, ok := s.seen[delivery]; ok {
+		return true, nil
+	}
+
+	item := deliveryItem{delivery: delivery, expires: now.Add(s.window)}
+	s.seen[delivery] = item.expires
+	s.queue = append(s.queue, item)
+
+	for len(s.seen) > s.size {
+		s.pop()
+	}
+
+	return false, nil
+}
+
+func (s *memoryDeliveryStore) Remove(delivery string) error {
+	s.mut.Lock()
+	delete(s.seen, delivery)
+	s.mut.Unlock()
+
+	return nil
+}
+
+func (s *memoryDeliveryStore) pop() {
+	item := s.queue[0]
+	s.queue = s.queue[1:]
+
+	// the delivery may have been removed and seen again after the item.
+	if s.seen[item.delivery] == item.expires {
+		delete(s.seen, item.delivery)
+	}
+}
+
+// isDuplicate checks whether the delivery has been handled. The event is handled
+// if the store fails, because missing an event is worse than handling it twice.
+func (d *dispatcher) isDuplicate(delivery string, l *logrus.Entry) bool {
+	if d.deliveries == nil || delivery == "" {
+		return false
+	}
+
+	seen, err := d.deliveries.CheckAndAdd(delivery)
+	if err != nil {
+		l.WithError(err).Warn("check the delivery")
+
+		return false
+	}
+
+	if seen {
+		l.Info("skip the duplicate delivery")
+	}
+
+	return seen
+}
+
+// forgetDelivery removes the delivery which fails to be handled from the store, so that
+// the retry of MQ or the redelivery of AtomGit is not skipped as a duplicate.
+func (d *dispatcher) forgetDelivery(delivery string, l *logrus.Entry) {
+	if d.deliveries == nil || delivery == "" {
+		return
+	}
+
+	if err := d.deliveries.Remove(delivery); err != nil {
+		l.WithError(err).Warn("remove the delivery")
+	}
+}
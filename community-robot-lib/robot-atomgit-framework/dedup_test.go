@@ -0,0 +1,110 @@
+package framework
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opensourceways/community-robot-lib/config"
+	"github.com/opensourceways/community-robot-lib/mq"
+)
+
+func TestMemoryDeliveryStore(t *testing.T) {
+	s := NewMemoryDeliveryStore(2, time.Hour)
+
+	steps := []struct {
+		delivery string
+		seen     bool
+	}{
+		{"a", false},
+		{"a", true},
+		{"b", false},
+		// a is removed because there are at most 2 deliveries.
+		{"c", false},
+		{"a", false},
+		{"c", true},
+	}
+
+	for i, v := range steps {
+		if seen, _ := s.CheckAndAdd(v.delivery); seen != v.seen {
+			t.Errorf("step %d: seen of %s = %t", i, v.delivery, seen)
+		}
+	}
+
+	// the delivery removed is handled again.
+	_ = s.Remove("c")
+	if seen, _ := s.CheckAndAdd("c"); seen {
+		t.Error("the delivery removed should not be seen")
+	}
+
+	s = NewMemoryDeliveryStore(10, time.Millisecond)
+	_, _ = s.CheckAndAdd("a")
+
+	time.Sleep(5 * time.Millisecond)
+
+	if seen, _ := s.CheckAndAdd("a"); seen {
+		t.Error("the delivery should expire after the window")
+	}
+}
+
+func TestSkipDuplicateDelivery(t *testing.T) {
+	bot := &testRobot{}
+	agent := config.NewConfigAgent(bot.NewConfig)
+	d := newDispatcher(bot, &agent, nil)
+	d.deliveries = NewMemoryDeliveryStore(10, time.Hour)
+
+	payload := []byte(`{"action":"created","issue":{"number":1}}`)
+
+	for _, delivery := range []string{"1", "1", "2", ""} {
+		req := httptest.NewRequest(http.MethodPost, "/atomgit-hook", bytes.NewReader(payload))
+		req.Header.Set("User-Agent", UserAgentHeader)
+		req.Header.Set("X-AtomGit-Event", "issues")
+		req.Header.Set("X-AtomGit-Delivery", delivery)
+
+		d.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// the message of the same delivery from MQ is skipped too.
+	if err := d.handleMessage(&testEvent{m: NewEventMessage("issues", "2", payload, "o", "r")}); err != nil {
+		t.Fatal(err)
+	}
+
+	d.Wait()
+
+	// the event without delivery is always handled.
+	if len(bot.issues) != 3 {
+		t.Errorf("%d events are handled, want 3", len(bot.issues))
+	}
+}
+
+func TestRetryDuplicateDelivery(t *testing.T) {
+	bot := &failingRobot{failures: 1}
+	agent := config.NewConfigAgent(bot.NewConfig)
+	d := newDispatcher(bot, &agent, nil)
+	d.deliveries = NewMemoryDeliveryStore(10, time.Hour)
+
+	msg := NewEventMessage("issues", "1", []byte(`{"action":"created","issue":{"number":1}}`), "o", "r")
+	policy := mq.RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond}
+
+	// the retry is not skipped as a duplicate though the delivery has been seen.
+	if err := policy.Handle(context.Background(), &testEvent{m: msg}, d.handleMessage); err != nil {
+		t.Fatalf("the retry should succeed, err: %v", err)
+	}
+
+	if n := atomic.LoadInt32(&bot.calls); n != 2 {
+		t.Errorf("the handler should be called 2 times, got %d", n)
+	}
+
+	// the delivery handled is skipped.
+	if err := d.handleMessage(&testEvent{m: msg}); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := atomic.LoadInt32(&bot.calls); n != 2 {
+		t.Errorf("the duplicate delivery should be skipped, calls: %d", n)
+	}
+}
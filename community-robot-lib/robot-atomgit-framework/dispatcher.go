@@ -1,6 +1,7 @@
 package framework
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
@@ -8,10 +9,16 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
 
-	"github.com/opensourceways/community-robot-lib/config"
 	sdk "github.com/opensourceways/go-atomgit/atomgit"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/opensourceways/community-robot-lib/config"
+	"github.com/opensourceways/community-robot-lib/tracing"
 )
 
 const (
@@ -29,64 +36,217 @@ const (
 type dispatcher struct {
 	agent *config.ConfigAgent
 
-	h handlers
+	bot Robot
+	h   handlers
 
 	// Tracks running handlers for graceful shutdown
 	wg sync.WaitGroup
 
+	// pending is the number of events being handled
+	pending int64
+
 	// secret usage
 	hmac func() []byte
+
+	// forwardSecrets returns the secrets to verify the events forwarded by the access
+	// robot. The events are not verified if it is nil.
+	forwardSecrets func() [][]byte
+
+	// rec saves the webhooks received if it is set
+	rec *recorder
+
+	// deliveries records the deliveries seen to skip the duplicate ones if it is set
+	deliveries DeliveryStore
+
+	// isLeader reports whether the replica is the leader, only the leader handles
+	// the events if it is set
+	isLeader func() bool
 }
 
 func (d *dispatcher) Wait() {
 	d.wg.Wait() // Handle remaining requests
 }
 
+// Dispatch handles the event in background, and it returns the error of parsing the event.
 func (d *dispatcher) Dispatch(eventType string, payload []byte, l *logrus.Entry) error {
+	return d.dispatch(eventType, payload, l, func(f func() error) error {
+		d.start()
+
+		go func() {
+			defer d.done()
+
+			_ = f()
+		}()
+
+		return nil
+	})
+}
+
+// dispatchSync handles the event before returning, so that the events are handled
+// in the order they are received, such as the messages of a partition of MQ.
+// It returns the error of handler too, so that the message can be retried.
+func (d *dispatcher) dispatchSync(eventType string, payload []byte, l *logrus.Entry) error {
+	return d.dispatch(eventType, payload, l, func(f func() error) error {
+		d.start()
+		defer d.done()
+
+		return f()
+	})
+}
+
+func (d *dispatcher) start() {
+	d.wg.Add(1)
+	atomic.AddInt64(&d.pending, 1)
+}
+
+func (d *dispatcher) done() {
+	atomic.AddInt64(&d.pending, -1)
+	d.wg.Done()
+}
+
+// pendingEvents returns the number of events being handled.
+func (d *dispatcher) pendingEvents() int {
+	return int(atomic.LoadInt64(&d.pending))
+}
+
+// dispatch parses the event and handles it by run. It returns what run returns.
+func (d *dispatcher) dispatch(eventType string, payload []byte, l *logrus.Entry, run func(func() error) error) error {
+	delivery, _ := l.Data[LogFieldEventId].(string)
+
+	ctx, span := tracing.Start(
+		logContext(l), "handle "+eventType,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("atomgit.event", eventType),
+			attribute.String("atomgit.delivery", delivery),
+		),
+	)
+	l = l.WithContext(ctx)
+
 	hook, err := sdk.ParseWebHook(eventType, payload)
 	if err != nil {
+		tracing.End(span, err)
+
 		return err
 	}
 
+	var h func() error
+
 	switch hookType := hook.(type) {
 	case *sdk.AccessEvent:
-		d.wg.Add(1)
-		go d.handleAccessEvent(hookType, l, payload)
+		if d.h.accessHandlers != nil {
+			h = func() error { return d.handleAccessEvent(hookType, l, payload) }
+		}
 	case *sdk.IssuesEvent:
-		d.wg.Add(1)
-		go d.handleIssueEvent(hookType, l)
+		if d.h.issueHandlers != nil {
+			h = func() error { return d.handleIssueEvent(hookType, l) }
+		}
 	case *sdk.PullRequestEvent:
-		d.wg.Add(1)
-		go d.handlePullRequestEvent(hookType, l)
+		if d.h.pullRequestHandler != nil {
+			h = func() error { return d.handlePullRequestEvent(hookType, l) }
+		}
 	case *sdk.PushEvent:
-		d.wg.Add(1)
-		go d.handlePushEvent(hookType, l)
+		if d.h.pushEventHandler != nil {
+			h = func() error { return d.handlePushEvent(hookType, l) }
+		}
 	case *sdk.IssueCommentEvent:
-		d.wg.Add(1)
-		go d.handleIssueCommentEvent(hookType, l)
+		if d.h.issueCommentHandler != nil {
+			h = func() error { return d.handleIssueCommentEvent(hookType, l) }
+		}
 	case *sdk.PullRequestReviewEvent:
-		d.wg.Add(1)
-		go d.handleReviewEvent(hookType, l)
+		if d.h.reviewEventHandler != nil {
+			h = func() error { return d.handleReviewEvent(hookType, l) }
+		}
 	case *sdk.PullRequestReviewCommentEvent:
-		d.wg.Add(1)
-		go d.handleReviewCommentEvent(hookType, l)
+		if d.h.reviewCommentEventHandler != nil {
+			h = func() error { return d.handleReviewCommentEvent(hookType, l) }
+		}
 	default:
+		span.End()
 		l.Debug("Ignoring unknown event type")
+
+		return nil
+	}
+
+	if h == nil {
+		span.End()
+		l.Info("Ignoring the event, because the robot has no handler of it")
+
+		return nil
+	}
+
+	// the span ends when the event has been handled.
+	return run(func() error {
+		defer span.End()
+
+		err := h()
+		if err != nil {
+			d.forgetDelivery(delivery, l)
+		}
+
+		return err
+	})
+}
+
+// logContext returns the context of log entry, which carries the trace of event.
+func logContext(l *logrus.Entry) context.Context {
+	if l.Context == nil {
+		return context.Background()
+	}
+
+	return l.Context
+}
+
+// logResult logs the result of handler, and records it to the span of event.
+// It returns err.
+func logResult(l *logrus.Entry, err error) error {
+	span := trace.SpanFromContext(logContext(l))
+
+	for _, k := range []string{LogFieldOrg, LogFieldRepo, logFieldURL, logFieldAction} {
+		if v, ok := l.Data[k].(string); ok {
+			span.SetAttributes(attribute.String("atomgit."+k, v))
+		}
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		l.WithError(err).Error()
+	} else {
+		l.Info()
 	}
 
-	return nil
+	return err
 }
 
+// handlersOf returns the handlers of the robot bound to the context of event if it is a
+// ContextRobot, so that each handler sends the requests of AtomGit in the span of event.
+func (d *dispatcher) handlersOf(l *logrus.Entry) *handlers {
+	b, ok := d.bot.(ContextRobot)
+	if !ok || l.Context == nil {
+		return &d.h
+	}
+
+	h := handlers{}
+	b.WithContext(l.Context).RegisterEventHandler(&h)
+
+	return &h
+}
+
+// getConfig returns nil if the robot has no config agent.
 func (d *dispatcher) getConfig() config.Config {
+	if d.agent == nil {
+		return nil
+	}
+
 	_, c := d.agent.GetConfig()
 
 	return c
 }
 
 // handleAccessEvent access robot handle request that come form webhook
-func (d *dispatcher) handleAccessEvent(e *sdk.AccessEvent, l *logrus.Entry, payload []byte) {
-	defer d.wg.Done()
-
+func (d *dispatcher) handleAccessEvent(e *sdk.AccessEvent, l *logrus.Entry, payload []byte) error {
 	org, repo := e.GetRepo().GetOrgAndRepo()
 
 	l = l.WithFields(logrus.Fields{
@@ -94,45 +254,28 @@ func (d *dispatcher) handleAccessEvent(e *sdk.AccessEvent, l *logrus.Entry, payl
 		LogFieldRepo: repo,
 	})
 
-	if err := d.h.accessHandlers(e, d.getConfig(), l, payload); err != nil {
-		l.WithError(err).Error()
-	} else {
-		l.Info()
-	}
+	return logResult(l, d.handlersOf(l).accessHandlers(e, d.getConfig(), l, payload))
 }
 
-func (d *dispatcher) handleIssueEvent(e *sdk.IssuesEvent, l *logrus.Entry) {
-	defer d.wg.Done()
-
+func (d *dispatcher) handleIssueEvent(e *sdk.IssuesEvent, l *logrus.Entry) error {
 	l = l.WithFields(logrus.Fields{
 		logFieldURL:    e.GetIssue().GetHTMLURL(),
 		logFieldAction: e.GetAction(),
 	})
 
-	if err := d.h.issueHandlers(e, d.getConfig(), l); err != nil {
-		l.WithError(err).Error()
-	} else {
-		l.Info()
-	}
+	return logResult(l, d.handlersOf(l).issueHandlers(e, d.getConfig(), l))
 }
 
-func (d *dispatcher) handlePullRequestEvent(e *sdk.PullRequestEvent, l *logrus.Entry) {
-	defer d.wg.Done()
-
+func (d *dispatcher) handlePullRequestEvent(e *sdk.PullRequestEvent, l *logrus.Entry) error {
 	l = l.WithFields(logrus.Fields{
 		logFieldURL:    e.GetPullRequest().GetHTMLURL(),
 		logFieldAction: e.GetAction(),
 	})
 
-	if err := d.h.pullRequestHandler(e, d.getConfig(), l); err != nil {
-		l.WithError(err).Error()
-	} else {
-		l.Info()
-	}
+	return logResult(l, d.handlersOf(l).pullRequestHandler(e, d.getConfig(), l))
 }
 
-func (d *dispatcher) handlePushEvent(e *sdk.PushEvent, l *logrus.Entry) {
-	defer d.wg.Done()
+func (d *dispatcher) handlePushEvent(e *sdk.PushEvent, l *logrus.Entry) error {
 	l = l.WithFields(logrus.Fields{
 		LogFieldOrg:  e.GetRepo().GetOwner().GetLogin(),
 		LogFieldRepo: e.GetRepo().GetName(),
@@ -140,31 +283,19 @@ func (d *dispatcher) handlePushEvent(e *sdk.PushEvent, l *logrus.Entry) {
 		"head":       e.GetAfter(),
 	})
 
-	if err := d.h.pushEventHandler(e, d.getConfig(), l); err != nil {
-		l.WithError(err).Error()
-	} else {
-		l.Info()
-	}
+	return logResult(l, d.handlersOf(l).pushEventHandler(e, d.getConfig(), l))
 }
 
-func (d *dispatcher) handleIssueCommentEvent(e *sdk.IssueCommentEvent, l *logrus.Entry) {
-	defer d.wg.Done()
-
+func (d *dispatcher) handleIssueCommentEvent(e *sdk.IssueCommentEvent, l *logrus.Entry) error {
 	l = l.WithFields(logrus.Fields{
 		logFieldURL:    e.GetIssue().GetHTMLURL(),
 		logFieldAction: e.GetAction(),
 	})
 
-	if err := d.h.issueCommentHandler(e, d.getConfig(), l); err != nil {
-		l.WithError(err).Error()
-	} else {
-		l.Info()
-	}
+	return logResult(l, d.handlersOf(l).issueCommentHandler(e, d.getConfig(), l))
 }
 
-func (d *dispatcher) handleReviewEvent(e *sdk.PullRequestReviewEvent, l *logrus.Entry) {
-	defer d.wg.Done()
-
+func (d *dispatcher) handleReviewEvent(e *sdk.PullRequestReviewEvent, l *logrus.Entry) error {
 	org, repo := e.GetRepo().GetOrgAndRepo()
 	l = l.WithFields(logrus.Fields{
 		LogFieldOrg:  org,
@@ -174,16 +305,10 @@ func (d *dispatcher) handleReviewEvent(e *sdk.PullRequestReviewEvent, l *logrus.
 		"url":        e.GetReview().GetHTMLURL(),
 	})
 
-	if err := d.h.reviewEventHandler(e, d.getConfig(), l); err != nil {
-		l.WithError(err).Error()
-	} else {
-		l.Info()
-	}
+	return logResult(l, d.handlersOf(l).reviewEventHandler(e, d.getConfig(), l))
 }
 
-func (d *dispatcher) handleReviewCommentEvent(e *sdk.PullRequestReviewCommentEvent, l *logrus.Entry) {
-	defer d.wg.Done()
-
+func (d *dispatcher) handleReviewCommentEvent(e *sdk.PullRequestReviewCommentEvent, l *logrus.Entry) error {
 	org, repo := e.GetRepo().GetOrgAndRepo()
 	l = l.WithFields(logrus.Fields{
 		LogFieldOrg:  org,
@@ -193,39 +318,64 @@ func (d *dispatcher) handleReviewCommentEvent(e *sdk.PullRequestReviewCommentEve
 		"url":        e.GetComment().GetHTMLURL(),
 	})
 
-	if err := d.h.reviewCommentEventHandler(e, d.getConfig(), l); err != nil {
-		l.WithError(err).Error()
-	} else {
-		l.Info()
-	}
+	return logResult(l, d.handlersOf(l).reviewCommentEventHandler(e, d.getConfig(), l))
 }
 
 func (d *dispatcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
-	eventType, eventGUID, payload, ok := parseRequest(w, r, d.hmac)
+	eventType, eventGUID, payload, ok := parseRequest(w, r, d.hmac, d.forwardSecrets)
 	if !ok {
 		return
 	}
 
 	evt := eventType
-	if strings.HasPrefix(eventType, sdk.EventCustomToAccess) {
-		evt = sdk.EventCustomToAccess
-		eventType = eventType[10:]
+	fromAtomGit := strings.HasPrefix(eventType, sdk.EventCustomToAccess)
+	if fromAtomGit {
+		eventType = eventType[len(sdk.EventCustomToAccess):]
+
+		// the robot without the access handler handles the webhook delivered by AtomGit
+		// directly, instead of the one forwarded by the access robot.
+		evt = eventType
+		if d.h.accessHandlers != nil {
+			evt = sdk.EventCustomToAccess
+		}
 	}
 
+	// the trace is continued if the event is forwarded by the access robot.
 	l := logrus.WithFields(
 		logrus.Fields{
 			LogFieldEventType: eventType,
 			LogFieldEventId:   eventGUID,
 		},
-	)
+	).WithContext(tracing.ExtractHTTP(context.Background(), r.Header))
+
+	if d.rec != nil {
+		d.rec.record(r.Header, payload, l)
+	}
+
+	// the follower rejects the event before it is recorded as seen, and the sender
+	// retries it, so that it reaches the leader behind the same address.
+	if d.isFollower(l) {
+		http.Error(w, "503 Service Unavailable: the replica is not the leader", http.StatusServiceUnavailable)
+		return
+	}
+
+	if fromAtomGit {
+		http.Error(w, "The request was accepted by access's robot, inform to webhook.", http.StatusOK)
+	}
+
+	if d.isDuplicate(eventGUID, l) {
+		return
+	}
 
 	if err := d.Dispatch(evt, payload, l); err != nil {
 		l.WithError(err).Error()
 	}
 }
 
-func parseRequest(w http.ResponseWriter, r *http.Request, getHmac func() []byte) (eventType string, uuid string, payload []byte, ok bool) {
+func parseRequest(
+	w http.ResponseWriter, r *http.Request, getHmac func() []byte, forwardSecrets func() [][]byte,
+) (eventType string, uuid string, payload []byte, ok bool) {
 	defer func(Body io.ReadCloser) {
 		err := Body.Close()
 		if err != nil {
@@ -258,6 +408,12 @@ func parseRequest(w http.ResponseWriter, r *http.Request, getHmac func() []byte)
 			return
 		}
 
+		// the webhook can't be verified without the secret, it should be forwarded by the access robot.
+		if getHmac == nil {
+			resp(http.StatusForbidden, "403 Forbidden: the webhook of AtomGit is not accepted")
+			return
+		}
+
 		sign := r.Header.Get("X-Hub-Signature-256")
 		if sign == "" || !strings.HasPrefix(sign, "sha256=") {
 			resp(http.StatusForbidden, "403 Forbidden: Missing X-Hub-Signature-256 Header")
@@ -268,14 +424,21 @@ func parseRequest(w http.ResponseWriter, r *http.Request, getHmac func() []byte)
 			resp(http.StatusForbidden, "403 Forbidden: Invalid X-Hub-Signature-256")
 			return
 		}
-
-		resp(http.StatusOK, "The request was accepted by access's robot, inform to webhook.")
-
 	} else {
 		if ua != UserAgentHeader {
 			resp(http.StatusBadRequest, "400 Bad Request: unknown User-Agent Header")
 			return
 		}
+
+		// the delivery is propagated by the access robot, and it may be empty.
+		uuid = r.Header.Get("X-AtomGit-Delivery")
+
+		if forwardSecrets != nil {
+			if err := verifyForwardedRequest(r.Header, eventType, v, forwardSecrets()); err != nil {
+				resp(http.StatusForbidden, "403 Forbidden: "+err.Error())
+				return
+			}
+		}
 	}
 
 	payload = v
@@ -0,0 +1,83 @@
+package framework
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opensourceways/community-robot-lib/config"
+)
+
+func TestServeDirectWebhook(t *testing.T) {
+	secret := []byte("secret")
+
+	bot := &testRobot{}
+	agent := config.NewConfigAgent(bot.NewConfig)
+	d := newDispatcher(bot, &agent, func() []byte { return secret })
+
+	send := func(eventType, ua string, payload []byte) int {
+		req := httptest.NewRequest(http.MethodPost, "/atomgit-hook", bytes.NewReader(payload))
+		req.Header.Set("User-Agent", ua)
+		req.Header.Set("X-AtomGit-Event", eventType)
+		req.Header.Set("X-AtomGit-Delivery", "1")
+		req.Header.Set("X-Hub-Signature-256", "sha256="+payloadSignature(payload, secret))
+
+		w := httptest.NewRecorder()
+		d.ServeHTTP(w, req)
+
+		return w.Code
+	}
+
+	// the robot without the access handler handles the webhook of AtomGit directly.
+	if code := send("issues", "AtomGit-Hookshot", []byte(`{"action":"opened","issue":{"number":1}}`)); code != http.StatusOK {
+		t.Errorf("status = %d", code)
+	}
+
+	// the events which the robot has no handler of are ignored.
+	for _, ua := range []string{"AtomGit-Hookshot", UserAgentHeader} {
+		if code := send("push", ua, []byte(`{"ref":"refs/heads/master"}`)); code != http.StatusOK {
+			t.Errorf("status = %d", code)
+		}
+	}
+
+	d.Wait()
+
+	if len(bot.issues) != 1 || bot.issues[0] != 1 {
+		t.Errorf("issues handled = %v", bot.issues)
+	}
+}
+
+func TestServeWebhookWithoutConfigAndSecret(t *testing.T) {
+	bot := &testRobot{}
+	d := newDispatcher(bot, nil, nil)
+
+	send := func(ua string) int {
+		req := httptest.NewRequest(http.MethodPost, "/atomgit-hook", bytes.NewReader([]byte(`{"action":"opened","issue":{"number":1}}`)))
+		req.Header.Set("User-Agent", ua)
+		req.Header.Set("X-AtomGit-Event", "issues")
+		req.Header.Set("X-AtomGit-Delivery", "1")
+		req.Header.Set("X-Hub-Signature-256", "sha256=any")
+
+		w := httptest.NewRecorder()
+		d.ServeHTTP(w, req)
+
+		return w.Code
+	}
+
+	// the webhook of AtomGit can't be verified without the secret.
+	if code := send("AtomGit-Hookshot"); code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", code, http.StatusForbidden)
+	}
+
+	// the forwarded one is handled with nil config.
+	if code := send(UserAgentHeader); code != http.StatusOK {
+		t.Errorf("status = %d", code)
+	}
+
+	d.Wait()
+
+	if len(bot.issues) != 1 {
+		t.Errorf("issues handled = %v", bot.issues)
+	}
+}
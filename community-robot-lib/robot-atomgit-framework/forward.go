@@ -0,0 +1,73 @@
+package framework
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	headerForwardSignature = "X-AtomGit-Forward-Signature"
+	headerForwardTimestamp = "X-AtomGit-Forward-Timestamp"
+
+	forwardSignaturePrefix = "sha256="
+
+	// forwardMaxSkew is the max difference between the time of signing and verifying,
+	// so that the requests captured can't be replayed later.
+	forwardMaxSkew = 5 * time.Minute
+)
+
+// SignForwardedRequest signs the event forwarded by the access robot to the downstream robots.
+// The signature covers the time of signing, the event type, the delivery and the payload,
+// so the X-AtomGit-Delivery header should be set before signing.
+func SignForwardedRequest(req *http.Request, eventType string, payload, secret []byte) {
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	sign := forwardSignature(secret, ts, eventType, req.Header.Get(headerDelivery), payload)
+
+	req.Header.Set(headerForwardTimestamp, ts)
+	req.Header.Set(headerForwardSignature, forwardSignaturePrefix+sign)
+}
+
+func forwardSignature(secret []byte, ts, eventType, delivery string, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(ts + "\n" + eventType + "\n" + delivery + "\n"))
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyForwardedRequest checks the signature of event forwarded with each of the secrets,
+// so that the previous secret is still accepted during the rotation.
+func verifyForwardedRequest(h http.Header, eventType string, payload []byte, secrets [][]byte) error {
+	sign := h.Get(headerForwardSignature)
+	if !strings.HasPrefix(sign, forwardSignaturePrefix) {
+		return errors.New("missing " + headerForwardSignature + " header")
+	}
+
+	ts := h.Get(headerForwardTimestamp)
+
+	t, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return errors.New("invalid " + headerForwardTimestamp + " header")
+	}
+
+	if d := time.Since(time.Unix(t, 0)); d > forwardMaxSkew || d < -forwardMaxSkew {
+		return errors.New("the signature has expired")
+	}
+
+	sign = sign[len(forwardSignaturePrefix):]
+	delivery := h.Get(headerDelivery)
+
+	for _, secret := range secrets {
+		if len(secret) > 0 && hmac.Equal([]byte(sign), []byte(forwardSignature(secret, ts, eventType, delivery, payload))) {
+			return nil
+		}
+	}
+
+	return errors.New("invalid " + headerForwardSignature + " header")
+}
@@ -0,0 +1,123 @@
+package framework
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/opensourceways/community-robot-lib/config"
+)
+
+func TestForwardedRequest(t *testing.T) {
+	bot := &testRobot{}
+	agent := config.NewConfigAgent(bot.NewConfig)
+	d := newDispatcher(bot, &agent, nil)
+	d.forwardSecrets = func() [][]byte {
+		return [][]byte{[]byte("current"), []byte("previous")}
+	}
+
+	payload := []byte(`{"action":"created","issue":{"number":1}}`)
+
+	newReq := func(eventType string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/atomgit-hook", bytes.NewReader(payload))
+		req.Header.Set("User-Agent", UserAgentHeader)
+		req.Header.Set("X-AtomGit-Event", eventType)
+
+		return req
+	}
+
+	cases := []struct {
+		name string
+		req  func() *http.Request
+		code int
+	}{
+		{
+			name: "unsigned",
+			req:  func() *http.Request { return newReq("issues") },
+			code: http.StatusForbidden,
+		},
+		{
+			name: "current secret",
+			req: func() *http.Request {
+				req := newReq("issues")
+				SignForwardedRequest(req, "issues", payload, []byte("current"))
+
+				return req
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "previous secret",
+			req: func() *http.Request {
+				req := newReq("issues")
+				SignForwardedRequest(req, "issues", payload, []byte("previous"))
+
+				return req
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "unknown secret",
+			req: func() *http.Request {
+				req := newReq("issues")
+				SignForwardedRequest(req, "issues", payload, []byte("unknown"))
+
+				return req
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name: "event type changed",
+			req: func() *http.Request {
+				req := newReq("pull_request")
+				SignForwardedRequest(req, "issues", payload, []byte("current"))
+
+				return req
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name: "delivery changed",
+			req: func() *http.Request {
+				req := newReq("issues")
+				req.Header.Set("X-AtomGit-Delivery", "1")
+				SignForwardedRequest(req, "issues", payload, []byte("current"))
+				req.Header.Set("X-AtomGit-Delivery", "2")
+
+				return req
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name: "expired",
+			req: func() *http.Request {
+				ts := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+
+				req := newReq("issues")
+				req.Header.Set(headerForwardTimestamp, ts)
+				req.Header.Set(headerForwardSignature, forwardSignaturePrefix+forwardSignature([]byte("current"), ts, "issues", "", payload))
+
+				return req
+			},
+			code: http.StatusForbidden,
+		},
+	}
+
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		d.ServeHTTP(w, c.req())
+
+		if w.Code != c.code {
+			t.Errorf("%s: code = %d, want %d, body: %s", c.name, w.Code, c.code, w.Body.String())
+		}
+	}
+
+	d.Wait()
+
+	if len(bot.issues) != 2 {
+		t.Errorf("%d events are handled, want 2", len(bot.issues))
+	}
+}
@@ -36,6 +36,7 @@ type handlers struct {
 	issueCommentHandler       IssueCommentHandler
 	reviewEventHandler        ReviewEventHandler
 	reviewCommentEventHandler ReviewCommentEventHandler
+	jobs                      []*job
 }
 
 // RegisterAccessHandler registers a plugin's github.IssueEvent handler.
@@ -72,3 +73,10 @@ func (h *handlers) RegisterReviewEventHandler(fn ReviewEventHandler) {
 func (h *handlers) RegisterReviewCommentEventHandler(fn ReviewCommentEventHandler) {
 	h.reviewCommentEventHandler = fn
 }
+
+// RegisterJob registers a plugin's periodic job, which runs on the schedule of spec.
+// The spec is a standard cron expression, such as "0 2 * * *", or a descriptor such as
+// "@daily" and "@every 1h".
+func (h *handlers) RegisterJob(name, spec string, fn JobHandler, opts ...JobOption) {
+	h.jobs = append(h.jobs, newJob(name, spec, fn, opts...))
+}
@@ -0,0 +1,220 @@
+package framework
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/opensourceways/community-robot-lib/config"
+)
+
+const (
+	HealthzPath = "/healthz"
+	ReadyzPath  = "/readyz"
+	VersionPath = "/version"
+
+	// DefaultAPIEndpoint is the api of AtomGit checked by APICheck.
+	DefaultAPIEndpoint = "https://api.atomgit.com/"
+
+	apiCheckInterval = 30 * time.Second
+)
+
+// The build info reported at VersionPath, which can be set by -ldflags, for example
+// "-X github.com/opensourceways/community-robot-lib/robot-atomgit-framework.Version=v1.0.0".
+// GitCommit and BuildTime are read from the info embedded by go build if they are not set.
+var (
+	Version   string
+	GitCommit string
+	BuildTime string
+)
+
+// Check checks a dependency of robot, and returns the error if it is unavailable.
+type Check func() error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// WithLivenessCheck adds the check served at HealthzPath and ReadyzPath.
+// The robot should be restarted when a liveness check fails.
+func WithLivenessCheck(name string, c Check) RunOption {
+	return func(o *runOptions) {
+		o.liveness = append(o.liveness, namedCheck{name: name, check: c})
+	}
+}
+
+// WithReadinessCheck adds the check served at ReadyzPath.
+// The robot should not receive the events when a readiness check fails.
+func WithReadinessCheck(name string, c Check) RunOption {
+	return func(o *runOptions) {
+		o.readiness = append(o.readiness, namedCheck{name: name, check: c})
+	}
+}
+
+// ConfigCheck checks that the config is loaded and the last reloading succeeded.
+func ConfigCheck(agent *config.ConfigAgent) Check {
+	return func() error {
+		if _, c := agent.GetConfig(); c == nil {
+			return errors.New("the config is not loaded")
+		}
+
+		if err := agent.LoadError(); err != nil {
+			return fmt.Errorf("failed to reload the config, err: %s", err.Error())
+		}
+
+		return nil
+	}
+}
+
+// SecretCheck checks that the secret is present.
+func SecretCheck(getSecret func() []byte) Check {
+	return func() error {
+		if len(getSecret()) == 0 {
+			return errors.New("the secret is missing")
+		}
+
+		return nil
+	}
+}
+
+// APICheck checks that the api at endpoint is reachable. The server errors fail it, but
+// the client errors such as 401 don't. The result is cached for a while, so that the
+// probes don't call the api frequently.
+func APICheck(endpoint string) Check {
+	hc := http.Client{Timeout: 10 * time.Second}
+
+	var mut sync.Mutex
+	var checkedAt time.Time
+	var lastErr error
+
+	check := func() error {
+		resp, err := hc.Get(endpoint)
+		if err != nil {
+			return err
+		}
+
+		_ = resp.Body.Close()
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("the api responds %s", resp.Status)
+		}
+
+		return nil
+	}
+
+	return func() error {
+		mut.Lock()
+		defer mut.Unlock()
+
+		if time.Since(checkedAt) >= apiCheckInterval {
+			lastErr = check()
+			checkedAt = time.Now()
+		}
+
+		return lastErr
+	}
+}
+
+// QueueDepthCheck checks that the depth of queue is below max.
+func QueueDepthCheck(depth func() int, max int) Check {
+	return func() error {
+		if n := depth(); n >= max {
+			return fmt.Errorf("%d events are pending, the max is %d", n, max)
+		}
+
+		return nil
+	}
+}
+
+type checkResult struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+func serveChecks(checks []namedCheck) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		code := http.StatusOK
+		v := checkResult{Status: "ok", Checks: map[string]string{}}
+
+		for _, c := range checks {
+			if err := c.check(); err != nil {
+				code = http.StatusServiceUnavailable
+				v.Status = "unavailable"
+				v.Checks[c.name] = err.Error()
+			} else {
+				v.Checks[c.name] = "ok"
+			}
+		}
+
+		writeJSON(w, code, &v)
+	}
+}
+
+type versionInfo struct {
+	Version   string `json:"version,omitempty"`
+	GitCommit string `json:"git_commit,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	GoVersion string `json:"go_version,omitempty"`
+	Module    string `json:"module,omitempty"`
+	ConfigMD5 string `json:"config_md5,omitempty"`
+}
+
+func newVersionInfo() versionInfo {
+	v := versionInfo{
+		Version:   Version,
+		GitCommit: GitCommit,
+		BuildTime: BuildTime,
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return v
+	}
+
+	v.GoVersion = info.GoVersion
+	v.Module = info.Main.Path
+
+	if v.Version == "" && info.Main.Version != "(devel)" {
+		v.Version = info.Main.Version
+	}
+
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if v.GitCommit == "" {
+				v.GitCommit = s.Value
+			}
+
+		case "vcs.time":
+			if v.BuildTime == "" {
+				v.BuildTime = s.Value
+			}
+		}
+	}
+
+	return v
+}
+
+// serveVersion reports the build info and the md5 of config being used.
+func serveVersion(agent *config.ConfigAgent) http.HandlerFunc {
+	info := newVersionInfo()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		v := info
+		v.ConfigMD5, _ = agent.GetConfig()
+
+		writeJSON(w, http.StatusOK, &v)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	_ = json.NewEncoder(w).Encode(v)
+}
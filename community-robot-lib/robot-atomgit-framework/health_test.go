@@ -0,0 +1,88 @@
+package framework
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opensourceways/community-robot-lib/config"
+)
+
+type testConfig struct {
+	Name string `json:"name"`
+}
+
+func (c *testConfig) Validate() error {
+	if c.Name == "" {
+		return errors.New("missing name")
+	}
+
+	return nil
+}
+
+func (c *testConfig) SetDefault() {}
+
+func TestConfigCheck(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	agent := config.NewConfigAgent(func() config.Config { return new(testConfig) })
+
+	check := ConfigCheck(&agent)
+	if err := check(); err == nil {
+		t.Error("the check should fail before the config is loaded")
+	}
+
+	if err := os.WriteFile(path, []byte("name: test"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := agent.Start(path); err != nil {
+		t.Fatal(err)
+	}
+	defer agent.Stop()
+
+	if err := check(); err != nil {
+		t.Error(err)
+	}
+
+	w := httptest.NewRecorder()
+	serveVersion(&agent)(w, httptest.NewRequest(http.MethodGet, VersionPath, nil))
+
+	var v versionInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &v); err != nil {
+		t.Fatal(err)
+	}
+
+	if md5, _ := agent.GetConfig(); v.ConfigMD5 != md5 || md5 == "" {
+		t.Errorf("config md5 = %s, want %s", v.ConfigMD5, md5)
+	}
+}
+
+func TestServeChecks(t *testing.T) {
+	depth := 0
+	checks := []namedCheck{
+		{name: "queue", check: QueueDepthCheck(func() int { return depth }, 2)},
+		{name: "secret", check: SecretCheck(func() []byte { return []byte("secret") })},
+	}
+
+	for _, c := range []struct {
+		depth int
+		code  int
+	}{
+		{depth: 1, code: http.StatusOK},
+		{depth: 2, code: http.StatusServiceUnavailable},
+	} {
+		depth = c.depth
+
+		w := httptest.NewRecorder()
+		serveChecks(checks)(w, httptest.NewRequest(http.MethodGet, ReadyzPath, nil))
+
+		if w.Code != c.code {
+			t.Errorf("depth %d: code = %d, want %d, body: %s", c.depth, w.Code, c.code, w.Body.String())
+		}
+	}
+}
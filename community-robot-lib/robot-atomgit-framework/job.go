@@ -0,0 +1,249 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/opensourceways/community-robot-lib/atomgitclient"
+	"github.com/opensourceways/community-robot-lib/config"
+	"github.com/opensourceways/community-robot-lib/tracing"
+)
+
+const (
+	// JobsPath reports the schedule and the statistics of each job.
+	JobsPath = "/jobs"
+
+	logFieldJob = "job"
+)
+
+// JobHandler defines the function contract for a periodic job. The Context of log is
+// done when the robot is shutting down, and the client sends the requests with it.
+type JobHandler func(cfg config.Config, cli atomgitclient.Client, log *logrus.Entry) error
+
+// JobOption customizes a job.
+type JobOption func(*job)
+
+// JobJitter delays each run of job by a random duration up to d, so that the replicas
+// or robots with the same schedule don't call AtomGit at the same time.
+func JobJitter(d time.Duration) JobOption {
+	return func(j *job) {
+		j.jitter = d
+	}
+}
+
+// JobStats is the statistics of a job.
+type JobStats struct {
+	Name     string `json:"name"`
+	Schedule string `json:"schedule"`
+	Running  bool   `json:"running"`
+
+	Runs     int64 `json:"runs"`
+	Failures int64 `json:"failures"`
+	// Skipped is the number of runs skipped because the previous run has not finished.
+	Skipped int64 `json:"skipped"`
+
+	LastStart    time.Time `json:"last_start,omitempty"`
+	LastDuration string    `json:"last_duration,omitempty"`
+	LastError    string    `json:"last_error,omitempty"`
+	NextRun      time.Time `json:"next_run,omitempty"`
+}
+
+type job struct {
+	name     string
+	spec     string
+	h        JobHandler
+	jitter   time.Duration
+	schedule cron.Schedule
+
+	// running is 1 when the job is running, so that the runs don't overlap.
+	running int32
+
+	mut   sync.Mutex
+	stats JobStats
+}
+
+func newJob(name, spec string, h JobHandler, opts ...JobOption) *job {
+	j := &job{name: name, spec: spec, h: h}
+	for _, o := range opts {
+		o(j)
+	}
+
+	j.stats.Name = name
+	j.stats.Schedule = spec
+
+	return j
+}
+
+func (j *job) next(now time.Time) time.Time {
+	t := j.schedule.Next(now)
+	if j.jitter > 0 {
+		t = t.Add(time.Duration(rand.Int63n(int64(j.jitter))))
+	}
+
+	j.mut.Lock()
+	j.stats.NextRun = t
+	j.mut.Unlock()
+
+	return t
+}
+
+func (j *job) getStats() JobStats {
+	j.mut.Lock()
+	defer j.mut.Unlock()
+
+	v := j.stats
+	v.Running = atomic.LoadInt32(&j.running) == 1
+
+	return v
+}
+
+// scheduler runs the jobs registered by robot on their schedules.
+type scheduler struct {
+	jobs      []*job
+	getConfig func() config.Config
+	cli       atomgitclient.Client
+
+	// isLeader reports whether the replica is the leader, only the leader runs
+	// the jobs if it is set
+	isLeader func() bool
+
+	wg sync.WaitGroup
+}
+
+func newScheduler(jobs []*job, getConfig func() config.Config, cli atomgitclient.Client) (*scheduler, error) {
+	names := map[string]bool{}
+
+	for _, j := range jobs {
+		if names[j.name] {
+			return nil, fmt.Errorf("duplicate job: %s", j.name)
+		}
+
+		names[j.name] = true
+
+		s, err := cron.ParseStandard(j.spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule of job:%s, err:%s", j.name, err.Error())
+		}
+
+		j.schedule = s
+	}
+
+	return &scheduler{jobs: jobs, getConfig: getConfig, cli: cli}, nil
+}
+
+// run schedules the jobs until ctx is done, and then waits for the jobs running.
+func (s *scheduler) run(ctx context.Context) {
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+
+		go func(j *job) {
+			defer s.wg.Done()
+
+			s.loop(ctx, j)
+		}(j)
+	}
+
+	s.wg.Wait()
+}
+
+func (s *scheduler) loop(ctx context.Context, j *job) {
+	for {
+		timer := time.NewTimer(time.Until(j.next(time.Now())))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return
+
+		case <-timer.C:
+			s.start(ctx, j)
+		}
+	}
+}
+
+// start runs the job in background unless the previous run has not finished
+// or the replica is not the leader.
+func (s *scheduler) start(ctx context.Context, j *job) {
+	if s.isLeader != nil && !s.isLeader() {
+		logrus.WithField(logFieldJob, j.name).Debug("skip the job, because the replica is not the leader")
+
+		return
+	}
+
+	if !atomic.CompareAndSwapInt32(&j.running, 0, 1) {
+		j.mut.Lock()
+		j.stats.Skipped++
+		j.mut.Unlock()
+
+		logrus.WithField(logFieldJob, j.name).Warn("skip the job, because the previous run has not finished")
+
+		return
+	}
+
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+		defer atomic.StoreInt32(&j.running, 0)
+
+		s.runJob(ctx, j)
+	}()
+}
+
+func (s *scheduler) runJob(ctx context.Context, j *job) {
+	ctx, span := tracing.Start(
+		ctx, "job "+j.name,
+		trace.WithAttributes(attribute.String("job", j.name)),
+	)
+
+	l := logrus.WithField(logFieldJob, j.name).WithContext(ctx)
+
+	start := time.Now()
+
+	err := j.h(s.getConfig(), s.cli.WithContext(ctx), l)
+
+	tracing.End(span, err)
+
+	duration := time.Since(start)
+
+	j.mut.Lock()
+	j.stats.Runs++
+	j.stats.LastStart = start
+	j.stats.LastDuration = duration.String()
+	j.stats.LastError = ""
+
+	if err != nil {
+		j.stats.Failures++
+		j.stats.LastError = err.Error()
+	}
+	j.mut.Unlock()
+
+	l = l.WithField("duration", duration.String())
+
+	if err != nil {
+		l.WithError(err).Error("run the job")
+	} else {
+		l.Info("run the job")
+	}
+}
+
+// serveJobs reports the statistics of jobs.
+func (s *scheduler) serveJobs(w http.ResponseWriter, r *http.Request) {
+	v := make([]JobStats, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		v = append(v, j.getStats())
+	}
+
+	writeJSON(w, http.StatusOK, v)
+}
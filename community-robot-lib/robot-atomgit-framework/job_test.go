@@ -0,0 +1,83 @@
+package framework
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/community-robot-lib/atomgitclient"
+	"github.com/opensourceways/community-robot-lib/config"
+)
+
+// everySchedule runs the job at each interval, which can be shorter than a second.
+type everySchedule time.Duration
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(s))
+}
+
+func TestNewScheduler(t *testing.T) {
+	h := func(config.Config, atomgitclient.Client, *logrus.Entry) error { return nil }
+
+	if _, err := newScheduler([]*job{newJob("a", "@daily", h), newJob("b", "0 2 * * 1-5", h)}, nil, nil); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if _, err := newScheduler([]*job{newJob("a", "0 2 * *", h)}, nil, nil); err == nil {
+		t.Error("the invalid schedule should be rejected")
+	}
+
+	if _, err := newScheduler([]*job{newJob("a", "@daily", h), newJob("a", "@hourly", h)}, nil, nil); err == nil {
+		t.Error("the duplicate jobs should be rejected")
+	}
+}
+
+func TestSchedulerRun(t *testing.T) {
+	cli := atomgitclient.NewClient(func() []byte { return nil })
+
+	release := make(chan struct{})
+	runs := make(chan struct{}, 10)
+
+	slow := newJob("slow", "", func(_ config.Config, _ atomgitclient.Client, log *logrus.Entry) error {
+		runs <- struct{}{}
+
+		// it blocks until released, so that the next runs are skipped.
+		select {
+		case <-release:
+		case <-log.Context.Done():
+		}
+
+		return errors.New("failed")
+	})
+	slow.schedule = everySchedule(5 * time.Millisecond)
+
+	s := &scheduler{jobs: []*job{slow}, getConfig: func() config.Config { return nil }, cli: cli}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		s.run(ctx)
+		close(done)
+	}()
+
+	<-runs
+	time.Sleep(30 * time.Millisecond)
+	close(release)
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout to wait for the scheduler to stop")
+	}
+
+	v := slow.getStats()
+	if v.Runs == 0 || v.Failures != v.Runs || v.LastError != "failed" || v.Skipped == 0 || v.Running {
+		t.Errorf("stats = %+v", v)
+	}
+}
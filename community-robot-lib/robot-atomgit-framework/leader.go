@@ -0,0 +1,230 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/community-robot-lib/options"
+)
+
+const (
+	// LeaderPath reports the state of replica in the leader election.
+	LeaderPath = "/leader"
+
+	// leaseReleaseTimeout is the time to release the lease on shutdown.
+	leaseReleaseTimeout = 5 * time.Second
+)
+
+// LeaseBackend keeps the lease which the replicas of robot compete for. It can be
+// implemented by a shared storage, such as etcd or redis, besides the file and the
+// Lease of kubernetes provided.
+type LeaseBackend interface {
+	// TryAcquire acquires the lease for identity, or renews it if identity holds it.
+	// It returns false if the lease is held by another one and has not expired.
+	TryAcquire(ctx context.Context, identity string, duration time.Duration) (bool, error)
+
+	// Release gives up the lease if identity holds it, so that the others take it at once.
+	Release(ctx context.Context, identity string) error
+}
+
+// WithLeaseBackend makes Run elect the leader by b instead of the backend set by the
+// options of service. It is used when the leader election is enabled.
+func WithLeaseBackend(b LeaseBackend) RunOption {
+	return func(o *runOptions) {
+		o.lease = b
+	}
+}
+
+func (o *runOptions) leaseBackend(opt *options.LeaderElectionOptions) (LeaseBackend, error) {
+	if o.lease != nil {
+		return o.lease, nil
+	}
+
+	if opt.Backend == options.LeaseBackendKubernetes {
+		return NewKubernetesLease(opt.LeaseNamespace, opt.LeaseName)
+	}
+
+	return NewFileLease(opt.LeaseFile), nil
+}
+
+// checkHandleEvents checks whether only the leader can handle the events of message queue.
+// The follower acks them without handling, so each replica must consume all the events
+// instead of sharing them in a group.
+func (o *runOptions) checkHandleEvents() error {
+	if o.mq != nil && o.group != "" {
+		return fmt.Errorf("leader-handle-events requires the broadcast subscription, but the group is %s", o.group)
+	}
+
+	return nil
+}
+
+// LeaderStatus is the state of replica in the leader election.
+type LeaderStatus struct {
+	Identity  string    `json:"identity"`
+	Leader    bool      `json:"leader"`
+	Since     time.Time `json:"since,omitempty"`
+	LastRenew time.Time `json:"last_renew,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// elector keeps trying to acquire the lease, and renews it while being the leader.
+type elector struct {
+	backend  LeaseBackend
+	identity string
+	duration time.Duration
+	period   time.Duration
+
+	mut    sync.RWMutex
+	status LeaderStatus
+}
+
+func newLeaderElector(opt *options.LeaderElectionOptions, ro *runOptions) (*elector, error) {
+	backend, err := ro.leaseBackend(opt)
+	if err != nil {
+		return nil, err
+	}
+
+	return newElector(backend, opt)
+}
+
+func newElector(backend LeaseBackend, opt *options.LeaderElectionOptions) (*elector, error) {
+	identity := opt.Identity
+	if identity == "" {
+		host, err := os.Hostname()
+		if err != nil {
+			return nil, err
+		}
+
+		identity = fmt.Sprintf("%s_%d", host, os.Getpid())
+	}
+
+	return &elector{
+		backend:  backend,
+		identity: identity,
+		duration: opt.LeaseDuration,
+		period:   opt.RetryPeriod,
+		status:   LeaderStatus{Identity: identity},
+	}, nil
+}
+
+// isLeader reports whether the replica holds the lease.
+func (e *elector) isLeader() bool {
+	e.mut.RLock()
+	defer e.mut.RUnlock()
+
+	return e.status.Leader
+}
+
+// run competes for the lease until ctx is done, and then releases it if it is held.
+func (e *elector) run(ctx context.Context) {
+	ticker := time.NewTicker(e.period)
+	defer ticker.Stop()
+
+	for {
+		e.tryAcquire(ctx)
+
+		select {
+		case <-ctx.Done():
+			e.release()
+
+			return
+
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *elector) tryAcquire(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, e.period)
+	defer cancel()
+
+	ok, err := e.backend.TryAcquire(ctx, e.identity, e.duration)
+	now := time.Now()
+
+	e.mut.Lock()
+	defer e.mut.Unlock()
+
+	s := &e.status
+	l := logrus.WithField("identity", e.identity)
+
+	if err != nil {
+		s.LastError = err.Error()
+
+		// the lease may have been taken by the others after it expires, so the leader
+		// steps down before that if it can't renew the lease.
+		if s.Leader && now.Sub(s.LastRenew) >= e.duration-e.period {
+			s.Leader = false
+			l.WithError(err).Error("lose the leadership, because the lease can't be renewed")
+		} else {
+			l.WithError(err).Warn("try to acquire the lease")
+		}
+
+		return
+	}
+
+	s.LastError = ""
+
+	switch {
+	case ok && !s.Leader:
+		s.Leader = true
+		s.Since = now
+		l.Info("become the leader")
+
+	case !ok && s.Leader:
+		s.Leader = false
+		l.Warn("lose the leadership, because the lease is held by another one")
+	}
+
+	if ok {
+		s.LastRenew = now
+	}
+}
+
+func (e *elector) release() {
+	e.mut.Lock()
+	leader := e.status.Leader
+	e.status.Leader = false
+	e.mut.Unlock()
+
+	if !leader {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), leaseReleaseTimeout)
+	defer cancel()
+
+	if err := e.backend.Release(ctx, e.identity); err != nil {
+		logrus.WithError(err).Error("release the lease")
+	} else {
+		logrus.WithField("identity", e.identity).Info("release the lease")
+	}
+}
+
+func (e *elector) getStatus() LeaderStatus {
+	e.mut.RLock()
+	defer e.mut.RUnlock()
+
+	return e.status
+}
+
+// serveLeader reports the state of replica in the leader election.
+func (e *elector) serveLeader(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, e.getStatus())
+}
+
+// isFollower checks whether the event should be rejected or skipped, because only the leader handles it.
+func (d *dispatcher) isFollower(l *logrus.Entry) bool {
+	if d.isLeader == nil || d.isLeader() {
+		return false
+	}
+
+	l.Debug("skip the event, because the replica is not the leader")
+
+	return true
+}
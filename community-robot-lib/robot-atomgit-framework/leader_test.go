@@ -0,0 +1,311 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/community-robot-lib/config"
+	"github.com/opensourceways/community-robot-lib/localmq"
+	"github.com/opensourceways/community-robot-lib/options"
+)
+
+func TestFileLease(t *testing.T) {
+	ctx := context.Background()
+	lease := NewFileLease(filepath.Join(t.TempDir(), "lease"))
+
+	acquire := func(identity string, duration time.Duration, want bool) {
+		t.Helper()
+
+		ok, err := lease.TryAcquire(ctx, identity, duration)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if ok != want {
+			t.Errorf("%s acquires the lease: expected %v, got %v", identity, want, ok)
+		}
+	}
+
+	acquire("a", time.Minute, true)
+	acquire("b", time.Minute, false)
+	// a renews it.
+	acquire("a", time.Millisecond, true)
+
+	time.Sleep(5 * time.Millisecond)
+	// the lease expires.
+	acquire("b", time.Minute, true)
+	acquire("a", time.Minute, false)
+
+	if err := lease.Release(ctx, "a"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	acquire("a", time.Minute, false)
+
+	if err := lease.Release(ctx, "b"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	acquire("a", time.Minute, true)
+}
+
+func TestElectorFailover(t *testing.T) {
+	lease := NewFileLease(filepath.Join(t.TempDir(), "lease"))
+
+	newTestElector := func(identity string) *elector {
+		e, err := newElector(lease, &options.LeaderElectionOptions{
+			Identity:      identity,
+			LeaseDuration: time.Minute,
+			RetryPeriod:   10 * time.Millisecond,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		return e
+	}
+
+	a, b := newTestElector("a"), newTestElector("b")
+
+	ctxA, cancelA := context.WithCancel(context.Background())
+	doneA := make(chan struct{})
+	go func() {
+		a.run(ctxA)
+		close(doneA)
+	}()
+
+	waitFor(t, a.isLeader, "a becomes the leader")
+
+	ctxB, cancelB := context.WithCancel(context.Background())
+	doneB := make(chan struct{})
+	go func() {
+		b.run(ctxB)
+		close(doneB)
+	}()
+
+	// b releases the lease before the lease file is removed.
+	defer func() {
+		cancelB()
+		<-doneB
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	if b.isLeader() {
+		t.Fatal("b should not be the leader while a holds the lease")
+	}
+
+	// b takes over at once when a releases the lease, instead of waiting for it to expire.
+	cancelA()
+	<-doneA
+
+	if a.isLeader() {
+		t.Error("a should not be the leader after shutdown")
+	}
+
+	waitFor(t, b.isLeader, "b becomes the leader")
+
+	if s := b.getStatus(); s.Identity != "b" || s.Since.IsZero() {
+		t.Errorf("Unexpected status: %+v", s)
+	}
+}
+
+func TestSchedulerSkipsFollower(t *testing.T) {
+	j := newJob("j", "", nil)
+	s := &scheduler{isLeader: func() bool { return false }}
+
+	s.start(context.Background(), j)
+	s.wg.Wait()
+
+	if st := j.getStats(); st.Runs != 0 || st.Skipped != 0 {
+		t.Errorf("the follower should not run the job, stats: %+v", st)
+	}
+}
+
+func TestDispatcherSkipsFollower(t *testing.T) {
+	leader := false
+	d := &dispatcher{isLeader: func() bool { return leader }}
+
+	l := logrus.NewEntry(logrus.New())
+	if !d.isFollower(l) {
+		t.Fatal("the follower should skip the event")
+	}
+
+	leader = true
+	if d.isFollower(l) {
+		t.Fatal("the leader should handle the event")
+	}
+}
+
+func TestFollowerRejectsWebhook(t *testing.T) {
+	leader := false
+
+	bot := &testRobot{}
+	agent := config.NewConfigAgent(bot.NewConfig)
+	d := newDispatcher(bot, &agent, func() []byte { return nil })
+	d.isLeader = func() bool { return leader }
+
+	send := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/atomgit-hook", strings.NewReader(`{"action":"opened","issue":{"number":1}}`))
+		req.Header.Set("User-Agent", UserAgentHeader)
+		req.Header.Set("X-AtomGit-Event", "issues")
+		req.Header.Set("X-AtomGit-Delivery", "1")
+
+		w := httptest.NewRecorder()
+		d.ServeHTTP(w, req)
+
+		return w.Code
+	}
+
+	// the sender retries the event rejected by the follower.
+	if code := send(); code != http.StatusServiceUnavailable {
+		t.Errorf("the follower responds %d", code)
+	}
+
+	// the delivery is not recorded as seen by the follower.
+	leader = true
+	if code := send(); code != http.StatusOK {
+		t.Errorf("the leader responds %d", code)
+	}
+
+	d.Wait()
+
+	if len(bot.issues) != 1 {
+		t.Errorf("issues handled = %v", bot.issues)
+	}
+}
+
+func TestHandleEventsRequiresBroadcast(t *testing.T) {
+	var ro runOptions
+	if err := ro.checkHandleEvents(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	ConsumeFrom(localmq.NewMemoryMQ(), "atomgit-events", "")(&ro)
+	if err := ro.checkHandleEvents(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	ConsumeFrom(localmq.NewMemoryMQ(), "atomgit-events", "robot")(&ro)
+	if err := ro.checkHandleEvents(); err == nil {
+		t.Error("the followers in a group should not share the events")
+	}
+}
+
+// fakeKubeLeases serves the leases of coordination.k8s.io with the resource version checked.
+type fakeKubeLeases struct {
+	mut     sync.Mutex
+	lease   *kubeLease
+	version int
+}
+
+func (f *fakeKubeLeases) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var in kubeLease
+	if r.Method != http.MethodGet {
+		v, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(v, &in); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if f.lease == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+	case http.MethodPost:
+		if f.lease != nil {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+	case http.MethodPut:
+		if f.lease == nil || in.Metadata.ResourceVersion != f.lease.Metadata.ResourceVersion {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+	}
+
+	if r.Method != http.MethodGet {
+		f.version++
+		in.Metadata.ResourceVersion = strconv.Itoa(f.version)
+		f.lease = &in
+	}
+
+	_ = json.NewEncoder(w).Encode(f.lease)
+}
+
+func TestKubernetesLease(t *testing.T) {
+	fake := &fakeKubeLeases{}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	newLease := func() *kubernetesLease {
+		return &kubernetesLease{
+			endpoint: srv.URL, namespace: "ns", name: "robot", client: srv.Client(),
+			token: func() ([]byte, error) { return []byte("token\n"), nil },
+		}
+	}
+
+	ctx := context.Background()
+	a, b := newLease(), newLease()
+
+	acquire := func(k *kubernetesLease, identity string, want bool) {
+		t.Helper()
+
+		ok, err := k.TryAcquire(ctx, identity, time.Minute)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if ok != want {
+			t.Errorf("%s acquires the lease: expected %v, got %v", identity, want, ok)
+		}
+	}
+
+	acquire(a, "a", true)
+	acquire(b, "b", false)
+	acquire(a, "a", true)
+
+	if err := a.Release(ctx, "a"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	acquire(b, "b", true)
+
+	if v := fake.lease.Spec; v.holder() != "b" || v.LeaseTransitions == nil || *v.LeaseTransitions != 1 {
+		t.Errorf("Unexpected lease: %+v", v)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool, msg string) {
+	t.Helper()
+
+	for i := 0; i < 200; i++ {
+		if cond() {
+			return
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Fatalf("timeout: %s", msg)
+}
@@ -0,0 +1,161 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// fileLockStale is the age of lock file after which it is regarded as left by a crash.
+	fileLockStale = 10 * time.Second
+
+	fileLockRetry = 50 * time.Millisecond
+)
+
+// leaseRecord is the content of lease file.
+type leaseRecord struct {
+	Holder    string    `json:"holder"`
+	Acquired  time.Time `json:"acquired"`
+	Renewed   time.Time `json:"renewed"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewFileLease returns the lease kept in the file at path, which should be on a storage
+// shared by the replicas, such as a volume mounted to them. The file is updated under
+// the lock of file path.lock, so that the replicas don't acquire the lease at the same time.
+func NewFileLease(path string) LeaseBackend {
+	return &fileLease{path: path}
+}
+
+type fileLease struct {
+	path string
+}
+
+func (f *fileLease) TryAcquire(ctx context.Context, identity string, duration time.Duration) (bool, error) {
+	unlock, err := f.lock(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	r, err := f.read()
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+
+	if r.Holder != identity {
+		if r.Holder != "" && now.Before(r.ExpiresAt) {
+			return false, nil
+		}
+
+		r.Holder = identity
+		r.Acquired = now
+	}
+
+	r.Renewed = now
+	r.ExpiresAt = now.Add(duration)
+
+	return true, f.write(&r)
+}
+
+func (f *fileLease) Release(ctx context.Context, identity string) error {
+	unlock, err := f.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	r, err := f.read()
+	if err != nil || r.Holder != identity {
+		return err
+	}
+
+	return f.write(&leaseRecord{})
+}
+
+func (f *fileLease) read() (leaseRecord, error) {
+	var r leaseRecord
+
+	v, err := os.ReadFile(f.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			err = nil
+		}
+
+		return r, err
+	}
+
+	if len(v) == 0 {
+		return r, nil
+	}
+
+	return r, json.Unmarshal(v, &r)
+}
+
+// write replaces the lease file by renaming, so that it is never read half written.
+func (f *fileLease) write(r *leaseRecord) error {
+	v, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return err
+	}
+
+	if _, err = tmp.Write(v); err == nil {
+		err = tmp.Sync()
+	}
+
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), f.path)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+
+	return err
+}
+
+// lock creates the lock file exclusively, and removes it if it is stale.
+func (f *fileLease) lock(ctx context.Context) (func(), error) {
+	name := f.path + ".lock"
+
+	for {
+		fi, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			_ = fi.Close()
+
+			return func() { _ = os.Remove(name) }, nil
+		}
+
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		if info, err := os.Stat(name); err == nil && time.Since(info.ModTime()) > fileLockStale {
+			_ = os.Remove(name)
+
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+
+		case <-time.After(fileLockRetry):
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package framework
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	kubeServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+	// kubeMicroTime is the format of MicroTime of kubernetes.
+	kubeMicroTime = "2006-01-02T15:04:05.000000Z07:00"
+)
+
+// kubeLease is the Lease of coordination.k8s.io/v1, only the fields used are kept.
+type kubeLease struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name            string `json:"name"`
+		Namespace       string `json:"namespace"`
+		ResourceVersion string `json:"resourceVersion,omitempty"`
+	} `json:"metadata"`
+	Spec kubeLeaseSpec `json:"spec"`
+}
+
+type kubeLeaseSpec struct {
+	HolderIdentity       *string `json:"holderIdentity,omitempty"`
+	LeaseDurationSeconds *int    `json:"leaseDurationSeconds,omitempty"`
+	AcquireTime          *string `json:"acquireTime,omitempty"`
+	RenewTime            *string `json:"renewTime,omitempty"`
+	LeaseTransitions     *int    `json:"leaseTransitions,omitempty"`
+}
+
+func (s *kubeLeaseSpec) holder() string {
+	if s.HolderIdentity == nil {
+		return ""
+	}
+
+	return *s.HolderIdentity
+}
+
+func (s *kubeLeaseSpec) expired(now time.Time) bool {
+	if s.holder() == "" || s.RenewTime == nil || s.LeaseDurationSeconds == nil {
+		return true
+	}
+
+	t, err := time.Parse(kubeMicroTime, *s.RenewTime)
+	if err != nil {
+		return true
+	}
+
+	return !now.Before(t.Add(time.Duration(*s.LeaseDurationSeconds) * time.Second))
+}
+
+// NewKubernetesLease returns the lease kept in the Lease object of kubernetes, which is
+// accessed by the service account of pod. The service account must be allowed to get,
+// create and update the leases of coordination.k8s.io in the namespace. The namespace
+// is the one of pod if it is empty.
+func NewKubernetesLease(namespace, name string) (LeaseBackend, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("not running in kubernetes, KUBERNETES_SERVICE_HOST or KUBERNETES_SERVICE_PORT is missing")
+	}
+
+	if namespace == "" {
+		v, err := os.ReadFile(kubeServiceAccountDir + "/namespace")
+		if err != nil {
+			return nil, fmt.Errorf("read the namespace of pod, err:%s", err.Error())
+		}
+
+		namespace = strings.TrimSpace(string(v))
+	}
+
+	ca, err := os.ReadFile(kubeServiceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, fmt.Errorf("read the ca of kubernetes, err:%s", err.Error())
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("invalid ca of kubernetes")
+	}
+
+	return &kubernetesLease{
+		endpoint:  "https://" + net.JoinHostPort(host, port),
+		namespace: namespace,
+		name:      name,
+		client: &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+		}},
+		// the token is read on each request, because it is rotated by kubelet.
+		token: func() ([]byte, error) {
+			return os.ReadFile(kubeServiceAccountDir + "/token")
+		},
+	}, nil
+}
+
+type kubernetesLease struct {
+	endpoint  string
+	namespace string
+	name      string
+	client    *http.Client
+	token     func() ([]byte, error)
+}
+
+// errKubeConflict means the lease has been changed by another one since it was read.
+var errKubeConflict = errors.New("conflict")
+
+func (k *kubernetesLease) TryAcquire(ctx context.Context, identity string, duration time.Duration) (bool, error) {
+	lease, err := k.get(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	nowStr := now.UTC().Format(kubeMicroTime)
+	seconds := int((duration + time.Second - 1) / time.Second)
+
+	if lease == nil {
+		lease = k.newLease()
+		lease.Spec = kubeLeaseSpec{
+			HolderIdentity:       &identity,
+			LeaseDurationSeconds: &seconds,
+			AcquireTime:          &nowStr,
+			RenewTime:            &nowStr,
+		}
+
+		err = k.save(ctx, http.MethodPost, lease)
+	} else {
+		spec := &lease.Spec
+
+		if spec.holder() != identity {
+			if !spec.expired(now) {
+				return false, nil
+			}
+
+			transitions := 1
+			if spec.LeaseTransitions != nil {
+				transitions += *spec.LeaseTransitions
+			}
+
+			spec.HolderIdentity = &identity
+			spec.AcquireTime = &nowStr
+			spec.LeaseTransitions = &transitions
+		}
+
+		spec.LeaseDurationSeconds = &seconds
+		spec.RenewTime = &nowStr
+
+		err = k.save(ctx, http.MethodPut, lease)
+	}
+
+	if errors.Is(err, errKubeConflict) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (k *kubernetesLease) Release(ctx context.Context, identity string) error {
+	lease, err := k.get(ctx)
+	if err != nil || lease == nil || lease.Spec.holder() != identity {
+		return err
+	}
+
+	lease.Spec.HolderIdentity = nil
+	lease.Spec.AcquireTime = nil
+	lease.Spec.RenewTime = nil
+
+	if err = k.save(ctx, http.MethodPut, lease); errors.Is(err, errKubeConflict) {
+		return nil
+	}
+
+	return err
+}
+
+func (k *kubernetesLease) newLease() *kubeLease {
+	lease := &kubeLease{APIVersion: "coordination.k8s.io/v1", Kind: "Lease"}
+	lease.Metadata.Name = k.name
+	lease.Metadata.Namespace = k.namespace
+
+	return lease
+}
+
+func (k *kubernetesLease) url(withName bool) string {
+	s := fmt.Sprintf("%s/apis/coordination.k8s.io/v1/namespaces/%s/leases", k.endpoint, k.namespace)
+	if withName {
+		s += "/" + k.name
+	}
+
+	return s
+}
+
+// get returns nil if the lease doesn't exist.
+func (k *kubernetesLease) get(ctx context.Context) (*kubeLease, error) {
+	var lease kubeLease
+
+	code, err := k.do(ctx, http.MethodGet, k.url(true), nil, &lease)
+	if code == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &lease, nil
+}
+
+// save creates the lease by POST, or updates it by PUT with the resource version read,
+// so that only one of the replicas which read the same version succeeds.
+func (k *kubernetesLease) save(ctx context.Context, method string, lease *kubeLease) error {
+	body, err := json.Marshal(lease)
+	if err != nil {
+		return err
+	}
+
+	code, err := k.do(ctx, method, k.url(method == http.MethodPut), body, nil)
+	if code == http.StatusConflict {
+		return errKubeConflict
+	}
+
+	return err
+}
+
+func (k *kubernetesLease) do(ctx context.Context, method, url string, body []byte, result interface{}) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	token, err := k.token()
+	if err != nil {
+		return 0, fmt.Errorf("read the token of service account, err:%s", err.Error())
+	}
+
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	v, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("%s %s, status:%d, body:%s", method, url, resp.StatusCode, v)
+	}
+
+	if result != nil {
+		return resp.StatusCode, json.Unmarshal(v, result)
+	}
+
+	return resp.StatusCode, nil
+}
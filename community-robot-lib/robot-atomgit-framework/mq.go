@@ -0,0 +1,134 @@
+package framework
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/community-robot-lib/atomgitclient"
+	"github.com/opensourceways/community-robot-lib/mq"
+	"github.com/opensourceways/community-robot-lib/options"
+	"github.com/opensourceways/community-robot-lib/tracing"
+)
+
+const (
+	headerEventType = "X-AtomGit-Event"
+	headerDelivery  = "X-AtomGit-Delivery"
+)
+
+// NewEventMessage creates the message of event published to MQ. It is keyed by
+// org/repo, so that the events of a repository are consumed in order.
+func NewEventMessage(eventType, delivery string, payload []byte, org, repo string) *mq.Message {
+	m := &mq.Message{
+		Header: map[string]string{
+			headerEventType: eventType,
+			headerDelivery:  delivery,
+		},
+		Body: payload,
+	}
+
+	m.SetMessageKey(org + "/" + repo)
+
+	return m
+}
+
+// RunOption customizes Run.
+type RunOption func(*runOptions)
+
+type runOptions struct {
+	mq      mq.MQ
+	topic   string
+	group   string
+	subOpts []mq.SubscribeOption
+
+	deliveries DeliveryStore
+
+	client atomgitclient.Client
+
+	lease LeaseBackend
+
+	liveness  []namedCheck
+	readiness []namedCheck
+}
+
+// DeduplicateWith makes Run skip the deliveries which have been seen in s, instead of
+// the store in memory created by the options of service.
+func DeduplicateWith(s DeliveryStore) RunOption {
+	return func(o *runOptions) {
+		o.deliveries = s
+	}
+}
+
+// ConsumeFrom makes Run consume the events from topic of m instead of serving the webhook.
+// The robots in the same group share the events, and each one gets all the events if it is empty.
+// The opts such as mq.Retry and mq.DeadLetterTopic customize the subscription.
+func ConsumeFrom(m mq.MQ, topic, group string, opts ...mq.SubscribeOption) RunOption {
+	return func(o *runOptions) {
+		o.mq = m
+		o.topic = topic
+		o.group = group
+		o.subOpts = opts
+	}
+}
+
+// ConsumeByOptions makes Run consume the events from the topic of m set by the options,
+// with the retries and the dead letter topic of them. It does nothing if the message
+// queue is not enabled by the options.
+func ConsumeByOptions(m func() mq.MQ, opt *options.MQOptions) RunOption {
+	if !opt.Enabled() {
+		return func(*runOptions) {}
+	}
+
+	return ConsumeFrom(
+		m(), opt.Topic, opt.Group,
+		mq.Retry(opt.MaxRetries, opt.RetryBackoff, 0),
+		mq.DeadLetterTopic(opt.DeadLetterTopic),
+	)
+}
+
+func (o *runOptions) subscribe(d *dispatcher) (mq.Subscriber, error) {
+	if err := o.mq.Connect(); err != nil {
+		return nil, err
+	}
+
+	opts := o.subOpts
+	if o.group != "" {
+		opts = append([]mq.SubscribeOption{mq.Queue(o.group)}, opts...)
+	}
+
+	s, err := o.mq.Subscribe(o.topic, d.handleMessage, opts...)
+	if err != nil {
+		_ = o.mq.Disconnect()
+
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// handleMessage handles the event of message before returning, so that the next
+// message is consumed after it.
+func (d *dispatcher) handleMessage(e mq.Event) error {
+	msg := e.Message()
+
+	eventType := msg.Header[headerEventType]
+	if eventType == "" {
+		return errors.New("missing the event type of message")
+	}
+
+	l := logrus.WithFields(
+		logrus.Fields{
+			LogFieldEventType: eventType,
+			LogFieldEventId:   msg.Header[headerDelivery],
+		},
+	).WithContext(tracing.ExtractMessage(context.Background(), msg))
+
+	// the leader gets the event too, because each replica consumes all the events
+	// when only the leader handles them.
+	if d.isFollower(l) || d.isDuplicate(msg.Header[headerDelivery], l) {
+		return nil
+	}
+
+	return d.dispatchSync(eventType, msg.Body, l)
+}
@@ -0,0 +1,179 @@
+package framework
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opensourceways/go-atomgit/atomgit"
+	"github.com/sirupsen/logrus"
+
+	"github.com/opensourceways/community-robot-lib/config"
+	"github.com/opensourceways/community-robot-lib/localmq"
+	"github.com/opensourceways/community-robot-lib/mq"
+)
+
+type testRobot struct {
+	mut    sync.Mutex
+	issues []int
+}
+
+func (bot *testRobot) NewConfig() config.Config {
+	return nil
+}
+
+func (bot *testRobot) RegisterEventHandler(f HandlerRegister) {
+	f.RegisterIssueHandler(func(e *atomgit.IssuesEvent, _ config.Config, _ *logrus.Entry) error {
+		bot.mut.Lock()
+		bot.issues = append(bot.issues, e.GetIssue().GetNumber())
+		bot.mut.Unlock()
+
+		return nil
+	})
+}
+
+type testEvent struct {
+	m *mq.Message
+}
+
+func (e *testEvent) Topic() string                 { return "events" }
+func (e *testEvent) Message() *mq.Message          { return e.m }
+func (e *testEvent) Ack() error                    { return nil }
+func (e *testEvent) Error() error                  { return nil }
+func (e *testEvent) Extra() map[string]interface{} { return nil }
+
+func TestHandleMessage(t *testing.T) {
+	bot := &testRobot{}
+	agent := config.NewConfigAgent(bot.NewConfig)
+	d := newDispatcher(bot, &agent, nil)
+
+	for _, n := range []string{"1", "2", "3"} {
+		msg := NewEventMessage("issues", "delivery-"+n, []byte(`{"action":"created","issue":{"number":`+n+`}}`), "openeuler", "community")
+
+		if msg.MessageKey() != "openeuler/community" {
+			t.Fatalf("the message should be keyed by org/repo, got %s", msg.MessageKey())
+		}
+
+		if err := d.handleMessage(&testEvent{m: msg}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the messages are handled one by one.
+	if len(bot.issues) != 3 || bot.issues[0] != 1 || bot.issues[2] != 3 {
+		t.Errorf("issues handled = %v", bot.issues)
+	}
+
+	if err := d.handleMessage(&testEvent{m: &mq.Message{Body: []byte("{}")}}); err == nil {
+		t.Error("the message without event type should be rejected")
+	}
+}
+
+func TestConsumeFrom(t *testing.T) {
+	m := localmq.NewMemoryMQ()
+
+	bot := &testRobot{}
+	agent := config.NewConfigAgent(bot.NewConfig)
+	d := newDispatcher(bot, &agent, nil)
+
+	var ro runOptions
+	ConsumeFrom(m, "atomgit-events", "robot")(&ro)
+
+	s, err := ro.subscribe(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Disconnect()
+
+	for _, n := range []string{"1", "2"} {
+		msg := NewEventMessage("issues", "delivery-"+n, []byte(`{"action":"created","issue":{"number":`+n+`}}`), "openeuler", "community")
+		if err := m.Publish("atomgit-events", msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 0; i < 50; i++ {
+		bot.mut.Lock()
+		n := len(bot.issues)
+		bot.mut.Unlock()
+
+		if n == 2 {
+			break
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	_ = s.Unsubscribe()
+
+	if len(bot.issues) != 2 || bot.issues[0] != 1 || bot.issues[1] != 2 {
+		t.Errorf("issues handled = %v", bot.issues)
+	}
+}
+
+// failingRobot fails to handle the issue event for the first failures times.
+type failingRobot struct {
+	failures int32
+	calls    int32
+}
+
+func (bot *failingRobot) NewConfig() config.Config {
+	return nil
+}
+
+func (bot *failingRobot) RegisterEventHandler(f HandlerRegister) {
+	f.RegisterIssueHandler(func(*atomgit.IssuesEvent, config.Config, *logrus.Entry) error {
+		if atomic.AddInt32(&bot.calls, 1) > bot.failures {
+			return nil
+		}
+
+		return errors.New("failed")
+	})
+}
+
+func TestConsumeFromDeadLetter(t *testing.T) {
+	m := localmq.NewMemoryMQ()
+
+	bot := &failingRobot{failures: 10}
+	agent := config.NewConfigAgent(bot.NewConfig)
+	d := newDispatcher(bot, &agent, nil)
+
+	var ro runOptions
+	ConsumeFrom(m, "atomgit-events", "robot", mq.Retry(2, time.Millisecond, 0), mq.DeadLetterTopic("dead"))(&ro)
+
+	if _, err := ro.subscribe(d); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Disconnect()
+
+	dead := make(chan *mq.Message, 1)
+	if _, err := m.Subscribe("dead", func(e mq.Event) error {
+		dead <- e.Message()
+
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := NewEventMessage("issues", "delivery-1", []byte(`{"action":"created","issue":{"number":1}}`), "openeuler", "community")
+	if err := m.Publish("atomgit-events", msg); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case v := <-dead:
+		if v.Header[mq.HeaderRetryCount] != "2" || !strings.HasSuffix(v.Header[mq.HeaderError], "failed") {
+			t.Errorf("header of dead letter = %v", v.Header)
+		}
+
+	case <-time.After(5 * time.Second):
+		t.Fatal("the event which keeps failing should be dead-lettered")
+	}
+
+	if n := atomic.LoadInt32(&bot.calls); n != 3 {
+		t.Errorf("the handler should be called 3 times, got %d", n)
+	}
+}
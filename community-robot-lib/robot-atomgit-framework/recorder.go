@@ -0,0 +1,118 @@
+package framework
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const fixtureFileSuffix = ".json"
+
+// recordedHeaders are the headers of webhook saved in the fixture.
+// The others, such as the signature, are dropped.
+var recordedHeaders = []string{
+	"Content-Type",
+	"User-Agent",
+	"X-AtomGit-Event",
+	"X-AtomGit-Delivery",
+}
+
+// Fixture is a webhook recorded by the dispatcher.
+type Fixture struct {
+	Header  map[string]string `json:"header"`
+	Payload json.RawMessage   `json:"payload"`
+}
+
+// EventType returns the event type of webhook.
+func (f *Fixture) EventType() string {
+	return f.Header["X-AtomGit-Event"]
+}
+
+// LoadFixtures loads the fixtures under dir in the order they were recorded.
+func LoadFixtures(dir string) ([]Fixture, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), fixtureFileSuffix) {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	r := make([]Fixture, len(names))
+	for i, name := range names {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(b, &r[i]); err != nil {
+			return nil, fmt.Errorf("load fixture %s, err:%s", name, err.Error())
+		}
+	}
+
+	return r, nil
+}
+
+type recorder struct {
+	dir    string
+	censor func([]byte) []byte
+	seq    uint64
+}
+
+func newRecorder(dir string, censor func([]byte) []byte) (*recorder, error) {
+	if censor == nil {
+		return nil, fmt.Errorf("missing censor for recording webhooks")
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &recorder{dir: dir, censor: censor}, nil
+}
+
+func (rc *recorder) record(header http.Header, payload []byte, l *logrus.Entry) {
+	f := Fixture{
+		Header:  make(map[string]string, len(recordedHeaders)),
+		Payload: rc.censor(payload),
+	}
+
+	for _, k := range recordedHeaders {
+		if v := header.Get(k); v != "" {
+			f.Header[k] = string(rc.censor([]byte(v)))
+		}
+	}
+
+	b, err := json.MarshalIndent(&f, "", "  ")
+	if err != nil {
+		l.WithError(err).Error("marshal fixture")
+
+		return
+	}
+
+	// the name keeps the order of webhooks when the fixtures are sorted.
+	name := fmt.Sprintf(
+		"%s-%06d-%s%s",
+		time.Now().UTC().Format("20060102T150405.000000000"),
+		atomic.AddUint64(&rc.seq, 1),
+		strings.ReplaceAll(f.EventType(), string(filepath.Separator), "_"),
+		fixtureFileSuffix,
+	)
+
+	if err := os.WriteFile(filepath.Join(rc.dir, name), b, 0o644); err != nil {
+		l.WithError(err).Error("write fixture")
+	}
+}
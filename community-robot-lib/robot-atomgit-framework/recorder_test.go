@@ -0,0 +1,55 @@
+package framework
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestRecorder(t *testing.T) {
+	dir := t.TempDir()
+
+	rec, err := newRecorder(dir, func(b []byte) []byte {
+		return bytes.ReplaceAll(b, []byte("s3cr3t"), []byte("CENSORED"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := logrus.NewEntry(logrus.New())
+
+	for _, evt := range []string{"pull_request", "issue_comment"} {
+		h := http.Header{}
+		h.Set("X-AtomGit-Event", evt)
+		h.Set("X-AtomGit-Delivery", "delivery-s3cr3t")
+		h.Set("X-Hub-Signature-256", "sha256=abc")
+
+		rec.record(h, []byte(`{"token":"s3cr3t"}`), l)
+	}
+
+	fixtures, err := LoadFixtures(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fixtures) != 2 {
+		t.Fatalf("got %d fixtures, want 2", len(fixtures))
+	}
+
+	if v := fixtures[0].EventType(); v != "pull_request" {
+		t.Errorf("the first fixture is %s, want pull_request", v)
+	}
+
+	for _, f := range fixtures {
+		if _, ok := f.Header["X-Hub-Signature-256"]; ok {
+			t.Error("signature should not be recorded")
+		}
+
+		if strings.Contains(f.Header["X-AtomGit-Delivery"]+string(f.Payload), "s3cr3t") {
+			t.Errorf("secret is not censored: %v, %s", f.Header, f.Payload)
+		}
+	}
+}
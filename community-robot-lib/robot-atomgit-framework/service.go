@@ -1,14 +1,20 @@
 package framework
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/opensourceways/community-robot-lib/atomgitclient"
 	"github.com/opensourceways/community-robot-lib/config"
 	"github.com/opensourceways/community-robot-lib/interrupts"
+	"github.com/opensourceways/community-robot-lib/mq"
 	"github.com/opensourceways/community-robot-lib/options"
+	"github.com/opensourceways/community-robot-lib/tracing"
 )
 
 type HandlerRegister interface {
@@ -19,6 +25,7 @@ type HandlerRegister interface {
 	RegisterIssueCommentHandler(IssueCommentHandler)
 	RegisterReviewEventHandler(ReviewEventHandler)
 	RegisterReviewCommentEventHandler(ReviewCommentEventHandler)
+	RegisterJob(name, spec string, fn JobHandler, opts ...JobOption)
 }
 
 type Robot interface {
@@ -26,32 +33,331 @@ type Robot interface {
 	RegisterEventHandler(HandlerRegister)
 }
 
-func Run(bot Robot, servOpt options.ServiceOptions, atomgitOpt options.AtomGitOptions) {
+// EndpointRobot is the robot which serves other endpoints besides the webhook.
+// The handlers should read the config by getConfig, because it is reloaded periodically.
+type EndpointRobot interface {
+	Robot
+	RegisterEndpoints(mux *http.ServeMux, getConfig func() config.Config)
+}
+
+// ContextRobot is the robot whose handlers are called on the copy of it returned by
+// WithContext for each event, so that they send the requests of AtomGit in the span of
+// event. WithContext generally binds the client of robot to ctx by BindClient.
+type ContextRobot interface {
+	Robot
+	WithContext(ctx context.Context) Robot
+}
+
+// WebhookHandler serves the webhook delivered to robot.
+type WebhookHandler interface {
+	http.Handler
+
+	// Wait blocks until all the events received have been handled.
+	Wait()
+}
+
+// WebhookOption customizes the handler created by NewWebhookHandler.
+type WebhookOption func(*dispatcher)
+
+// VerifyForwardedBy makes the handler verify the events forwarded by the access robot
+// with the secrets, as Run does with the forward secrets of service.
+func VerifyForwardedBy(secrets func() [][]byte) WebhookOption {
+	return func(d *dispatcher) {
+		d.forwardSecrets = secrets
+	}
+}
+
+// NewWebhookHandler creates the handler which dispatches the webhook to the handlers of bot.
+// It is used to serve a robot in-process, for example in the end-to-end tests. The handlers
+// get nil config if agent is nil, and the webhook of AtomGit is rejected if hmac is nil.
+func NewWebhookHandler(bot Robot, agent *config.ConfigAgent, hmac func() []byte, opts ...WebhookOption) WebhookHandler {
+	d := newDispatcher(bot, agent, hmac)
+	for _, o := range opts {
+		o(d)
+	}
+
+	return d
+}
+
+func newDispatcher(bot Robot, agent *config.ConfigAgent, hmac func() []byte) *dispatcher {
+	h := handlers{}
+	bot.RegisterEventHandler(&h)
+
+	return &dispatcher{agent: agent, bot: bot, h: h, hmac: hmac}
+}
+
+const (
+	// DryRunPath is the page which lists the calls recorded in dry-run mode.
+	DryRunPath = "/dry-run"
+
+	// tracingShutdownTimeout is the time to export the remaining spans on shutdown.
+	tracingShutdownTimeout = 10 * time.Second
+)
+
+// WrapClient returns the client which the robot should use. In dry-run mode, the calls
+// which change AtomGit are recorded instead of executed, and they are listed at DryRunPath.
+func WrapClient(c atomgitclient.Client, servOpt options.ServiceOptions) atomgitclient.Client {
+	if !servOpt.DryRun {
+		return c
+	}
+
+	logrus.Warn("run in dry-run mode, nothing will be changed on AtomGit")
+
+	dc := atomgitclient.NewDryRunClient(c)
+	http.Handle(DryRunPath, dc)
+
+	return dc
+}
+
+// WithClient sets the client used by the jobs and the admin api, which is generally the
+// one returned by WrapClient. A client is created by the token generator of AtomGit
+// options if it is not set.
+func WithClient(cli atomgitclient.Client) RunOption {
+	return func(o *runOptions) {
+		o.client = cli
+	}
+}
+
+// atomgitClient returns the client set by WithClient, or creates one by the token generator
+// of AtomGit options. It returns an error if neither is set, instead of creating the client
+// which panics on the first request.
+func (o *runOptions) atomgitClient(servOpt options.ServiceOptions, atomgitOpt options.AtomGitOptions) (atomgitclient.Client, error) {
+	if o.client != nil {
+		return o.client, nil
+	}
+
+	if atomgitOpt.TokenGenerator == nil {
+		return nil, errors.New("the client of AtomGit is missing, set it by WithClient")
+	}
+
+	cli := atomgitclient.NewClient(atomgitOpt.TokenGenerator)
+	if servOpt.DryRun {
+		return atomgitclient.NewDryRunClient(cli), nil
+	}
+
+	return cli, nil
+}
+
+// BindClient returns the client which sends the requests with ctx, so that they are traced
+// as the children of the span handling the event. c is the client of AtomGit, or the subset
+// of it which the robot uses. It returns c if c can't be bound to a context.
+func BindClient[C any](c C, ctx context.Context) C {
+	b, ok := any(c).(interface {
+		WithContext(context.Context) atomgitclient.Client
+	})
+	if !ok || ctx == nil {
+		return c
+	}
+
+	if v, ok := b.WithContext(ctx).(C); ok {
+		return v
+	}
+
+	return c
+}
+
+func Run(bot Robot, servOpt options.ServiceOptions, atomgitOpt options.AtomGitOptions, opts ...RunOption) {
+	var ro runOptions
+	for _, o := range opts {
+		o(&ro)
+	}
+
 	agent := config.NewConfigAgent(bot.NewConfig)
 	if err := agent.Start(servOpt.ConfigFile); err != nil {
 		logrus.WithError(err).Errorf("start config:%s", servOpt.ConfigFile)
 		return
 	}
 
-	h := handlers{}
-	bot.RegisterEventHandler(&h)
+	shutdownTracing, err := tracing.Init(servOpt.Tracing)
+	if err != nil {
+		agent.Stop()
+		logrus.WithError(err).Errorf("start tracing:%s", servOpt.Tracing.Exporter)
+		return
+	}
+
+	d := newDispatcher(bot, &agent, atomgitOpt.TokenGenerator)
+
+	if servOpt.ForwardSecrets != nil {
+		d.forwardSecrets = servOpt.ForwardSecrets
+	} else {
+		logrus.Warn("the events forwarded by the access robot are not verified, set the forward secret to do it")
+	}
+
+	if ro.deliveries != nil {
+		d.deliveries = ro.deliveries
+	} else if servOpt.DedupWindow > 0 {
+		d.deliveries = NewMemoryDeliveryStore(servOpt.DedupSize, servOpt.DedupWindow)
+	}
+
+	if servOpt.RecordDir != "" {
+		rec, err := newRecorder(servOpt.RecordDir, servOpt.Censor)
+		if err != nil {
+			agent.Stop()
+			logrus.WithError(err).Errorf("start recorder:%s", servOpt.RecordDir)
+			return
+		}
+
+		d.rec = rec
+	}
+
+	// the jobs and the admin api use the client of AtomGit.
+	var cli atomgitclient.Client
+	if len(d.h.jobs) > 0 || servOpt.AdminToken != nil {
+		if cli, err = ro.atomgitClient(servOpt, atomgitOpt); err != nil {
+			agent.Stop()
+			logrus.WithError(err).Error("start jobs or admin api")
+			return
+		}
+	}
+
+	var jobs *scheduler
+	if len(d.h.jobs) > 0 {
+		s, err := newScheduler(d.h.jobs, d.getConfig, cli)
+		if err != nil {
+			agent.Stop()
+			logrus.WithError(err).Error("start jobs")
+			return
+		}
 
-	d := &dispatcher{agent: &agent, h: h, hmac: atomgitOpt.TokenGenerator}
+		jobs = s
+	}
+
+	var leader *elector
+	if opt := &servOpt.LeaderElection; opt.Enabled {
+		e, err := newLeaderElector(opt, &ro)
+		if err != nil {
+			agent.Stop()
+			logrus.WithError(err).Errorf("start leader election:%s", opt.Backend)
+			return
+		}
+
+		leader = e
+
+		if jobs != nil {
+			jobs.isLeader = leader.isLeader
+		}
+
+		if opt.HandleEvents {
+			if err := ro.checkHandleEvents(); err != nil {
+				agent.Stop()
+				logrus.WithError(err).Error("start leader election")
+				return
+			}
+
+			d.isLeader = leader.isLeader
+		}
+	}
+
+	var sub mq.Subscriber
+	if ro.mq != nil {
+		s, err := ro.subscribe(d)
+		if err != nil {
+			agent.Stop()
+			logrus.WithError(err).Errorf("subscribe topic:%s", ro.topic)
+			return
+		}
+
+		sub = s
+	}
 
 	defer interrupts.WaitForGracefulShutdown()
 
 	interrupts.OnInterrupt(func() {
+		if sub != nil {
+			if err := sub.Unsubscribe(); err != nil {
+				logrus.WithError(err).Error("unsubscribe")
+			}
+
+			_ = ro.mq.Disconnect()
+		}
+
 		agent.Stop()
 		d.Wait()
+
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+
+		if err := shutdownTracing(ctx); err != nil {
+			logrus.WithError(err).Error("shutdown tracing")
+		}
 	})
 
+	// the lease is released on shutdown, so that another replica takes over at once.
+	if leader != nil {
+		interrupts.Run(leader.run)
+		http.HandleFunc(LeaderPath, leader.serveLeader)
+	}
+
+	// the jobs running are waited for on shutdown.
+	if jobs != nil {
+		interrupts.Run(jobs.run)
+		http.HandleFunc(JobsPath, jobs.serveJobs)
+	}
+
+	// it is kept for the probes configured before, use HealthzPath instead.
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		// service's healthy check, do nothing
 	})
 
-	http.Handle("/atomgit-hook", d)
+	registerHealthEndpoints(d, &agent, servOpt, atomgitOpt, &ro)
+
+	if servOpt.AdminToken != nil {
+		registerAdminEndpoints(http.DefaultServeMux, d, cli, servOpt.AdminToken)
+	}
+
+	// the events are consumed from MQ instead of the webhook.
+	if sub == nil {
+		http.Handle("/atomgit-hook", d)
+	}
+
+	if v, ok := bot.(EndpointRobot); ok {
+		v.RegisterEndpoints(http.DefaultServeMux, func() config.Config {
+			_, c := agent.GetConfig()
+
+			return c
+		})
+	}
 
 	httpServer := &http.Server{Addr: ":" + strconv.Itoa(servOpt.Port)}
 
 	interrupts.ListenAndServe(httpServer, servOpt.GracePeriod)
 }
+
+// registerHealthEndpoints serves the checks of the dependencies of robot and the build info.
+func registerHealthEndpoints(
+	d *dispatcher, agent *config.ConfigAgent,
+	servOpt options.ServiceOptions, atomgitOpt options.AtomGitOptions, ro *runOptions,
+) {
+	readiness := []namedCheck{{name: "config", check: ConfigCheck(agent)}}
+
+	if atomgitOpt.TokenGenerator != nil {
+		readiness = append(readiness, namedCheck{name: "token", check: SecretCheck(atomgitOpt.TokenGenerator)})
+	}
+
+	if servOpt.ForwardSecrets != nil {
+		readiness = append(readiness, namedCheck{name: "forward-secret", check: func() error {
+			if len(servOpt.ForwardSecrets()) == 0 {
+				return errors.New("the forward secret is missing")
+			}
+
+			return nil
+		}})
+	}
+
+	if servOpt.MaxPendingEvents > 0 {
+		readiness = append(readiness, namedCheck{
+			name: "events", check: QueueDepthCheck(d.pendingEvents, servOpt.MaxPendingEvents),
+		})
+	}
+
+	if servOpt.CheckAPI {
+		readiness = append(readiness, namedCheck{name: "atomgit-api", check: APICheck(DefaultAPIEndpoint)})
+	}
+
+	liveness := ro.liveness
+	readiness = append(append(readiness, liveness...), ro.readiness...)
+
+	http.Handle(HealthzPath, serveChecks(liveness))
+	http.Handle(ReadyzPath, serveChecks(readiness))
+	http.Handle(VersionPath, serveVersion(agent))
+}
@@ -0,0 +1,128 @@
+package framework
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opensourceways/go-atomgit/atomgit"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/opensourceways/community-robot-lib/atomgitclient"
+	"github.com/opensourceways/community-robot-lib/config"
+)
+
+// traceRobot records the trace of issue events handled.
+type traceRobot struct {
+	traceID string
+}
+
+func (bot *traceRobot) NewConfig() config.Config {
+	return nil
+}
+
+func (bot *traceRobot) RegisterEventHandler(f HandlerRegister) {
+	f.RegisterIssueHandler(func(_ *atomgit.IssuesEvent, _ config.Config, log *logrus.Entry) error {
+		bot.traceID = trace.SpanContextFromContext(log.Context).TraceID().String()
+
+		return nil
+	})
+}
+
+func TestHandleMessageContinuesTrace(t *testing.T) {
+	bot := &traceRobot{}
+	agent := config.NewConfigAgent(bot.NewConfig)
+	d := newDispatcher(bot, &agent, nil)
+
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	msg := NewEventMessage("issues", "delivery", []byte(`{"action":"created","issue":{"number":1}}`), "openeuler", "community")
+	msg.Header["traceparent"] = "00-" + traceID + "-00f067aa0ba902b7-01"
+
+	if err := d.handleMessage(&testEvent{m: msg}); err != nil {
+		t.Fatal(err)
+	}
+
+	if bot.traceID != traceID {
+		t.Errorf("the trace of handler is %s, want %s", bot.traceID, traceID)
+	}
+}
+
+// ctxClient records the context which it is bound to.
+type ctxClient struct {
+	atomgitclient.Client
+	ctx context.Context
+}
+
+func (c *ctxClient) WithContext(ctx context.Context) atomgitclient.Client {
+	return &ctxClient{ctx: ctx}
+}
+
+// contextRobot records the trace of client which its issue handler uses.
+type contextRobot struct {
+	cli     interface{}
+	traceID *string
+}
+
+func (bot *contextRobot) NewConfig() config.Config {
+	return nil
+}
+
+func (bot *contextRobot) WithContext(ctx context.Context) Robot {
+	r := *bot
+	r.cli = BindClient(bot.cli, ctx)
+
+	return &r
+}
+
+func (bot *contextRobot) RegisterEventHandler(f HandlerRegister) {
+	f.RegisterIssueHandler(func(*atomgit.IssuesEvent, config.Config, *logrus.Entry) error {
+		if c, ok := bot.cli.(*ctxClient); ok && c.ctx != nil {
+			*bot.traceID = trace.SpanContextFromContext(c.ctx).TraceID().String()
+		}
+
+		return nil
+	})
+}
+
+func TestDispatchToBoundRobot(t *testing.T) {
+	var got string
+
+	bot := &contextRobot{cli: &ctxClient{}, traceID: &got}
+	agent := config.NewConfigAgent(bot.NewConfig)
+	d := newDispatcher(bot, &agent, nil)
+
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	msg := NewEventMessage("issues", "delivery", []byte(`{"action":"created","issue":{"number":1}}`), "openeuler", "community")
+	msg.Header["traceparent"] = "00-" + traceID + "-00f067aa0ba902b7-01"
+
+	if err := d.handleMessage(&testEvent{m: msg}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got != traceID {
+		t.Errorf("the trace of client is %q, want %s", got, traceID)
+	}
+}
+
+func TestBindClient(t *testing.T) {
+	type iClient interface {
+		WithContext(context.Context) atomgitclient.Client
+	}
+
+	ctx := context.WithValue(context.Background(), struct{}{}, "event")
+	c := BindClient[iClient](&ctxClient{}, ctx)
+
+	if v := c.(*ctxClient); v.ctx != ctx {
+		t.Error("the client should be bound to the context of event")
+	}
+
+	// the client without WithContext, such as the fake one, is returned as it is.
+	type fakeClient interface{}
+
+	fake := &struct{ name string }{"fake"}
+	if v := BindClient[fakeClient](fake, ctx); v != fake {
+		t.Error("the client should be returned as it is")
+	}
+}
@@ -109,6 +109,22 @@ func (a *Agent) GetTokenGenerator(secretPath string) func() []byte {
 	}
 }
 
+// GetSecretsGenerator returns a function that gets the values of the given secrets
+// which are not empty, in the order of paths.
+func (a *Agent) GetSecretsGenerator(secretPaths ...string) func() [][]byte {
+	return func() [][]byte {
+		r := make([][]byte, 0, len(secretPaths))
+
+		for _, p := range secretPaths {
+			if v := a.GetSecret(p); len(v) > 0 {
+				r = append(r, v)
+			}
+		}
+
+		return r
+	}
+}
+
 const censored = "CENSORED"
 
 var censoredBytes = []byte(censored)
@@ -0,0 +1,38 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/opensourceways/community-robot-lib/mq"
+)
+
+// propagator carries the trace context in the W3C traceparent header. It is used
+// even if the tracing of robot is disabled, so that the traces are not broken by it.
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// InjectHTTP sets the trace context of ctx to the header of request.
+func InjectHTTP(ctx context.Context, h http.Header) {
+	propagator.Inject(ctx, propagation.HeaderCarrier(h))
+}
+
+// ExtractHTTP returns the context with the trace context in the header of request.
+func ExtractHTTP(ctx context.Context, h http.Header) context.Context {
+	return propagator.Extract(ctx, propagation.HeaderCarrier(h))
+}
+
+// InjectMessage sets the trace context of ctx to the header of message.
+func InjectMessage(ctx context.Context, m *mq.Message) {
+	if m.Header == nil {
+		m.Header = map[string]string{}
+	}
+
+	propagator.Inject(ctx, propagation.MapCarrier(m.Header))
+}
+
+// ExtractMessage returns the context with the trace context in the header of message.
+func ExtractMessage(ctx context.Context, m *mq.Message) context.Context {
+	return propagator.Extract(ctx, propagation.MapCarrier(m.Header))
+}
@@ -0,0 +1,106 @@
+// Package tracing exports the traces of events handled by the robots, and propagates
+// the trace context over the forwarded requests and the messages of MQ.
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/opensourceways/community-robot-lib/options"
+)
+
+const instrumentationName = "github.com/opensourceways/community-robot-lib/tracing"
+
+// Init sets the global tracer provider which exports the spans as o specified.
+// The returned function flushes the spans and stops exporting, it should be called
+// before the robot exits. Nothing is exported if tracing is not enabled, but the trace
+// context received is still propagated.
+func Init(o options.TracingOptions) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagator)
+
+	if !o.Enabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, closeFile, err := newExporter(o)
+	if err != nil {
+		return nil, err
+	}
+
+	name := o.ServiceName
+	if name == "" {
+		name = filepath.Base(os.Args[0])
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(o.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", name))),
+	)
+
+	otel.SetTracerProvider(tp)
+
+	return func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+
+		if closeFile != nil {
+			if e := closeFile(); err == nil {
+				err = e
+			}
+		}
+
+		return err
+	}, nil
+}
+
+func newExporter(o options.TracingOptions) (sdktrace.SpanExporter, func() error, error) {
+	if o.Exporter == options.TraceExporterFile {
+		f, err := os.OpenFile(o.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			_ = f.Close()
+
+			return nil, nil, err
+		}
+
+		return exporter, f.Close, nil
+	}
+
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(o.OTLPEndpoint)}
+	if o.OTLPInsecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+
+	return exporter, nil, err
+}
+
+// Start starts a span as the child of the span in ctx.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// End ends the span, and marks it failed if err is not nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -0,0 +1,92 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/opensourceways/community-robot-lib/mq"
+)
+
+func newRecorder(t *testing.T) *tracetest.SpanRecorder {
+	r := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(r))
+
+	old := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() { otel.SetTracerProvider(old) })
+
+	return r
+}
+
+func TestMessagePropagation(t *testing.T) {
+	newRecorder(t)
+
+	ctx, span := Start(context.Background(), "publish")
+	defer span.End()
+
+	m := &mq.Message{}
+	InjectMessage(ctx, m)
+
+	got := trace.SpanContextFromContext(ExtractMessage(context.Background(), m))
+	if got.TraceID() != span.SpanContext().TraceID() || got.SpanID() != span.SpanContext().SpanID() {
+		t.Errorf("the trace extracted from %v is %v", m.Header, got)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	r := newRecorder(t)
+
+	var traceparent string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		traceparent = req.Header.Get("traceparent")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer s.Close()
+
+	ctx, span := Start(context.Background(), "handle")
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, s.URL+"/repos?access_token=secret", nil)
+
+	resp, err := (&http.Client{Transport: Transport(nil)}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	span.End()
+
+	if req.Header.Get("traceparent") != "" {
+		t.Error("the request is modified")
+	}
+
+	spans := r.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("%d spans are recorded", len(spans))
+	}
+
+	client := spans[0]
+	if client.Parent().SpanID() != span.SpanContext().SpanID() {
+		t.Error("the span of request is not the child of handler")
+	}
+
+	if traceparent == "" || traceparent[36:52] != client.SpanContext().SpanID().String() {
+		t.Errorf("traceparent = %q", traceparent)
+	}
+
+	if client.Status().Code.String() != "Error" {
+		t.Errorf("status of span = %v", client.Status())
+	}
+
+	for _, kv := range client.Attributes() {
+		if kv.Key == "http.url" && kv.Value.AsString() != s.URL+"/repos" {
+			t.Errorf("http.url = %s", kv.Value.AsString())
+		}
+	}
+}
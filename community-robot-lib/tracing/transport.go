@@ -0,0 +1,61 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Transport returns the http.RoundTripper which records a span for each request sent
+// by base, and propagates the trace context to the server. http.DefaultTransport is
+// used if base is nil.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &transport{base: base}
+}
+
+type transport struct {
+	base http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// the query is not recorded, because it may contain the secrets.
+	u := *req.URL
+	u.RawQuery = ""
+	u.User = nil
+
+	ctx, span := Start(
+		req.Context(), "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.method", req.Method),
+			attribute.String("http.url", u.String()),
+		),
+	)
+
+	// the request should not be modified by RoundTripper.
+	r := req.Clone(ctx)
+	InjectHTTP(ctx, r.Header)
+
+	resp, err := t.base.RoundTrip(r)
+	if err != nil {
+		End(span, err)
+
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		err = fmt.Errorf("the server responds %s", resp.Status)
+	}
+
+	End(span, err)
+
+	return resp, nil
+}
@@ -70,6 +70,11 @@ func (hc *HttpClient) Download(req *http.Request) (r []byte, statusCode int, err
 	return
 }
 
+// Do sends the request and retries if it fails to be sent. The caller must close the body of response.
+func (hc *HttpClient) Do(req *http.Request) (*http.Response, error) {
+	return hc.do(req)
+}
+
 func (hc *HttpClient) do(req *http.Request) (resp *http.Response, err error) {
 	if resp, err = hc.Client.Do(req); err == nil {
 		return
@@ -82,7 +87,20 @@ func (hc *HttpClient) do(req *http.Request) (resp *http.Response, err error) {
 		time.Sleep(backoff)
 		backoff *= 2
 
-		if resp, err = hc.Client.Do(req); err == nil {
+		// the body has been read by the last attempt, so it is sent again by a copy of request.
+		r := req
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return
+			}
+
+			r = req.Clone(req.Context())
+			if r.Body, err = req.GetBody(); err != nil {
+				return
+			}
+		}
+
+		if resp, err = hc.Client.Do(r); err == nil {
 			break
 		}
 	}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepInterval is the number of items at which the expired ones are removed,
+// in case the cache grows endlessly.
+const sweepInterval = 1000
+
+type ttlItem[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// TTLCache keeps the values until they expire. It is safe for concurrent use.
+type TTLCache[V any] struct {
+	mut   sync.Mutex
+	items map[string]ttlItem[V]
+}
+
+func NewTTLCache[V any]() *TTLCache[V] {
+	return &TTLCache[V]{items: map[string]ttlItem[V]{}}
+}
+
+// Get returns the value of key if it has not expired.
+func (c *TTLCache[V]) Get(key string) (V, bool) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	v, ok := c.items[key]
+	if !ok {
+		var empty V
+
+		return empty, false
+	}
+
+	if time.Now().After(v.expires) {
+		delete(c.items, key)
+
+		return v.value, false
+	}
+
+	return v.value, true
+}
+
+// Set keeps the value of key for ttl, and does nothing if ttl is not positive.
+func (c *TTLCache[V]) Set(key string, value V, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	now := time.Now()
+	c.items[key] = ttlItem[V]{value: value, expires: now.Add(ttl)}
+
+	if len(c.items)%sweepInterval == 0 {
+		for k, v := range c.items {
+			if now.After(v.expires) {
+				delete(c.items, k)
+			}
+		}
+	}
+}
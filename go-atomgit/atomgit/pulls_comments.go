@@ -206,7 +206,7 @@ func (s *PullRequestsService) EditComment(ctx context.Context, owner, repo strin
 //
 //meta:operation DELETE /repos/{owner}/{repo}/pulls/comments/{comment_id}
 func (s *PullRequestsService) DeleteComment(ctx context.Context, owner, repo, commentID string) (*Response, error) {
-	u := fmt.Sprintf("repos/%v/%v/pulls/comments/%d", owner, repo, commentID)
+	u := fmt.Sprintf("repos/%v/%v/pulls/comments/%v", owner, repo, commentID)
 	req, err := s.client.NewRequest("DELETE", u, nil)
 	if err != nil {
 		return nil, err
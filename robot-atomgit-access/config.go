@@ -7,6 +7,11 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
+const (
+	transportHTTP = "http"
+	transportMQ   = "mq"
+)
+
 type configuration struct {
 	ConfigItems accessConfig `json:"access,omitempty"`
 }
@@ -29,8 +34,13 @@ type pluginConfig struct {
 	// Name of the plugin.
 	Name string `json:"name" required:"true"`
 
-	// Endpoint is the location of the plugin.
-	Endpoint string `json:"endpoint" required:"true"`
+	// Endpoint is the location of the plugin. It is required if the transport is http.
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Transport is how the events are delivered to the plugin, http or mq. The plugin of mq
+	// consumes the events from the topic which the access robot publishes them to, instead of
+	// being forwarded to. The default is http.
+	Transport string `json:"transport,omitempty"`
 
 	// Events are the events that this plugin can handle and should be forward to it.
 	// If no events are specified, everything is sent.
@@ -62,10 +72,11 @@ func (a *accessConfig) validate() error {
 	return nil
 }
 
-func (c *configuration) GetEndpoints(param ...string) (ans []string) {
-
+// GetEndpoints returns the endpoints of the plugins of http which handle the event
+// of org/repo, and whether any plugin of mq handles it. The params are org, repo and event.
+func (c *configuration) GetEndpoints(param ...string) (ans []string, publish bool) {
 	if c.ConfigItems.RepoPlugins == nil {
-		return []string{}
+		return []string{}, false
 	}
 
 	var robotNames []string
@@ -79,21 +90,29 @@ func (c *configuration) GetEndpoints(param ...string) (ans []string) {
 		robotNames = append(robotNames, endpoint...)
 	}
 
-	if len(c.ConfigItems.Plugins) != 0 && len(robotNames) != 0 {
-		ans = matchEndpoint(&c.ConfigItems.Plugins, param[2], robotNames...)
+	if len(c.ConfigItems.Plugins) == 0 || len(robotNames) == 0 {
+		return
+	}
+
+	for _, p := range matchPlugins(c.ConfigItems.Plugins, param[2], robotNames...) {
+		if p.Transport == transportMQ {
+			publish = true
+		} else {
+			ans = append(ans, p.Endpoint)
+		}
 	}
 
 	return
 }
 
-func matchEndpoint(m *[]pluginConfig, event string, robotNames ...string) (ans []string) {
+func matchPlugins(m []pluginConfig, event string, robotNames ...string) (ans []pluginConfig) {
 	for _, val := range robotNames {
-		for _, value := range *m {
+		for _, value := range m {
 			if value.Name == val {
 				sort.Strings(value.Events)
 				idx := sort.SearchStrings(value.Events, event)
 				if idx < len(value.Events) && value.Events[idx] == event {
-					ans = append(ans, value.Endpoint)
+					ans = append(ans, value)
 				}
 			}
 		}
@@ -107,8 +126,15 @@ func (p *pluginConfig) validate() error {
 		return fmt.Errorf("missing name")
 	}
 
-	if p.Endpoint == "" {
-		return fmt.Errorf("missing endpoint")
+	switch p.Transport {
+	case "", transportHTTP:
+		if p.Endpoint == "" {
+			return fmt.Errorf("missing endpoint of plugin: %s", p.Name)
+		}
+
+	case transportMQ:
+	default:
+		return fmt.Errorf("unknown transport of plugin: %s, it must be %s or %s", p.Name, transportHTTP, transportMQ)
 	}
 
 	// p.Endpoint unchecked
@@ -3,12 +3,14 @@ package main
 import (
 	"flag"
 	_ "net/http"
+	"os"
 	_ "strconv"
-	"time"
 
 	_ "github.com/opensourceways/community-robot-lib/config"
 	_ "github.com/opensourceways/community-robot-lib/interrupts"
+	"github.com/opensourceways/community-robot-lib/kafka"
 	"github.com/opensourceways/community-robot-lib/logrusutil"
+	"github.com/opensourceways/community-robot-lib/mq"
 	liboptions "github.com/opensourceways/community-robot-lib/options"
 	framework "github.com/opensourceways/community-robot-lib/robot-atomgit-framework"
 	"github.com/opensourceways/community-robot-lib/secret"
@@ -19,6 +21,7 @@ import (
 type options struct {
 	service liboptions.ServiceOptions
 	atomgit liboptions.AtomGitOptions
+	mq      liboptions.MQOptions
 }
 
 func (o *options) Validate() error {
@@ -26,6 +29,10 @@ func (o *options) Validate() error {
 		return err
 	}
 
+	if err := o.mq.Validate(); err != nil {
+		return err
+	}
+
 	return o.atomgit.Validate()
 }
 
@@ -34,6 +41,7 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 
 	opt.atomgit.AddFlags(fs)
 	opt.service.AddFlags(fs)
+	opt.mq.AddFlags(fs)
 
 	_ = fs.Parse(args)
 
@@ -43,31 +51,31 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 func main() {
 	logrusutil.ComponentInit(botName)
 
-	//o := gatherOptions(flag.NewFlagSet(os.Args[0], flag.ExitOnError), os.Args[1:]...)
-	opt := options{
-		service: liboptions.ServiceOptions{
-			Port:        8822,
-			ConfigFile:  "D:\\Project\\github\\ibfru\\atomgit-bot\\robot-atomgit-access\\local\\config.yaml",
-			GracePeriod: 300 * time.Second,
-		},
-		atomgit: liboptions.AtomGitOptions{
-			TokenPath: "D:\\Project\\github\\ibfru\\atomgit-bot\\robot-atomgit-access\\local\\secret",
-		},
-	}
+	opt := gatherOptions(flag.NewFlagSet(os.Args[0], flag.ExitOnError), os.Args[1:]...)
 
 	if err := opt.Validate(); err != nil {
 		logrus.WithError(err).Fatal("Invalid options")
 	}
 
 	secretAgent := new(secret.Agent)
-	if err := secretAgent.Start([]string{opt.atomgit.TokenPath}); err != nil {
+	if err := secretAgent.Start(append([]string{opt.atomgit.TokenPath}, opt.service.SecretPaths()...)); err != nil {
 		logrus.WithError(err).Fatal("Error starting secret agent.")
 	}
 	defer secretAgent.Stop()
 
-	// to replace
+	opt.service.LoadSecrets(secretAgent)
+
+	var m mq.MQ
+	if opt.mq.Enabled() {
+		m = kafka.NewMQ(mq.Addresses(opt.mq.AddressList()...))
+		if err := m.Connect(); err != nil {
+			logrus.WithError(err).Fatal("Error connecting to the message queue.")
+		}
+
+		defer m.Disconnect()
+	}
 
-	p := newRobot()
+	p := newRobot(m, opt.mq.Topic, opt.service.ForwardSecret)
 	opt.atomgit.TokenGenerator = secretAgent.GetTokenGenerator(opt.atomgit.TokenPath)
 	framework.Run(p, opt.service, opt.atomgit)
 }
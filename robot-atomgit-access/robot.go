@@ -11,7 +11,9 @@ import (
 
 	_ "github.com/opensourceways/community-robot-lib/atomgitclient"
 	"github.com/opensourceways/community-robot-lib/config"
+	"github.com/opensourceways/community-robot-lib/mq"
 	framework "github.com/opensourceways/community-robot-lib/robot-atomgit-framework"
+	"github.com/opensourceways/community-robot-lib/tracing"
 	sdk "github.com/opensourceways/go-atomgit/atomgit"
 	"github.com/sirupsen/logrus"
 )
@@ -21,12 +23,19 @@ const botName = "robot-atomgit-access"
 type iClient interface {
 }
 
-func newRobot() *robot {
-	return &robot{}
+func newRobot(m mq.MQ, topic string, forwardSecret func() []byte) *robot {
+	return &robot{mq: m, topic: topic, forwardSecret: forwardSecret}
 }
 
 type robot struct {
 	cli iClient
+
+	// mq publishes the events to topic if it is set.
+	mq    mq.MQ
+	topic string
+
+	// forwardSecret returns the secret to sign the events forwarded if it is set.
+	forwardSecret func() []byte
 }
 
 type accessDispatcher struct {
@@ -38,7 +47,7 @@ type accessDispatcher struct {
 }
 
 var ad = accessDispatcher{
-	ec: *http.DefaultClient,
+	ec: http.Client{Transport: tracing.Transport(nil)},
 	wg: sync.WaitGroup{},
 }
 
@@ -67,24 +76,66 @@ func (bot *robot) handleAccessEvent(e *sdk.AccessEvent, cfg config.Config, log *
 	s[0], _ = log.Data[framework.LogFieldOrg].(string)
 	s[1], _ = log.Data[framework.LogFieldRepo].(string)
 	s[2], _ = log.Data[framework.LogFieldEventType].(string)
-	endpoints := c.GetEndpoints(s...)
+	endpoints, publish := c.GetEndpoints(s...)
+
+	var secret []byte
+	if bot.forwardSecret != nil {
+		secret = bot.forwardSecret()
+	}
+
+	ad.dispatchToDownstreamRobot(endpoints, log, payload, secret)
+
+	if !publish {
+		return nil
+	}
+
+	return bot.publish(s[0], s[1], s[2], log, payload)
+}
+
+// publish publishes the event to the topic, keyed by org/repo for the ordering.
+func (bot *robot) publish(org, repo, eventType string, log *logrus.Entry, payload []byte) error {
+	if bot.mq == nil {
+		return errors.New("some plugins consume the event from the message queue, but it is not set")
+	}
+
+	delivery, _ := log.Data[framework.LogFieldEventId].(string)
 
-	fmt.Printf("%+v", endpoints)
-	ad.dispatchToDownstreamRobot(endpoints, log, payload)
+	msg := framework.NewEventMessage(eventType, delivery, payload, org, repo)
+
+	// the downstream robots continue the trace of event by it.
+	tracing.InjectMessage(log.Context, msg)
+
+	if err := bot.mq.Publish(bot.topic, msg); err != nil {
+		return fmt.Errorf("publish to topic:%s, err:%s", bot.topic, err.Error())
+	}
 
 	return nil
 }
 
-func (d *accessDispatcher) dispatchToDownstreamRobot(endpoints []string, l *logrus.Entry, payload []byte) {
+// dispatchToDownstreamRobot forwards the event to the endpoints, and signs it if the secret is not empty.
+// The trace context of event is propagated to the endpoints by the transport of client.
+func (d *accessDispatcher) dispatchToDownstreamRobot(endpoints []string, l *logrus.Entry, payload, secret []byte) {
 
 	newReq := func(endpoint string) (*http.Request, error) {
-		req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewBuffer(payload))
+		req, err := http.NewRequestWithContext(l.Context, http.MethodPost, endpoint, bytes.NewBuffer(payload))
 		if err != nil {
 			return nil, err
 		}
 
+		eventType := l.Data[framework.LogFieldEventType].(string)
+
 		req.Header.Set("User-Agent", framework.UserAgentHeader)
-		req.Header.Set("X-AtomGit-Event", l.Data[framework.LogFieldEventType].(string))
+		req.Header.Set("X-AtomGit-Event", eventType)
+
+		// the downstream robots skip the duplicate deliveries by it.
+		if delivery, _ := l.Data[framework.LogFieldEventId].(string); delivery != "" {
+			req.Header.Set("X-AtomGit-Delivery", delivery)
+		}
+
+		if len(secret) > 0 {
+			framework.SignForwardedRequest(req, eventType, payload, secret)
+		}
+
 		return req, nil
 	}
 
@@ -137,21 +188,35 @@ func (d *accessDispatcher) forwardTo(req *http.Request) error {
 	return nil
 }
 
+// do sends the request, and retries it if it fails or the robot is unavailable, such as
+// the replica which is not the leader. The body is renewed by GetBody on each retry.
 func (d *accessDispatcher) do(req *http.Request) (resp *http.Response, err error) {
-	if resp, err = d.ec.Do(req); err == nil {
-		return
-	}
-
 	maxRetries := 4
 	backoff := 100 * time.Millisecond
 
-	for retries := 0; retries < maxRetries; retries++ {
+	for retries := 0; ; retries++ {
+		resp, err = d.ec.Do(req)
+		if err == nil && resp.StatusCode != http.StatusServiceUnavailable {
+			return
+		}
+
+		if retries == maxRetries {
+			return
+		}
+
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+
 		time.Sleep(backoff)
 		backoff *= 2
 
-		if resp, err = d.ec.Do(req); err == nil {
-			break
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
 		}
+
+		req = req.Clone(req.Context())
+		req.Body = body
 	}
-	return
 }
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opensourceways/community-robot-lib/config"
+	"github.com/opensourceways/community-robot-lib/localmq"
+	"github.com/opensourceways/community-robot-lib/mq"
+	framework "github.com/opensourceways/community-robot-lib/robot-atomgit-framework"
+	sdk "github.com/opensourceways/go-atomgit/atomgit"
+	"github.com/sirupsen/logrus"
+)
+
+// downstreamRobot records the issues forwarded by the access robot.
+type downstreamRobot struct {
+	mut    sync.Mutex
+	issues []int
+}
+
+func (bot *downstreamRobot) NewConfig() config.Config {
+	return nil
+}
+
+func (bot *downstreamRobot) RegisterEventHandler(f framework.HandlerRegister) {
+	f.RegisterIssueHandler(func(e *sdk.IssuesEvent, _ config.Config, _ *logrus.Entry) error {
+		bot.mut.Lock()
+		bot.issues = append(bot.issues, e.GetIssue().GetNumber())
+		bot.mut.Unlock()
+
+		return nil
+	})
+}
+
+func TestForwardSignedEvent(t *testing.T) {
+	down := &downstreamRobot{}
+	agent := config.NewConfigAgent(down.NewConfig)
+
+	// the downstream robot accepts the previous secret during the rotation.
+	h := framework.NewWebhookHandler(down, &agent, nil, framework.VerifyForwardedBy(func() [][]byte {
+		return [][]byte{[]byte("new-secret"), []byte("secret")}
+	}))
+
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	cfg := &configuration{ConfigItems: accessConfig{
+		RepoPlugins: map[string][]string{"openeuler": {"downstream"}},
+		Plugins:     []pluginConfig{{Name: "downstream", Endpoint: s.URL, Events: []string{"issues"}}},
+	}}
+
+	forward := func(secret string, number int) {
+		bot := newRobot(nil, "", func() []byte { return []byte(secret) })
+
+		if err := handleIssue(bot, cfg, number); err != nil {
+			t.Fatal(err)
+		}
+
+		ad.wg.Wait()
+		h.Wait()
+	}
+
+	forward("secret", 1)
+	// the event signed by an unknown secret is rejected.
+	forward("spoofed", 2)
+
+	if len(down.issues) != 1 || down.issues[0] != 1 {
+		t.Errorf("issues handled by the downstream robot = %v", down.issues)
+	}
+}
+
+func TestOneTransportPerPlugin(t *testing.T) {
+	down := &downstreamRobot{}
+	agent := config.NewConfigAgent(down.NewConfig)
+	h := framework.NewWebhookHandler(down, &agent, nil)
+
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	m := localmq.NewMemoryMQ()
+	if err := m.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Disconnect()
+
+	var published int32
+	sub, err := m.Subscribe("atomgit-events", func(mq.Event) error {
+		atomic.AddInt32(&published, 1)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	cfg := &configuration{ConfigItems: accessConfig{
+		RepoPlugins: map[string][]string{"openeuler": {"http-robot", "mq-robot"}},
+		Plugins: []pluginConfig{
+			{Name: "http-robot", Endpoint: s.URL, Events: []string{"issues"}},
+			{Name: "mq-robot", Transport: transportMQ, Events: []string{"issues"}},
+		},
+	}}
+
+	if err := handleIssue(newRobot(m, "atomgit-events", nil), cfg, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	ad.wg.Wait()
+	h.Wait()
+
+	for i := 0; i < 50 && atomic.LoadInt32(&published) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// each plugin gets the event once, by its own transport.
+	if len(down.issues) != 1 || atomic.LoadInt32(&published) != 1 {
+		t.Errorf("forwarded = %v, published = %d", down.issues, published)
+	}
+
+	// the event can't reach the plugin of mq if the message queue is not set.
+	if err := handleIssue(newRobot(nil, "", nil), cfg, 2); err == nil {
+		t.Error("expect an error when the message queue is not set")
+	}
+
+	ad.wg.Wait()
+	h.Wait()
+}
+
+func handleIssue(bot *robot, cfg *configuration, number int) error {
+	l := logrus.WithFields(logrus.Fields{
+		framework.LogFieldOrg:       "openeuler",
+		framework.LogFieldRepo:      "community",
+		framework.LogFieldEventType: "issues",
+		framework.LogFieldEventId:   "delivery",
+	}).WithContext(context.Background())
+
+	payload := []byte(`{"action":"opened","issue":{"number":` + strconv.Itoa(number) + `}}`)
+
+	return bot.handleAccessEvent(&sdk.AccessEvent{}, cfg, l, payload)
+}
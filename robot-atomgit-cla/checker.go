@@ -0,0 +1,347 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/opensourceways/community-robot-lib/utils"
+)
+
+const maxRetriesOfCheck = 3
+
+// CLAChecker checks whether the contributors have signed the cla.
+type CLAChecker interface {
+	// Check returns the signing status of each email.
+	Check(emails []string) (map[string]bool, error)
+}
+
+// newCLAChecker builds the checker of the config. The emails of the corporation
+// domains or in the allow list are signed, and the others are checked by the cla
+// service in which the signed ones are cached until the ttl expires.
+func newCLAChecker(cfg *botConfig, cache *signingCache, getToken func() []byte) CLAChecker {
+	var checkers []CLAChecker
+
+	if cfg.corpList != nil {
+		checkers = append(checkers, cfg.corpList)
+	}
+
+	if cfg.allowList != nil {
+		checkers = append(checkers, cfg.allowList)
+	}
+
+	cli := utils.HttpClient{
+		Client:     &http.Client{Timeout: time.Duration(cfg.CheckTimeout) * time.Second},
+		MaxRetries: maxRetriesOfCheck,
+	}
+
+	var remote CLAChecker
+	switch {
+	case cfg.BatchCheckURL != "":
+		remote = &batchChecker{url: cfg.BatchCheckURL, cli: cli, getToken: getToken}
+	case cfg.CheckURL != "":
+		remote = &httpChecker{url: cfg.CheckURL, cli: cli, getToken: getToken}
+	}
+
+	if remote != nil {
+		checkers = append(checkers, &cachedChecker{
+			checker: remote,
+			cache:   cache,
+			ttl:     time.Duration(cfg.CacheTTL) * time.Second,
+			prefix:  cfg.BatchCheckURL + cfg.CheckURL,
+		})
+	}
+
+	return chainChecker(checkers)
+}
+
+// chainChecker asks the checkers in order, and only the emails
+// which are not signed are passed to the next one. The emails are
+// case-insensitive, so the checkers get them in lower case.
+type chainChecker []CLAChecker
+
+func (c chainChecker) Check(emails []string) (map[string]bool, error) {
+	signed := make(map[string]bool, len(emails))
+
+	unsigned := make([]string, 0, len(emails))
+	for _, e := range emails {
+		v := strings.ToLower(e)
+		if _, ok := signed[v]; !ok {
+			signed[v] = false
+			unsigned = append(unsigned, v)
+		}
+	}
+
+	for _, checker := range c {
+		if len(unsigned) == 0 {
+			break
+		}
+
+		v, err := checker.Check(unsigned)
+		if err != nil {
+			return nil, err
+		}
+
+		next := make([]string, 0, len(unsigned))
+		for _, e := range unsigned {
+			if v[e] {
+				signed[e] = true
+			} else {
+				next = append(next, e)
+			}
+		}
+
+		unsigned = next
+	}
+
+	r := make(map[string]bool, len(emails))
+	for _, e := range emails {
+		r[e] = signed[strings.ToLower(e)]
+	}
+
+	return r, nil
+}
+
+func setAuthorization(req *http.Request, getToken func() []byte) {
+	if getToken == nil {
+		return
+	}
+
+	if t := getToken(); len(t) > 0 {
+		req.Header.Set("Authorization", "Bearer "+string(t))
+	}
+}
+
+// httpChecker checks each email by the cla service which
+// responds {"data":{"signed":bool}} for url?email=xxx.
+type httpChecker struct {
+	url      string
+	cli      utils.HttpClient
+	getToken func() []byte
+}
+
+func (c *httpChecker) Check(emails []string) (map[string]bool, error) {
+	r := make(map[string]bool, len(emails))
+
+	for _, e := range emails {
+		b, err := c.isSigned(e)
+		if err != nil {
+			return nil, err
+		}
+
+		r[e] = b
+	}
+
+	return r, nil
+}
+
+func (c *httpChecker) isSigned(email string) (bool, error) {
+	req, err := http.NewRequest(
+		http.MethodGet, fmt.Sprintf("%s?email=%s", c.url, url.QueryEscape(email)), nil,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	setAuthorization(req, c.getToken)
+
+	var v struct {
+		Data struct {
+			Signed bool `json:"signed"`
+		} `json:"data"`
+	}
+
+	if _, err := c.cli.ForwardTo(req, &v); err != nil {
+		return false, fmt.Errorf("check cla of %s failed, err:%s", email, err.Error())
+	}
+
+	return v.Data.Signed, nil
+}
+
+// batchChecker checks all the emails in one call. The request is {"emails":["xxx"]},
+// and the response is {"data":[{"email":"xxx","signed":bool}]}.
+type batchChecker struct {
+	url      string
+	cli      utils.HttpClient
+	getToken func() []byte
+}
+
+func (c *batchChecker) Check(emails []string) (map[string]bool, error) {
+	body, err := utils.JsonMarshal(map[string][]string{"emails": emails})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	setAuthorization(req, c.getToken)
+
+	var v struct {
+		Data []struct {
+			Email  string `json:"email"`
+			Signed bool   `json:"signed"`
+		} `json:"data"`
+	}
+
+	if _, err := c.cli.ForwardTo(req, &v); err != nil {
+		return nil, fmt.Errorf("check cla failed, err:%s", err.Error())
+	}
+
+	// the service may respond the emails in a different case.
+	signed := make(map[string]bool, len(v.Data))
+	for _, item := range v.Data {
+		signed[strings.ToLower(item.Email)] = item.Signed
+	}
+
+	r := make(map[string]bool, len(emails))
+	for _, e := range emails {
+		r[e] = signed[strings.ToLower(e)]
+	}
+
+	return r, nil
+}
+
+// allowList is the static list of signers, such as the bots and the employees
+// of corporations which signed the cla in bulk. Each line of the file is an
+// email or a domain starting with "@" or "*@", and the lines starting with "#" are comments.
+type allowList struct {
+	emails  map[string]bool
+	domains map[string]bool
+}
+
+func newAllowList() *allowList {
+	return &allowList{emails: map[string]bool{}, domains: map[string]bool{}}
+}
+
+func loadAllowList(path string) (*allowList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	l := newAllowList()
+
+	s := bufio.NewScanner(f)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if err := l.add(line); err != nil {
+			return nil, fmt.Errorf("invalid allow list, line %d: %s", n, err.Error())
+		}
+	}
+
+	return l, s.Err()
+}
+
+// add adds the email or the domain which is in the form of "@huawei.com" or "*@huawei.com".
+func (l *allowList) add(v string) error {
+	v = strings.ToLower(strings.TrimPrefix(v, "*"))
+
+	if strings.HasPrefix(v, "@") {
+		if !utils.IsValidEmail("x" + v) {
+			return fmt.Errorf("invalid domain: %s", v)
+		}
+
+		l.domains[v[1:]] = true
+	} else if utils.IsValidEmail(v) {
+		l.emails[v] = true
+	} else {
+		return fmt.Errorf("invalid email: %s", v)
+	}
+
+	return nil
+}
+
+func (l *allowList) has(email string) bool {
+	v := strings.ToLower(email)
+
+	if i := strings.LastIndex(v, "@"); i >= 0 && l.domains[v[i+1:]] {
+		return true
+	}
+
+	return l.emails[v]
+}
+
+func (l *allowList) Check(emails []string) (map[string]bool, error) {
+	r := make(map[string]bool, len(emails))
+
+	for _, e := range emails {
+		r[e] = l.has(e)
+	}
+
+	return r, nil
+}
+
+// cachedChecker caches the signed emails, so that a big PR will not call the cla
+// service for each commit. The unsigned ones are not cached, because the contributor
+// will check again after signing.
+type cachedChecker struct {
+	checker CLAChecker
+	cache   *signingCache
+	ttl     time.Duration
+	prefix  string
+}
+
+func (c *cachedChecker) Check(emails []string) (map[string]bool, error) {
+	r := make(map[string]bool, len(emails))
+
+	missed := make([]string, 0, len(emails))
+	for _, e := range emails {
+		if c.cache.isSigned(c.prefix + e) {
+			r[e] = true
+		} else {
+			missed = append(missed, e)
+		}
+	}
+
+	if len(missed) == 0 {
+		return r, nil
+	}
+
+	v, err := c.checker.Check(missed)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range missed {
+		if v[e] {
+			r[e] = true
+			c.cache.add(c.prefix+e, c.ttl)
+		}
+	}
+
+	return r, nil
+}
+
+// signingCache keeps the signed emails until they expire.
+// It is safe for concurrent use.
+type signingCache struct {
+	cache *utils.TTLCache[struct{}]
+}
+
+func newSigningCache() *signingCache {
+	return &signingCache{cache: utils.NewTTLCache[struct{}]()}
+}
+
+func (c *signingCache) isSigned(key string) bool {
+	_, ok := c.cache.Get(key)
+
+	return ok
+}
+
+func (c *signingCache) add(key string, ttl time.Duration) {
+	c.cache.Set(key, struct{}{}, ttl)
+}
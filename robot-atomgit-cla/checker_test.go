@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCLAChecker(t *testing.T) {
+	signed := map[string]bool{"alice@example.com": true}
+
+	var calls []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		email := r.URL.Query().Get("email")
+		calls = append(calls, email)
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]bool{"signed": signed[email]},
+		})
+	}))
+	defer s.Close()
+
+	path := filepath.Join(t.TempDir(), "allow_list")
+	if err := os.WriteFile(path, []byte("# bots\nci-bot@example.com\n@huawei.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := botConfig{
+		CLALabelYes:   "cla/yes",
+		CLALabelNo:    "cla/no",
+		SignURL:       "https://cla.example.com",
+		FAQURL:        "https://cla.example.com/faq",
+		CheckURL:      s.URL,
+		AllowListFile: path,
+	}
+	cfg.Repos = []string{"openeuler"}
+	cfg.setDefault()
+
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := newSigningCache()
+	getToken := func() []byte { return []byte("token") }
+	emails := []string{"alice@example.com", "bob@example.com", "ci-bot@example.com", "Carol@Huawei.com"}
+
+	for i := 0; i < 2; i++ {
+		r, err := newCLAChecker(&cfg, cache, getToken).Check(emails)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := map[string]bool{
+			"alice@example.com":  true,
+			"bob@example.com":    false,
+			"ci-bot@example.com": true,
+			"Carol@Huawei.com":   true,
+		}
+		for k, v := range want {
+			if r[k] != v {
+				t.Errorf("round %d: %s signed = %v, want %v", i, k, r[k], v)
+			}
+		}
+	}
+
+	// alice is cached after the first round, and bob is checked every time.
+	if want := []string{"alice@example.com", "bob@example.com", "bob@example.com"}; len(calls) != len(want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestBatchCheckerRetry(t *testing.T) {
+	attempts := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+
+		// the connection is broken at the first attempt, so that the request is retried.
+		if attempts == 1 {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			_ = conn.Close()
+
+			return
+		}
+
+		var req struct {
+			Emails []string `json:"emails"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Emails) != 1 {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		// the service responds the email in lower case.
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": []map[string]interface{}{{"email": strings.ToLower(req.Emails[0]), "signed": true}},
+		})
+	}))
+	defer s.Close()
+
+	cfg := botConfig{BatchCheckURL: s.URL, CheckTimeout: 5}
+
+	r, err := newCLAChecker(&cfg, newSigningCache(), nil).Check([]string{"Alice@Example.com", "alice@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !r["Alice@Example.com"] || !r["alice@example.com"] || attempts != 2 {
+		t.Errorf("result = %v, attempts = %d", r, attempts)
+	}
+}
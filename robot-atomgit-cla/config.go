@@ -2,6 +2,8 @@ package main
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/huaweicloud/golangsdk"
 	"github.com/opensourceways/community-robot-lib/config"
@@ -55,9 +57,22 @@ func (c *configuration) SetDefault() {
 	}
 }
 
+const (
+	checkModeCLA = "cla"
+	checkModeDCO = "dco"
+)
+
 type botConfig struct {
 	config.RepoFilter
 
+	// CheckMode is the way to check the commits of PR, it is cla or dco. Default is cla.
+	// The fields about cla below are not needed when it is dco.
+	CheckMode string `json:"check_mode"`
+
+	// DCO is the config of checking the Developer Certificate of Origin.
+	// It is used only when check_mode is dco.
+	DCO dcoConfig `json:"dco"`
+
 	// CLALabelYes is the cla label name for org/repos indicating
 	// the cla has been signed
 	CLALabelYes string `json:"cla_label_yes" required:"true"`
@@ -68,7 +83,26 @@ type botConfig struct {
 
 	// CheckURL is the url used to check whether the contributor has signed cla
 	// The url has the format as https://**/{{org}}:{{repo}}?email={{email}}
-	CheckURL string `json:"check_url" required:"true"`
+	CheckURL string `json:"check_url"`
+
+	// BatchCheckURL is the url used to check all the contributors of a PR in one call.
+	// It is used instead of CheckURL if it is set.
+	BatchCheckURL string `json:"batch_check_url"`
+
+	// AllowListFile is the file of contributors who are regarded as signed, such as
+	// the bots and the employees of corporations which signed the cla in bulk.
+	// Each line is an email or a domain starting with "@".
+	AllowListFile string `json:"allow_list_file"`
+
+	// CorpDomains are the email domains covered by the corporation cla, such as *@huawei.com.
+	// The contributors with the emails of these domains are regarded as signed.
+	CorpDomains []string `json:"corp_domains"`
+
+	// CheckTimeout is the timeout in seconds of each call to check cla. Default is 10.
+	CheckTimeout int `json:"check_timeout"`
+
+	// CacheTTL is the duration in seconds to cache the signed contributors. Default is 3600.
+	CacheTTL int `json:"cache_ttl"`
 
 	// SignURL is the url used to sign the cla
 	SignURL string `json:"sign_url" required:"true"`
@@ -84,12 +118,44 @@ type botConfig struct {
 
 	// FAQURL is the url of faq which is corresponding to the way of checking CLA
 	FAQURL string `json:"faq_url" required:"true"`
+
+	allowList *allowList
+	corpList  *allowList
 }
 
 func (c *botConfig) setDefault() {
+	if c.CheckMode == "" {
+		c.CheckMode = checkModeCLA
+	}
+
+	c.DCO.setDefault()
+
+	if c.CheckTimeout <= 0 {
+		c.CheckTimeout = 10
+	}
+
+	if c.CacheTTL <= 0 {
+		c.CacheTTL = 3600
+	}
 }
 
 func (c *botConfig) validate() error {
+	switch c.CheckMode {
+	case checkModeCLA:
+	case checkModeDCO:
+		if len(c.Repos) == 0 {
+			return errors.New("missing repos")
+		}
+
+		if err := c.DCO.validate(); err != nil {
+			return err
+		}
+
+		return c.RepoFilter.Validate()
+	default:
+		return fmt.Errorf("unknown check mode: %s", c.CheckMode)
+	}
+
 	if _, err := golangsdk.BuildRequestBody(c, ""); err != nil {
 		return err
 	}
@@ -100,9 +166,49 @@ func (c *botConfig) validate() error {
 		}
 	}
 
+	if c.CheckURL == "" && c.BatchCheckURL == "" && c.AllowListFile == "" && len(c.CorpDomains) == 0 {
+		return errors.New("missing check_url, batch_check_url, allow_list_file or corp_domains")
+	}
+
+	if len(c.CorpDomains) > 0 {
+		c.corpList = newAllowList()
+
+		for _, v := range c.CorpDomains {
+			if !strings.HasPrefix(v, "*@") && !strings.HasPrefix(v, "@") {
+				return fmt.Errorf("invalid corp domain: %s, it should be like *@huawei.com", v)
+			}
+
+			if err := c.corpList.add(v); err != nil {
+				return err
+			}
+		}
+	}
+
+	if c.AllowListFile != "" {
+		v, err := loadAllowList(c.AllowListFile)
+		if err != nil {
+			return err
+		}
+
+		c.allowList = v
+	}
+
 	return c.RepoFilter.Validate()
 }
 
+func (c *botConfig) isDCO() bool {
+	return c.CheckMode == checkModeDCO
+}
+
+// labels returns the labels indicating the commits are signed or not.
+func (c *botConfig) labels() (yes, no string) {
+	if c.isDCO() {
+		return c.DCO.LabelYes, c.DCO.LabelNo
+	}
+
+	return c.CLALabelYes, c.CLALabelNo
+}
+
 type litePRCommitter struct {
 	// Email is the one of committer in a commit when a PR is lite
 	Email string `json:"email" required:"true"`
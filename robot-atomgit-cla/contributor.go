@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/opensourceways/community-robot-lib/utils"
+	"github.com/opensourceways/go-atomgit/atomgit"
+)
+
+var coAuthoredByRe = regexp.MustCompile(`(?mi)^\s*Co-authored-by:\s*.*?<([^>]*)>\s*$`)
+
+// getEmailsOfCommit returns the email of author, or committer if checking by committer,
+// and the emails of co-authors in the Co-authored-by trailers without duplication.
+func getEmailsOfCommit(c *atomgit.RepositoryCommit, cfg *botConfig) []string {
+	if c == nil {
+		return []string{""}
+	}
+
+	r := []string{strings.Trim(getAuthorOfCommit(c, cfg), " ")}
+
+	for _, m := range coAuthoredByRe.FindAllStringSubmatch(c.Commit.GetMessage(), -1) {
+		email := strings.TrimSpace(m[1])
+
+		duplicate := false
+		for _, v := range r {
+			if strings.EqualFold(v, email) {
+				duplicate = true
+
+				break
+			}
+		}
+
+		if !duplicate {
+			r = append(r, email)
+		}
+	}
+
+	return r
+}
+
+// signingResult is the result of a contributor of pull request.
+type signingResult struct {
+	email   string
+	login   string
+	commits []string
+	signed  bool
+	byCorp  bool
+}
+
+func genSigningResults(
+	commits []*atomgit.RepositoryCommit, emails [][]string, signed map[string]bool, cfg *botConfig,
+) []signingResult {
+	var r []signingResult
+	index := map[string]int{}
+
+	for i, c := range commits {
+		sha := c.GetSHA()
+		if len(sha) > maxLengthOfSHA {
+			sha = sha[:maxLengthOfSHA]
+		}
+
+		for k, e := range emails[i] {
+			// only the author, or committer if checking by committer, has the login.
+			login := ""
+			if k == 0 {
+				login = getLoginOfCommit(c, cfg)
+			}
+
+			if j, ok := index[e]; ok {
+				r[j].commits = append(r[j].commits, sha)
+
+				if r[j].login == "" {
+					r[j].login = login
+				}
+
+				continue
+			}
+
+			index[e] = len(r)
+			r = append(r, signingResult{
+				email:   e,
+				login:   login,
+				commits: []string{sha},
+				signed:  signed[e],
+				byCorp:  cfg.corpList != nil && cfg.corpList.has(e),
+			})
+		}
+	}
+
+	return r
+}
+
+// getLoginOfCommit returns the login of user whose email is returned by getAuthorOfCommit.
+func getLoginOfCommit(c *atomgit.RepositoryCommit, cfg *botConfig) string {
+	if cfg.CheckByCommitter {
+		v := c.Commit.GetCommitter()
+
+		if !cfg.LitePRCommitter.isLitePR(v.GetEmail(), v.GetName()) {
+			return c.GetCommitter().GetLogin()
+		}
+	}
+
+	return c.GetAuthor().GetLogin()
+}
+
+// maskEmail hides the local part of email except the first letter, such as a***@example.com.
+func maskEmail(email string) string {
+	if email == "" {
+		return "(empty)"
+	}
+
+	first, _ := utf8.DecodeRuneInString(email)
+
+	domain := ""
+	if i := strings.LastIndex(email, "@"); i > 0 {
+		domain = email[i:]
+	}
+
+	return string(first) + "***" + domain
+}
+
+// generateSigningResults lists the result of each contributor. The comment is public,
+// so the contributor is shown by the login, or the masked email if the login is unknown.
+func generateSigningResults(results []signingResult) string {
+	if len(results) == 0 {
+		return ""
+	}
+
+	items := make([]string, 0, len(results)+2)
+	items = append(items, "| Contributor | Commits | Result |", "| --- | --- | --- |")
+
+	for _, item := range results {
+		status := ":x: not signed"
+		switch {
+		case item.byCorp:
+			status = ":white_check_mark: signed by corporation"
+		case item.signed:
+			status = ":white_check_mark: signed"
+		case !utils.IsValidEmail(item.email):
+			status = ":x: invalid email"
+		}
+
+		contributor := item.login
+		if contributor == "" {
+			contributor = maskEmail(item.email)
+		}
+
+		commits := item.commits
+		if len(commits) > 3 {
+			commits = append(commits[:3:3], fmt.Sprintf("and %d more", len(item.commits)-3))
+		}
+
+		items = append(items, fmt.Sprintf("| %s | %s | %s |", contributor, strings.Join(commits, ", "), status))
+	}
+
+	return strings.Join(items, "\n")
+}
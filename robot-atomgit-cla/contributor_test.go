@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/opensourceways/go-atomgit/atomgit"
+)
+
+func TestCoAuthorsAndCorpDomains(t *testing.T) {
+	cfg := botConfig{
+		CLALabelYes: "cla/yes",
+		CLALabelNo:  "cla/no",
+		SignURL:     "https://cla.example.com",
+		FAQURL:      "https://cla.example.com/faq",
+		CorpDomains: []string{"*@huawei.com"},
+	}
+	cfg.Repos = []string{"openeuler"}
+	cfg.setDefault()
+
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	commit := func(sha, email, msg string) *atomgit.RepositoryCommit {
+		return &atomgit.RepositoryCommit{
+			SHA:    atomgit.String(sha),
+			Author: &atomgit.User{Login: atomgit.String(strings.Split(email, "@")[0])},
+			Commit: &atomgit.Commit{
+				Message: atomgit.String(msg),
+				Author:  &atomgit.CommitAuthor{Email: atomgit.String(email)},
+			},
+		}
+	}
+
+	commits := []*atomgit.RepositoryCommit{
+		commit("aaaa", "alice@huawei.com", "fix"),
+		commit("bbbb", "carol@Huawei.com", "fix\n\nCo-authored-by: Bob <bob@example.com>"),
+	}
+
+	bot := newRobot(nil, nil, nil)
+
+	unsigned, results, err := bot.getUnsignedCommits(commits, &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(unsigned) != 1 || unsigned[0].GetSHA() != "bbbb" {
+		t.Errorf("the commit co-authored by bob should be unsigned, but got %v", unsigned)
+	}
+
+	s := generateSigningResults(results)
+	for _, v := range []string{
+		"| alice | aaaa | :white_check_mark: signed by corporation |",
+		"| b***@example.com | bbbb | :x: not signed |",
+	} {
+		if !strings.Contains(s, v) {
+			t.Errorf("results %q should contain %q", s, v)
+		}
+	}
+
+	// the emails are not shown in the public comment.
+	for _, v := range []string{"alice@huawei.com", "bob@example.com"} {
+		if strings.Contains(s, v) {
+			t.Errorf("results %q should not contain %q", s, v)
+		}
+	}
+}
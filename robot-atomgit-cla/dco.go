@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/opensourceways/go-atomgit/atomgit"
+)
+
+const (
+	// the Signed-off-by trailer matches the author if it has the same email.
+	matchByEmail = "email"
+	// the Signed-off-by trailer matches the author if it has the same name.
+	matchByName = "name"
+	// the Signed-off-by trailer matches the author if it has the same name or email.
+	matchByNameOrEmail = "name_or_email"
+	// the Signed-off-by trailer matches the author if it has the same name and email.
+	matchByNameAndEmail = "name_and_email"
+
+	signOffGuideTitle = "The following commits are not signed off by their authors, " +
+		"which is required by the Developer Certificate of Origin (DCO)."
+)
+
+var signedOffByRe = regexp.MustCompile(`(?mi)^\s*Signed-off-by:\s*(.*?)\s*<([^>]*)>\s*$`)
+
+type dcoConfig struct {
+	// LabelYes is the label indicating all the commits are signed off. Default is dco/yes.
+	LabelYes string `json:"label_yes"`
+
+	// LabelNo is the label indicating some commits are not signed off. Default is dco/no.
+	LabelNo string `json:"label_no"`
+
+	// Match is the rule by which the Signed-off-by trailer matches the author of commit.
+	// It is one of email, name, name_or_email and name_and_email. Default is email.
+	// The email is compared case-insensitively.
+	Match string `json:"match"`
+
+	// GuideURL is the url of document about how to sign off the commits.
+	GuideURL string `json:"guide_url"`
+}
+
+func (c *dcoConfig) setDefault() {
+	if c.LabelYes == "" {
+		c.LabelYes = "dco/yes"
+	}
+
+	if c.LabelNo == "" {
+		c.LabelNo = "dco/no"
+	}
+
+	if c.Match == "" {
+		c.Match = matchByEmail
+	}
+}
+
+func (c *dcoConfig) validate() error {
+	switch c.Match {
+	case matchByEmail, matchByName, matchByNameOrEmail, matchByNameAndEmail:
+	default:
+		return fmt.Errorf("unknown match rule of dco: %s", c.Match)
+	}
+
+	if c.LabelYes == c.LabelNo {
+		return fmt.Errorf("the labels of dco can't be the same")
+	}
+
+	return nil
+}
+
+// isSignedOffBy checks whether the Signed-off-by trailer of name and email matches the author.
+func (c *dcoConfig) isSignedOffBy(author *atomgit.CommitAuthor, name, email string) bool {
+	sameEmail := author.GetEmail() != "" && strings.EqualFold(strings.TrimSpace(author.GetEmail()), email)
+	sameName := author.GetName() != "" && strings.TrimSpace(author.GetName()) == name
+
+	switch c.Match {
+	case matchByName:
+		return sameName
+	case matchByNameOrEmail:
+		return sameName || sameEmail
+	case matchByNameAndEmail:
+		return sameName && sameEmail
+	default:
+		return sameEmail
+	}
+}
+
+// getCommitsNotSignedOff returns the commits which have no Signed-off-by trailer of the author.
+func getCommitsNotSignedOff(commits []*atomgit.RepositoryCommit, cfg *dcoConfig) []*atomgit.RepositoryCommit {
+	r := make([]*atomgit.RepositoryCommit, 0, len(commits))
+
+	for _, c := range commits {
+		if !isSignedOff(c, cfg) {
+			r = append(r, c)
+		}
+	}
+
+	return r
+}
+
+func isSignedOff(c *atomgit.RepositoryCommit, cfg *dcoConfig) bool {
+	if c == nil || c.Commit == nil {
+		return false
+	}
+
+	author := c.Commit.GetAuthor()
+
+	for _, m := range signedOffByRe.FindAllStringSubmatch(c.Commit.GetMessage(), -1) {
+		if cfg.isSignedOffBy(author, m[1], m[2]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func signOffGuide(cInfo string, cfg *dcoConfig) string {
+	s := fmt.Sprintf(
+		"%s\n\n%s\n\nPlease amend the commits by `git commit --amend --signoff` or `git rebase --signoff`, and push them again.",
+		signOffGuideTitle, cInfo,
+	)
+
+	if cfg.GuideURL != "" {
+		s += fmt.Sprintf(" See [the guide](%s) for details.", cfg.GuideURL)
+	}
+
+	return s
+}
+
+func alreadySignedOff(user string) string {
+	s := `***@%s***, thanks for your pull request. All the commits are signed off by their authors. :wave: `
+	return fmt.Sprintf(s, user)
+}
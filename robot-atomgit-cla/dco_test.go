@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/opensourceways/go-atomgit/atomgit"
+)
+
+func TestGetCommitsNotSignedOff(t *testing.T) {
+	commit := func(sha, msg string) *atomgit.RepositoryCommit {
+		return &atomgit.RepositoryCommit{
+			SHA: atomgit.String(sha),
+			Commit: &atomgit.Commit{
+				Message: atomgit.String(msg),
+				Author: &atomgit.CommitAuthor{
+					Name:  atomgit.String("Alice"),
+					Email: atomgit.String("alice@example.com"),
+				},
+			},
+		}
+	}
+
+	commits := []*atomgit.RepositoryCommit{
+		commit("a", "fix\n\nSigned-off-by: Alice <Alice@Example.com>"),
+		commit("b", "fix\n\nSigned-off-by: Bob <bob@example.com>"),
+		commit("c", "fix"),
+		commit("d", "fix\n\nSigned-off-by: Alice <alice@users.noreply.atomgit.com>"),
+	}
+
+	cases := map[string]string{
+		matchByEmail:        "bcd",
+		matchByName:         "bc",
+		matchByNameOrEmail:  "bc",
+		matchByNameAndEmail: "bcd",
+	}
+
+	for match, want := range cases {
+		cfg := dcoConfig{Match: match}
+
+		got := ""
+		for _, c := range getCommitsNotSignedOff(commits, &cfg) {
+			got += c.GetSHA()
+		}
+
+		if got != want {
+			t.Errorf("match by %s: got %s, want %s", match, got, want)
+		}
+	}
+}
@@ -6,7 +6,10 @@ import (
 
 	"github.com/opensourceways/community-robot-lib/atomgitclient"
 
+	"github.com/opensourceways/community-robot-lib/interrupts"
+	"github.com/opensourceways/community-robot-lib/kafka"
 	"github.com/opensourceways/community-robot-lib/logrusutil"
+	"github.com/opensourceways/community-robot-lib/mq"
 	liboptions "github.com/opensourceways/community-robot-lib/options"
 	framework "github.com/opensourceways/community-robot-lib/robot-atomgit-framework"
 	"github.com/opensourceways/community-robot-lib/secret"
@@ -16,9 +19,17 @@ import (
 type options struct {
 	service liboptions.ServiceOptions
 	atomgit liboptions.AtomGitOptions
+	mq      liboptions.MQOptions
+
+	claTokenPath     string
+	signingTokenPath string
 }
 
 func (o *options) Validate() error {
+	if err := o.mq.Validate(); err != nil {
+		return err
+	}
+
 	if err := o.service.Validate(); err != nil {
 		return err
 	}
@@ -31,6 +42,9 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 
 	o.atomgit.AddFlags(fs)
 	o.service.AddFlags(fs)
+	o.mq.AddFlags(fs)
+	fs.StringVar(&o.claTokenPath, "cla-token-path", "", "Path to the file containing the token to access the cla service.")
+	fs.StringVar(&o.signingTokenPath, "signing-token-path", "", "Path to the file containing the token which the cla service uses to notify the signing. The signing endpoint is disabled if it is not set.")
 
 	err := fs.Parse(args)
 	if err != nil {
@@ -48,15 +62,42 @@ func main() {
 	}
 
 	secretAgent := new(secret.Agent)
-	if err := secretAgent.Start([]string{o.atomgit.TokenPath}); err != nil {
+	secrets := append([]string{o.atomgit.TokenPath}, o.service.SecretPaths()...)
+	if o.claTokenPath != "" {
+		secrets = append(secrets, o.claTokenPath)
+	}
+
+	if o.signingTokenPath != "" {
+		secrets = append(secrets, o.signingTokenPath)
+	}
+
+	if err := secretAgent.Start(secrets); err != nil {
 		logrus.WithError(err).Fatal("Error starting secret agent.")
 	}
 
 	defer secretAgent.Stop()
 
-	c := atomgitclient.NewClient(secretAgent.GetTokenGenerator(o.atomgit.TokenPath))
+	o.service.LoadSecrets(secretAgent)
+
+	c := framework.WrapClient(atomgitclient.NewClient(secretAgent.GetTokenGenerator(o.atomgit.TokenPath)), o.service)
+
+	var getCLAToken func() []byte
+	if o.claTokenPath != "" {
+		getCLAToken = secretAgent.GetTokenGenerator(o.claTokenPath)
+	}
+
+	var getSigningToken func() []byte
+	if o.signingTokenPath != "" {
+		getSigningToken = secretAgent.GetTokenGenerator(o.signingTokenPath)
+	}
+
+	r := newRobot(c, getCLAToken, getSigningToken)
+
+	interrupts.OnInterrupt(r.wait)
 
-	r := newRobot(c)
+	consume := framework.ConsumeByOptions(func() mq.MQ {
+		return kafka.NewMQ(mq.Addresses(o.mq.AddressList()...))
+	}, &o.mq)
 
-	framework.Run(r, o.service, o.atomgit)
+	framework.Run(r, o.service, o.atomgit, consume, framework.WithClient(c))
 }
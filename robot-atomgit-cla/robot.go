@@ -1,12 +1,11 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"net/http"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -36,20 +35,48 @@ type iClient interface {
 
 	GetPRCommits(pr *atomgitclient.PRIssue) ([]*atomgit.RepositoryCommit, error)
 	GetPRComments(pr *atomgitclient.PRIssue) ([]*atomgit.PullRequestComment, error)
+
+	GetSinglePR(pr *atomgitclient.PRIssue) (*atomgit.PullRequest, error)
+	GetPullRequests(pr *atomgitclient.PRIssue) ([]*atomgit.PullRequest, error)
+	GetRepos(org string) ([]*atomgit.Repository, error)
 }
 
-func newRobot(cli iClient) *robot {
-	return &robot{cli: cli}
+func newRobot(cli iClient, getToken, getSigningToken func() []byte) *robot {
+	return &robot{
+		cli:             cli,
+		cache:           newSigningCache(),
+		getToken:        getToken,
+		getSigningToken: getSigningToken,
+		wg:              &sync.WaitGroup{},
+	}
 }
 
 type robot struct {
-	cli iClient
+	cli   iClient
+	cache *signingCache
+
+	// getToken returns the token to access the cla service.
+	getToken func() []byte
+
+	// getSigningToken returns the token which the cla service uses to notify the signing.
+	getSigningToken func() []byte
+
+	// wg tracks the pull requests being checked after the signing.
+	wg *sync.WaitGroup
 }
 
 func (bot *robot) NewConfig() config.Config {
 	return &configuration{}
 }
 
+// WithContext implements framework.ContextRobot.
+func (bot *robot) WithContext(ctx context.Context) framework.Robot {
+	r := *bot
+	r.cli = framework.BindClient(bot.cli, ctx)
+
+	return &r
+}
+
 func (bot *robot) getConfig(cfg config.Config, org, repo string) (*botConfig, error) {
 	c, ok := cfg.(*configuration)
 	if !ok {
@@ -66,6 +93,7 @@ func (bot *robot) getConfig(cfg config.Config, org, repo string) (*botConfig, er
 func (bot *robot) RegisterEventHandler(f framework.HandlerRegister) {
 	f.RegisterPullRequestHandler(bot.handlePullRequest)
 	f.RegisterReviewCommentEventHandler(bot.handlePullRequestReviewComment)
+	f.RegisterIssueCommentHandler(bot.handleIssueComment)
 }
 
 func (bot *robot) handlePullRequest(e *atomgit.PullRequestEvent, c config.Config, log *logrus.Entry) error {
@@ -105,6 +133,35 @@ func (bot *robot) handlePullRequestReviewComment(e *atomgit.PullRequestReviewCom
 	return bot.handle(org, repo, e.GetPullRequest(), cfg, true, log)
 }
 
+// handleIssueComment handles "/check-cla" which is commented on the pull request
+// and delivered as the comment of issue.
+func (bot *robot) handleIssueComment(e *atomgit.IssueCommentEvent, c config.Config, log *logrus.Entry) error {
+	if e.GetAction() != atomgit.ActionStateCreated || !e.GetIssue().IsPullRequest() {
+		return nil
+	}
+
+	if !checkCLARe.MatchString(e.GetComment().GetBody()) {
+		return nil
+	}
+
+	org, repo := e.GetRepo().GetOrgAndRepo()
+	cfg, err := bot.getConfig(c, org, repo)
+	if err != nil {
+		return err
+	}
+
+	pr, err := bot.cli.GetSinglePR(atomgitclient.BuildPRIssue(org, repo, e.GetIssue().GetNumber()))
+	if err != nil {
+		return err
+	}
+
+	if pr.GetState() != "opened" && pr.GetState() != "open" {
+		return nil
+	}
+
+	return bot.handle(org, repo, pr, cfg, true, log)
+}
+
 func (bot *robot) handle(
 	org, repo string,
 	pr *atomgit.PullRequest,
@@ -114,33 +171,47 @@ func (bot *robot) handle(
 ) error {
 	p := atomgitclient.BuildPRIssue(org, repo, pr.GetNumber())
 
-	unsigned, err := bot.getPRCommitsAbout(p, cfg)
+	commits, err := bot.getPRCommits(p)
 	if err != nil {
 		return err
 	}
 
+	var unsigned []*atomgit.RepositoryCommit
+	var results []signingResult
+	if cfg.isDCO() {
+		unsigned = getCommitsNotSignedOff(commits, &cfg.DCO)
+	} else if unsigned, results, err = bot.getUnsignedCommits(commits, cfg); err != nil {
+		return err
+	}
+
+	labelYes, labelNo := cfg.labels()
+
 	labels := sets.NewString()
 	for _, lb := range pr.GetLabels() {
 		labels.Insert(*lb.Name)
 	}
-	hasCLAYes := labels.Has(cfg.CLALabelYes)
-	hasCLANo := labels.Has(cfg.CLALabelNo)
+	hasYes := labels.Has(labelYes)
+	hasNo := labels.Has(labelNo)
 
 	deleteSignGuide(p, bot.cli, cfg)
 
 	if len(unsigned) == 0 {
-		if hasCLANo {
-			if err := bot.cli.RemovePRLabel(p, cfg.CLALabelNo); err != nil {
-				log.WithError(err).Warningf("Could not remove %s label.", cfg.CLALabelNo)
+		if hasNo {
+			if err := bot.cli.RemovePRLabel(p, labelNo); err != nil {
+				log.WithError(err).Warningf("Could not remove %s label.", labelNo)
 			}
 		}
 
-		if !hasCLAYes {
-			if err := bot.cli.AddPRLabel(p, cfg.CLALabelYes); err != nil {
-				log.WithError(err).Warningf("Could not add %s label.", cfg.CLALabelYes)
+		if !hasYes {
+			if err := bot.cli.AddPRLabel(p, labelYes); err != nil {
+				log.WithError(err).Warningf("Could not add %s label.", labelYes)
 			}
 
 			if notifyAuthorIfSigned {
+				if cfg.isDCO() {
+					return bot.cli.CreatePRComment(p, alreadySignedOff(pr.GetUser().GetLogin()))
+				}
+
 				return bot.cli.CreatePRComment(p, alreadySigned(pr.GetUser().GetLogin()))
 			}
 		}
@@ -148,78 +219,88 @@ func (bot *robot) handle(
 		return nil
 	}
 
-	if hasCLAYes {
-		if err := bot.cli.RemovePRLabel(p, cfg.CLALabelYes); err != nil {
-			log.WithError(err).Warningf("Could not remove %s label.", cfg.CLALabelYes)
+	if hasYes {
+		if err := bot.cli.RemovePRLabel(p, labelYes); err != nil {
+			log.WithError(err).Warningf("Could not remove %s label.", labelYes)
 		}
 	}
 
-	if !hasCLANo {
-		if err := bot.cli.AddPRLabel(p, cfg.CLALabelNo); err != nil {
-			log.WithError(err).Warningf("Could not add %s label.", cfg.CLALabelNo)
+	if !hasNo {
+		if err := bot.cli.AddPRLabel(p, labelNo); err != nil {
+			log.WithError(err).Warningf("Could not add %s label.", labelNo)
 		}
 	}
 
-	return bot.cli.CreatePRComment(p, signGuide(cfg.SignURL, generateUnSignComment(unsigned), cfg.FAQURL, cfg))
+	if cfg.isDCO() {
+		return bot.cli.CreatePRComment(p, signOffGuide(generateUnSignComment(unsigned), &cfg.DCO))
+	}
+
+	cInfo := generateUnSignComment(unsigned) + "\n\n" + generateSigningResults(results)
+
+	return bot.cli.CreatePRComment(p, signGuide(cfg.SignURL, cInfo, cfg.FAQURL, cfg))
 }
 
-func (bot *robot) getPRCommitsAbout(
-	p *atomgitclient.PRIssue,
-	cfg *botConfig,
-) ([]*atomgit.RepositoryCommit, error) {
-	// add retry logic
-	var prCommits []*atomgit.RepositoryCommit
-	retryTimes := 0
-	for {
-		commits, err := bot.cli.GetPRCommits(p)
-		if err != nil {
-			retryTimes += 1
+func (bot *robot) getPRCommits(p *atomgitclient.PRIssue) ([]*atomgit.RepositoryCommit, error) {
+	var commits []*atomgit.RepositoryCommit
+	var err error
 
+	for i := 0; i < 3; i++ {
+		if i > 0 {
 			// take a sleep before next api call
 			time.Sleep(1000 * time.Millisecond)
-			continue
 		}
 
-		if err == nil || retryTimes >= 2 {
-			prCommits = commits
+		if commits, err = bot.cli.GetPRCommits(p); err == nil {
 			break
 		}
 	}
 
-	if len(prCommits) == 0 {
-		return nil, fmt.Errorf("commits is empty, cla cannot be checked")
+	if err != nil {
+		return nil, err
 	}
 
-	result := map[string]bool{}
-	unsigned := make([]*atomgit.RepositoryCommit, 0, len(prCommits))
-	for i := range prCommits {
-		c := prCommits[i]
-		email := strings.Trim(getAuthorOfCommit(c, cfg), " ")
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("commits is empty, cla cannot be checked")
+	}
 
-		if !utils.IsValidEmail(email) {
-			unsigned = append(unsigned, c)
-			continue
-		}
+	return commits, nil
+}
 
-		if v, ok := result[email]; ok {
-			if !v {
-				unsigned = append(unsigned, c)
+// getUnsignedCommits returns the commits of which the author or some co-authors
+// have not signed the cla, and the results of all the contributors.
+func (bot *robot) getUnsignedCommits(
+	prCommits []*atomgit.RepositoryCommit,
+	cfg *botConfig,
+) ([]*atomgit.RepositoryCommit, []signingResult, error) {
+	emails := make([][]string, len(prCommits))
+	toCheck := sets.NewString()
+	for i, c := range prCommits {
+		emails[i] = getEmailsOfCommit(c, cfg)
+
+		for _, e := range emails[i] {
+			if utils.IsValidEmail(e) {
+				toCheck.Insert(e)
 			}
-			continue
 		}
+	}
 
-		b, err := isSigned(email, cfg.CheckURL)
-		if err != nil {
-			return nil, err
-		}
+	result, err := newCLAChecker(cfg, bot.cache, bot.getToken).Check(toCheck.List())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	unsigned := make([]*atomgit.RepositoryCommit, 0, len(prCommits))
+	for i, c := range prCommits {
+		for _, e := range emails[i] {
+			if !result[e] {
+				unsigned = append(unsigned, c)
 
-		result[email] = b
-		if !b {
-			unsigned = append(unsigned, c)
+				break
+			}
 		}
 	}
 
-	return unsigned, nil
+	return unsigned, genSigningResults(prCommits, emails, result, cfg), nil
 }
 
 func getAuthorOfCommit(c *atomgit.RepositoryCommit, cfg *botConfig) string {
@@ -238,39 +319,6 @@ func getAuthorOfCommit(c *atomgit.RepositoryCommit, cfg *botConfig) string {
 	return c.Commit.GetAuthor().GetEmail()
 }
 
-func isSigned(email, url string) (bool, error) {
-	endpoint := fmt.Sprintf("%s?email=%s", url, email)
-
-	resp, err := http.Get(endpoint)
-	if err != nil {
-		return false, err
-	}
-	defer func(Body io.ReadCloser) {
-		_ = Body.Close()
-	}(resp.Body)
-
-	rb, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return false, err
-	}
-	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		return false, fmt.Errorf("response has status %q and body %q", resp.Status, string(rb))
-	}
-
-	type signingInfo struct {
-		Signed bool `json:"signed"`
-	}
-	var v struct {
-		Data signingInfo `json:"data"`
-	}
-
-	if err := json.Unmarshal(rb, &v); err != nil {
-		return false, fmt.Errorf("unmarshal failed: %s", err.Error())
-	}
-
-	return v.Data.Signed, nil
-}
-
 func deleteSignGuide(p *atomgitclient.PRIssue, c iClient, cfg *botConfig) {
 	v, err := c.GetPRComments(p)
 	if err != nil {
@@ -278,6 +326,10 @@ func deleteSignGuide(p *atomgitclient.PRIssue, c iClient, cfg *botConfig) {
 	}
 
 	prefix := signGuideTitle(cfg)
+	if cfg.isDCO() {
+		prefix = signOffGuideTitle
+	}
+
 	prefixOld := "Thanks for your pull request. Before we can look at your pull request, you'll need to sign a Contributor License Agreement (CLA)."
 	fn := func(s string) bool {
 		return strings.HasPrefix(s, prefix) || strings.HasPrefix(s, prefixOld)
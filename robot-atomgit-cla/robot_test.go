@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/opensourceways/community-robot-lib/atomgitclient"
+	"github.com/opensourceways/community-robot-lib/config"
+	"github.com/opensourceways/community-robot-lib/fakeatomgit"
+	framework "github.com/opensourceways/community-robot-lib/robot-atomgit-framework"
+	sdk "github.com/opensourceways/go-atomgit/atomgit"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	testOrg  = "openeuler"
+	testRepo = "community"
+
+	testConfig = `
+config_items:
+  - repos:
+      - openeuler
+    cla_label_yes: openeuler-cla/yes
+    cla_label_no: openeuler-cla/no
+    check_url: %s
+    sign_url: https://clasign.osinfra.cn
+    faq_url: https://clasign.osinfra.cn/faq
+`
+)
+
+// claService is the fake cla service.
+type claService struct {
+	mut    sync.Mutex
+	signed sets.String
+}
+
+func (s *claService) sign(email string) {
+	s.mut.Lock()
+	s.signed.Insert(email)
+	s.mut.Unlock()
+}
+
+func (s *claService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mut.Lock()
+	signed := s.signed.Has(r.URL.Query().Get("email"))
+	s.mut.Unlock()
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"data": map[string]bool{"signed": signed},
+	})
+}
+
+type testEnv struct {
+	t   *testing.T
+	s   *fakeatomgit.Server
+	cla *claService
+	bot *robot
+	h   framework.WebhookHandler
+
+	hookURL    string
+	signingURL string
+}
+
+func newTestEnv(t *testing.T) (*testEnv, int) {
+	cla := &claService{signed: sets.NewString()}
+	claServer := httptest.NewServer(cla)
+	t.Cleanup(claServer.Close)
+
+	s := fakeatomgit.NewServer("openeuler-ci-bot")
+	n := s.AddPullRequest(testOrg, testRepo, fakeatomgit.PullRequest{
+		Title:  "add sig",
+		Author: "bob",
+		Labels: []string{"openeuler-cla/no"},
+		Commits: []*sdk.RepositoryCommit{{
+			SHA: sdk.String("1234567890abcdef"),
+			Commit: &sdk.Commit{
+				Message: sdk.String("add sig"),
+				Author:  &sdk.CommitAuthor{Email: sdk.String("bob@example.com")},
+			},
+		}},
+	})
+
+	api := httptest.NewServer(s)
+	t.Cleanup(api.Close)
+
+	cli, err := atomgitclient.NewClientWithEndpoint(func() []byte { return []byte("token") }, api.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bot := newRobot(cli, nil, func() []byte { return []byte("signing-token") })
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(fmt.Sprintf(testConfig, claServer.URL)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	agent := config.NewConfigAgent(bot.NewConfig)
+	if err := agent.Start(path); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(agent.Stop)
+
+	h := framework.NewWebhookHandler(bot, &agent, nil)
+	hook := httptest.NewServer(h)
+	t.Cleanup(hook.Close)
+
+	mux := http.NewServeMux()
+	bot.RegisterEndpoints(mux, func() config.Config {
+		_, c := agent.GetConfig()
+
+		return c
+	})
+	endpoint := httptest.NewServer(mux)
+	t.Cleanup(endpoint.Close)
+
+	return &testEnv{
+		t: t, s: s, cla: cla, bot: bot, h: h,
+		hookURL:    hook.URL,
+		signingURL: endpoint.URL + SigningPath,
+	}, n
+}
+
+func (env *testEnv) notifySigning(token, email string) int {
+	body, _ := json.Marshal(map[string]string{"email": email})
+
+	req, err := http.NewRequest(http.MethodPost, env.signingURL, bytes.NewReader(body))
+	if err != nil {
+		env.t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		env.t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	env.bot.wait()
+
+	return resp.StatusCode
+}
+
+func (env *testEnv) labels(n int) sets.String {
+	return sets.NewString(env.s.GetLabels(testOrg, testRepo, n)...)
+}
+
+func TestCheckCLAComment(t *testing.T) {
+	env, n := newTestEnv(t)
+	env.cla.sign("bob@example.com")
+
+	e, err := env.s.CommentOnIssue(testOrg, testRepo, n, "bob", "/check-cla")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fakeatomgit.SendHook(env.hookURL, fakeatomgit.EventTypeIssueComment, e); err != nil {
+		t.Fatal(err)
+	}
+
+	env.h.Wait()
+
+	if v := env.labels(n); !v.Has("openeuler-cla/yes") || v.Has("openeuler-cla/no") {
+		t.Errorf("labels = %v, the cla should be signed", v.List())
+	}
+}
+
+func TestSigningNotification(t *testing.T) {
+	env, n := newTestEnv(t)
+
+	if code := env.notifySigning("invalid", "bob@example.com"); code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, the invalid token should be rejected", code)
+	}
+
+	env.cla.sign("bob@example.com")
+
+	if code := env.notifySigning("signing-token", "bob@example.com"); code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d", code, http.StatusAccepted)
+	}
+
+	if v := env.labels(n); !v.Has("openeuler-cla/yes") || v.Has("openeuler-cla/no") {
+		t.Errorf("labels = %v, the pr should be re-checked after signing", v.List())
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/opensourceways/community-robot-lib/atomgitclient"
+	"github.com/opensourceways/community-robot-lib/config"
+	"github.com/opensourceways/community-robot-lib/utils"
+	"github.com/opensourceways/go-atomgit/atomgit"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// SigningPath is the endpoint which the cla service calls after someone signs the cla.
+// The request is {"email":"xxx"} with the header of "Authorization: Bearer <token>".
+const SigningPath = "/cla-signed"
+
+// RegisterEndpoints serves the signing endpoint if the token of it is set.
+func (bot *robot) RegisterEndpoints(mux *http.ServeMux, getConfig func() config.Config) {
+	if bot.getSigningToken == nil {
+		return
+	}
+
+	mux.HandleFunc(SigningPath, func(w http.ResponseWriter, r *http.Request) {
+		bot.serveSigning(w, r, getConfig())
+	})
+}
+
+func (bot *robot) serveSigning(w http.ResponseWriter, r *http.Request, c config.Config) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "405 Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if want := bot.getSigningToken(); len(want) == 0 || subtle.ConstantTimeCompare([]byte(token), want) != 1 {
+		http.Error(w, "401 Unauthorized", http.StatusUnauthorized)
+
+		return
+	}
+
+	var req struct {
+		Email string `json:"email"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || !utils.IsValidEmail(req.Email) {
+		http.Error(w, "400 Bad Request: invalid email", http.StatusBadRequest)
+
+		return
+	}
+
+	cfg, ok := c.(*configuration)
+	if !ok {
+		http.Error(w, "500 Internal Server Error: can't convert to configuration", http.StatusInternalServerError)
+
+		return
+	}
+
+	log := logrus.WithField("email", req.Email)
+	log.Info("re-check the pull requests after signing")
+
+	bot.wg.Add(1)
+	go func() {
+		defer bot.wg.Done()
+
+		if err := bot.handleSigning(req.Email, cfg, log); err != nil {
+			log.WithError(err).Error("re-check the pull requests")
+		}
+	}()
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (bot *robot) wait() {
+	bot.wg.Wait()
+}
+
+// handleSigning re-checks the open pull requests which are not signed and
+// have commits of email in all the repositories configured.
+func (bot *robot) handleSigning(email string, cfg *configuration, log *logrus.Entry) error {
+	mErr := utils.NewMultiErrors()
+
+	for _, orgRepo := range bot.listRepos(cfg, log) {
+		org, repo := orgRepo[0], orgRepo[1]
+
+		bc := cfg.configFor(org, repo)
+		if bc == nil || bc.isDCO() {
+			continue
+		}
+
+		prs, err := bot.cli.GetPullRequests(atomgitclient.BuildPRIssue(org, repo, 0))
+		if err != nil {
+			mErr.AddError(err)
+
+			continue
+		}
+
+		for _, pr := range prs {
+			if !hasLabel(pr, bc.CLALabelNo) {
+				continue
+			}
+
+			p := atomgitclient.BuildPRIssue(org, repo, pr.GetNumber())
+
+			commits, err := bot.cli.GetPRCommits(p)
+			if err != nil {
+				mErr.AddError(err)
+
+				continue
+			}
+
+			if !hasCommitOf(commits, email, bc) {
+				continue
+			}
+
+			l := log.WithField("pr", fmt.Sprintf("%s/%s:%d", org, repo, pr.GetNumber()))
+			if err := bot.handle(org, repo, pr, bc, true, l); err != nil {
+				mErr.AddError(err)
+			}
+		}
+	}
+
+	return mErr.Err()
+}
+
+// listRepos returns the org and repo of the repositories configured.
+func (bot *robot) listRepos(cfg *configuration, log *logrus.Entry) [][2]string {
+	repos := sets.NewString()
+
+	for i := range cfg.ConfigItems {
+		for _, v := range cfg.ConfigItems[i].Repos {
+			if strings.Contains(v, "/") {
+				repos.Insert(v)
+
+				continue
+			}
+
+			items, err := bot.cli.GetRepos(v)
+			if err != nil {
+				log.WithError(err).Errorf("list the repositories of %s", v)
+
+				continue
+			}
+
+			for _, item := range items {
+				repos.Insert(v + "/" + item.GetName())
+			}
+		}
+	}
+
+	r := make([][2]string, 0, repos.Len())
+	for _, v := range repos.List() {
+		if org, repo, ok := strings.Cut(v, "/"); ok {
+			r = append(r, [2]string{org, repo})
+		}
+	}
+
+	return r
+}
+
+func hasLabel(pr *atomgit.PullRequest, label string) bool {
+	for _, v := range pr.Labels {
+		if v.GetName() == label {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasCommitOf(commits []*atomgit.RepositoryCommit, email string, cfg *botConfig) bool {
+	for _, c := range commits {
+		for _, v := range getEmailsOfCommit(c, cfg) {
+			if strings.EqualFold(v, email) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
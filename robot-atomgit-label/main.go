@@ -2,13 +2,15 @@ package main
 
 import (
 	"flag"
-	"time"
+	"os"
 
 	atomgitclient "github.com/opensourceways/community-robot-lib/atomgitclient"
 	_ "github.com/opensourceways/go-atomgit/atomgit"
 
 	//ss "../go-atomgit"
+	"github.com/opensourceways/community-robot-lib/kafka"
 	"github.com/opensourceways/community-robot-lib/logrusutil"
+	"github.com/opensourceways/community-robot-lib/mq"
 	liboptions "github.com/opensourceways/community-robot-lib/options"
 	framework "github.com/opensourceways/community-robot-lib/robot-atomgit-framework"
 	"github.com/opensourceways/community-robot-lib/secret"
@@ -18,9 +20,14 @@ import (
 type options struct {
 	service liboptions.ServiceOptions
 	atomgit liboptions.AtomGitOptions
+	mq      liboptions.MQOptions
 }
 
 func (o *options) Validate() error {
+	if err := o.mq.Validate(); err != nil {
+		return err
+	}
+
 	if err := o.service.Validate(); err != nil {
 		return err
 	}
@@ -33,6 +40,7 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 
 	o.atomgit.AddFlags(fs)
 	o.service.AddFlags(fs)
+	o.mq.AddFlags(fs)
 
 	_ = fs.Parse(args)
 
@@ -42,30 +50,26 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 func main() {
 	logrusutil.ComponentInit(botName)
 
-	//o := gatherOptions(flag.NewFlagSet(os.Args[0], flag.ExitOnError), os.Args[1:]...)
-	o := options{
-		service: liboptions.ServiceOptions{
-			Port:        8863,
-			ConfigFile:  "D:\\Project\\github\\ibfru\\atomgit-bot\\robot-atomgit-label\\local\\config.yaml",
-			GracePeriod: 300 * time.Second,
-		},
-		atomgit: liboptions.AtomGitOptions{
-			TokenPath: "D:\\Project\\github\\ibfru\\atomgit-bot\\robot-atomgit-label\\local\\token",
-		},
-	}
+	o := gatherOptions(flag.NewFlagSet(os.Args[0], flag.ExitOnError), os.Args[1:]...)
 	if err := o.Validate(); err != nil {
 		logrus.WithError(err).Fatal("Invalid options")
 	}
 
 	secretAgent := new(secret.Agent)
-	if err := secretAgent.Start([]string{o.atomgit.TokenPath}); err != nil {
+	if err := secretAgent.Start(append([]string{o.atomgit.TokenPath}, o.service.SecretPaths()...)); err != nil {
 		logrus.WithError(err).Fatal("Error starting secret agent.")
 	}
 
 	defer secretAgent.Stop()
 
-	c := atomgitclient.NewClient(secretAgent.GetTokenGenerator(o.atomgit.TokenPath))
+	o.service.LoadSecrets(secretAgent)
+
+	c := framework.WrapClient(atomgitclient.NewClient(secretAgent.GetTokenGenerator(o.atomgit.TokenPath)), o.service)
 	p := newRobot(c)
 
-	framework.Run(p, o.service, o.atomgit)
+	consume := framework.ConsumeByOptions(func() mq.MQ {
+		return kafka.NewMQ(mq.Addresses(o.mq.AddressList()...))
+	}, &o.mq)
+
+	framework.Run(p, o.service, o.atomgit, consume, framework.WithClient(c))
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/opensourceways/community-robot-lib/utils"
@@ -58,6 +59,14 @@ func (bot *robot) NewConfig() config.Config {
 	return &configuration{}
 }
 
+// WithContext implements framework.ContextRobot.
+func (bot *robot) WithContext(ctx context.Context) framework.Robot {
+	r := *bot
+	r.cli = framework.BindClient(bot.cli, ctx)
+
+	return &r
+}
+
 func (bot *robot) getConfig(cfg config.Config, org, repo string) (*botConfig, error) {
 	c, ok := cfg.(*configuration)
 	if !ok {
@@ -7,6 +7,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/yaml"
 
+	cache "github.com/opensourceways/atomgit-sig-file-cache/sdk"
 	"github.com/sirupsen/logrus"
 )
 
@@ -29,7 +30,7 @@ func decodeKeepBranchFile(content string, keepBranches map[string]sets.String, l
 		return
 	}
 
-	var m SigInfo
+	var m cache.SigInfo
 
 	if err = yaml.Unmarshal(c, &m); err != nil {
 		log.WithError(err).Error("code yaml file")
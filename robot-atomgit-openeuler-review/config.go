@@ -2,8 +2,10 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"regexp"
 	"strings"
+	"text/template"
 
 	"github.com/opensourceways/community-robot-lib/config"
 )
@@ -13,8 +15,13 @@ type pullRequestMergeMethod string
 const (
 	mergeMethodeMerge pullRequestMergeMethod = "merge"
 	mergeMethodSquash pullRequestMergeMethod = "squash"
+	mergeMethodRebase pullRequestMergeMethod = "rebase"
 )
 
+func (m pullRequestMergeMethod) isValid() bool {
+	return m == mergeMethodeMerge || m == mergeMethodSquash || m == mergeMethodRebase
+}
+
 type configuration struct {
 	ConfigItems []botConfig `json:"config_items,omitempty"`
 }
@@ -96,9 +103,17 @@ type botConfig struct {
 	MissingLabelsForMerge []string `json:"missing_labels_for_merge,omitempty"`
 
 	// MergeMethod is the method to merge PR.
-	// The default method of merge. Valid options are squash and merge.
+	// The default method of merge. Valid options are merge, squash and rebase.
 	MergeMethod pullRequestMergeMethod `json:"merge_method,omitempty"`
 
+	// SquashCommitMessage is the template of commit message when squashing PR.
+	// It is a text/template which can reference .Title, .Body, .Number, .Author, .URL
+	// and .Trailers which are the Reviewed-by and Signed-off-by lines of PR.
+	// The first line of result is the commit title and the rest is the commit body.
+	// The default message is the trailers if it is empty.
+	SquashCommitMessage string             `json:"squash_commit_message,omitempty"`
+	squashCommitTpl     *template.Template `json:"-"`
+
 	// UnableCheckingReviewerForPR is a switch used to check whether the pr has been set reviewers when it is open.
 	UnableCheckingReviewerForPR bool `json:"unable_checking_reviewer_for_pr,omitempty"`
 
@@ -120,10 +135,24 @@ func (c *botConfig) setDefault() {
 }
 
 func (c *botConfig) validate() error {
-	if m := c.MergeMethod; m != mergeMethodeMerge && m != mergeMethodSquash {
+	if m := c.MergeMethod; !m.isValid() {
 		return fmt.Errorf("unsupported merge method:%s", m)
 	}
 
+	if c.SquashCommitMessage != "" {
+		tpl, err := template.New("squash_commit_message").Parse(c.SquashCommitMessage)
+		if err == nil {
+			// the fields referenced are checked only when executing it.
+			err = tpl.Execute(io.Discard, squashCommitData{})
+		}
+
+		if err != nil {
+			return fmt.Errorf("invalid squash_commit_message, err:%s", err.Error())
+		}
+
+		c.squashCommitTpl = tpl
+	}
+
 	if c.CheckPermissionBasedOnSigOwners {
 		if c.SigsDir == "" {
 			return fmt.Errorf("missing sigs_dir")
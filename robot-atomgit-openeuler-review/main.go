@@ -2,13 +2,17 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"net/url"
 	"os"
+	"strings"
 
 	"github.com/opensourceways/community-robot-lib/atomgitclient"
 
 	cache "github.com/opensourceways/atomgit-sig-file-cache/sdk"
+	"github.com/opensourceways/community-robot-lib/kafka"
 	"github.com/opensourceways/community-robot-lib/logrusutil"
+	"github.com/opensourceways/community-robot-lib/mq"
 	liboptions "github.com/opensourceways/community-robot-lib/options"
 	framework "github.com/opensourceways/community-robot-lib/robot-atomgit-framework"
 	"github.com/opensourceways/community-robot-lib/secret"
@@ -18,8 +22,14 @@ import (
 type options struct {
 	service       liboptions.ServiceOptions
 	atomgit       liboptions.AtomGitOptions
+	mq            liboptions.MQOptions
 	cacheEndpoint string
 	maxRetries    int
+
+	// fallbackRepo is the community repository in the form of org/repo
+	// to read the sig info from when the cache service is unavailable.
+	fallbackRepo   string
+	fallbackBranch string
 }
 
 func (o *options) Validate() error {
@@ -27,6 +37,14 @@ func (o *options) Validate() error {
 		return err
 	}
 
+	if o.fallbackRepo != "" && len(strings.Split(o.fallbackRepo, "/")) != 2 {
+		return fmt.Errorf("invalid fallback repo: %s", o.fallbackRepo)
+	}
+
+	if err := o.mq.Validate(); err != nil {
+		return err
+	}
+
 	if err := o.service.Validate(); err != nil {
 		return err
 	}
@@ -39,8 +57,11 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 
 	o.atomgit.AddFlags(fs)
 	o.service.AddFlags(fs)
-	fs.StringVar(&o.cacheEndpoint, "cache-endpoint", "", "The endpoint of repo file cache")
+	o.mq.AddFlags(fs)
+	fs.StringVar(&o.cacheEndpoint, "cache-endpoint", "", "The endpoint of sig info cache, such as http://localhost:8840")
 	fs.IntVar(&o.maxRetries, "max-retries", 3, "The number of failed retry attempts to call the cache api")
+	fs.StringVar(&o.fallbackRepo, "fallback-repo", "", "The community repository, org/repo, to read the sig info from when the cache is unavailable")
+	fs.StringVar(&o.fallbackBranch, "fallback-branch", "master", "The branch of the fallback repo")
 
 	_ = fs.Parse(args)
 
@@ -56,16 +77,26 @@ func main() {
 	}
 
 	secretAgent := new(secret.Agent)
-	if err := secretAgent.Start([]string{o.atomgit.TokenPath}); err != nil {
+	if err := secretAgent.Start(append([]string{o.atomgit.TokenPath}, o.service.SecretPaths()...)); err != nil {
 		logrus.WithError(err).Fatal("Error starting secret agent.")
 	}
 
 	defer secretAgent.Stop()
 
-	c := atomgitclient.NewClient(secretAgent.GetTokenGenerator(o.atomgit.TokenPath))
+	o.service.LoadSecrets(secretAgent)
+
+	c := framework.WrapClient(atomgitclient.NewClient(secretAgent.GetTokenGenerator(o.atomgit.TokenPath)), o.service)
 	s := cache.NewSDK(o.cacheEndpoint, o.maxRetries)
+	if o.fallbackRepo != "" {
+		v := strings.Split(o.fallbackRepo, "/")
+		s.SetFallback(c, v[0], v[1], o.fallbackBranch)
+	}
 
 	p := newRobot(c, s)
 
-	framework.Run(p, o.service, o.atomgit)
+	consume := framework.ConsumeByOptions(func() mq.MQ {
+		return kafka.NewMQ(mq.Addresses(o.mq.AddressList()...))
+	}, &o.mq)
+
+	framework.Run(p, o.service, o.atomgit, consume, framework.WithClient(c))
 }
@@ -1,12 +1,14 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
+	cache "github.com/opensourceways/atomgit-sig-file-cache/sdk"
 	"github.com/opensourceways/community-robot-lib/atomgitclient"
 
 	"github.com/opensourceways/go-atomgit/atomgit"
@@ -24,7 +26,16 @@ const (
 	msgFrozenWithOwner    = "The target branch of PR has been frozen and it can be merge only by branch owners: @%s"
 )
 
-var regCheckPr = regexp.MustCompile(`(?mi)^/check-pr\s*$`)
+var (
+	regCheckPr = regexp.MustCompile(`(?mi)^/check-pr\s*$`)
+
+	errMergeMethodNotAllowed = errors.New("merge method is not allowed")
+)
+
+// isNoticeableMergeError means the error should be told to the author of PR.
+func isNoticeableMergeError(err error) bool {
+	return errors.Is(err, errMergeMethodNotAllowed) || strings.Contains(err.Error(), "there are conflicting files")
+}
 
 func (bot *robot) handleCheckPR(p *parameter) error {
 	if !regCheckPr.MatchString(p.commentContent) {
@@ -71,8 +82,7 @@ func (bot *robot) tryMerge(p *parameter, addComment bool) error {
 	}
 
 	if err := h.merge(); err != nil {
-		includeErr := "there are conflicting files"
-		if !strings.Contains(err.Error(), includeErr) {
+		if !isNoticeableMergeError(err) {
 			return err
 		}
 
@@ -97,8 +107,7 @@ func (bot *robot) handleLabelUpdate(p *parameter) error {
 
 	if _, ok := h.canMerge(p.log); ok {
 		if err := h.merge(); err != nil {
-			includeErr := "there are conflicting files"
-			if !strings.Contains(err.Error(), includeErr) {
+			if !isNoticeableMergeError(err) {
 				return err
 			}
 
@@ -139,6 +148,8 @@ func (m *mergeHelper) merge() error {
 	}
 
 	desc := m.genMergeDesc()
+	method := m.method
+	msg := fmt.Sprintf("\n%s", desc)
 
 	bodyStr := ""
 	if m.arg.prArg.Org == "openeuler" && m.arg.prArg.Repo == "kernel" {
@@ -153,33 +164,96 @@ func (m *mergeHelper) merge() error {
 			relatedOrg := strings.Split(syncRelatedPR, "/")[3]
 			relatedRepo := strings.Split(syncRelatedPR, "/")[4]
 			relatedPR, _ := m.cli.GetSinglePR(atomgitclient.BuildPRIssue(relatedOrg, relatedRepo, relatedPRNumber))
-			relatedDesc := relatedPR.Body
+			relatedDesc := relatedPR.GetBody()
 
 			bodyStr = fmt.Sprintf("\n%s \n%s \n \n%s", originPR, syncRelatedPR, relatedDesc)
 		} else {
 			bodyStr = m.arg.commentContent
 		}
 
-		return m.cli.MergePR(
-			m.arg.prArg,
-			fmt.Sprintf("\n%s \n \n%s \n \n%s \n%s",
-				fmt.Sprintf("Merge Pull Request from: @%s", m.arg.commentator),
-				bodyStr, fmt.Sprintf("Link:%s", m.arg.realPR.GetHTMLURL()), desc),
-			&atomgit.PullRequestOptions{
-				MergeMethod: string(m.arg.bcf.MergeMethod),
-				SHA:         *m.arg.realPR.MergeCommitSHA,
-			},
-		)
+		method = string(m.arg.bcf.MergeMethod)
+		msg = fmt.Sprintf("\n%s \n \n%s \n \n%s \n%s",
+			fmt.Sprintf("Merge Pull Request from: @%s", m.arg.commentator),
+			bodyStr, fmt.Sprintf("Link:%s", m.arg.realPR.GetHTMLURL()), desc)
 	}
 
-	return m.cli.MergePR(
-		m.arg.prArg,
-		fmt.Sprintf("\n%s", desc),
-		&atomgit.PullRequestOptions{
-			MergeMethod: string(m.method),
-			SHA:         *m.arg.realPR.MergeCommitSHA,
-		},
-	)
+	if err := m.checkMergeMethod(method); err != nil {
+		return err
+	}
+
+	opt := &atomgit.PullRequestOptions{
+		MergeMethod: method,
+		SHA:         *m.arg.realPR.MergeCommitSHA,
+	}
+
+	if method == string(mergeMethodSquash) && m.arg.bcf.squashCommitTpl != nil {
+		title, body, err := m.genSquashCommitMessage(desc)
+		if err != nil {
+			return err
+		}
+
+		opt.CommitTitle = title
+		msg = body
+	}
+
+	return m.cli.MergePR(m.arg.prArg, msg, opt)
+}
+
+// checkMergeMethod checks whether the method is allowed by the settings of repository.
+func (m *mergeHelper) checkMergeMethod(method string) error {
+	if !pullRequestMergeMethod(method).isValid() {
+		return fmt.Errorf("%w: %s is unsupported", errMergeMethodNotAllowed, method)
+	}
+
+	repo, err := m.cli.GetRepo(m.arg.prArg.Org, m.arg.prArg.Repo)
+	if err != nil {
+		return err
+	}
+
+	allowed := map[pullRequestMergeMethod]*bool{
+		mergeMethodeMerge: repo.AllowMergeCommit,
+		mergeMethodSquash: repo.AllowSquashMerge,
+		mergeMethodRebase: repo.AllowRebaseMerge,
+	}
+
+	// the method is allowed if the repository does not set it.
+	if v := allowed[pullRequestMergeMethod(method)]; v != nil && !*v {
+		return fmt.Errorf("%w: %s is disabled by the repository settings", errMergeMethodNotAllowed, method)
+	}
+
+	return nil
+}
+
+// squashCommitData is the data to execute the template of squash commit message.
+type squashCommitData struct {
+	Title    string
+	Body     string
+	Number   int
+	Author   string
+	URL      string
+	Trailers string
+}
+
+// genSquashCommitMessage returns the title and body of squash commit.
+func (m *mergeHelper) genSquashCommitMessage(trailers string) (string, string, error) {
+	pr := m.arg.realPR
+
+	buf := new(strings.Builder)
+	err := m.arg.bcf.squashCommitTpl.Execute(buf, squashCommitData{
+		Title:    pr.GetTitle(),
+		Body:     pr.GetBody(),
+		Number:   pr.GetNumber(),
+		Author:   m.arg.author,
+		URL:      pr.GetHTMLURL(),
+		Trailers: strings.TrimSpace(trailers),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	title, body, _ := strings.Cut(strings.TrimSpace(buf.String()), "\n")
+
+	return strings.TrimSpace(title), strings.TrimSpace(body), nil
 }
 
 func (m *mergeHelper) canMerge(log *logrus.Entry) ([]string, bool) {
@@ -295,7 +369,7 @@ func (m *mergeHelper) genMergeDesc() string {
 
 	f := func(comment *atomgit.PullRequestComment, reg *regexp.Regexp) bool {
 		return reg.MatchString(comment.GetBody()) &&
-			comment.UpdatedAt == comment.CreatedAt &&
+			comment.GetUpdatedAt().Equal(comment.GetCreatedAt()) &&
 			*comment.User.Login != m.arg.author
 	}
 
@@ -348,7 +422,7 @@ func (m *mergeHelper) genMergeDesc() string {
 			return ""
 		}
 
-		var s SigInfo
+		var s cache.SigInfo
 
 		if err = yaml.Unmarshal([]byte(c), &s); err != nil {
 			return ""
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	cache "github.com/opensourceways/atomgit-sig-file-cache/sdk"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/yaml"
@@ -61,7 +62,7 @@ func decodeSigInfoFile(content string, log *logrus.Entry) sets.String {
 		return owners
 	}
 
-	var m SigInfo
+	var m cache.SigInfo
 
 	if err = yaml.Unmarshal(c, &m); err != nil {
 		log.WithError(err).Error("code yaml file")
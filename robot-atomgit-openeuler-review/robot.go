@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/opensourceways/go-atomgit/atomgit"
@@ -27,6 +28,7 @@ type iClient interface {
 	GetPathContent(org, repo, path, branch string) (*atomgit.RepositoryContent, error)
 	GetPullRequestChanges(pr *atomgitclient.PRIssue) ([]*atomgit.CommitFile, error)
 
+	GetRepo(org, repo string) (*atomgit.Repository, error)
 	GetSinglePR(pr *atomgitclient.PRIssue) (*atomgit.PullRequest, error)
 	GetPullRequests(pr *atomgitclient.PRIssue) ([]*atomgit.PullRequest, error)
 	MergePR(pr *atomgitclient.PRIssue, commitMessage string, opt *atomgit.PullRequestOptions) error
@@ -52,6 +54,14 @@ func (bot *robot) NewConfig() config.Config {
 	return &configuration{}
 }
 
+// WithContext implements framework.ContextRobot.
+func (bot *robot) WithContext(ctx context.Context) framework.Robot {
+	r := *bot
+	r.cli = framework.BindClient(bot.cli, ctx)
+
+	return &r
+}
+
 func (bot *robot) getConfig(cfg config.Config, org, repo string) (*botConfig, error) {
 	c, ok := cfg.(*configuration)
 	if !ok {
@@ -84,7 +94,7 @@ func handleFlow(p *parameter, flow []flowFunc) error {
 	merr := utils.NewMultiErrors()
 	var err error
 	for i, j := 0, len(flow); i < j; i++ {
-		if err = flow[j](p); err != nil {
+		if err = flow[i](p); err != nil {
 			merr.AddError(err)
 		}
 	}
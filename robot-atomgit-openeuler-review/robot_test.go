@@ -0,0 +1,181 @@
+package main
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opensourceways/community-robot-lib/atomgitclient"
+	"github.com/opensourceways/community-robot-lib/config"
+	"github.com/opensourceways/community-robot-lib/fakeatomgit"
+	framework "github.com/opensourceways/community-robot-lib/robot-atomgit-framework"
+)
+
+const (
+	testOrg  = "openeuler"
+	testRepo = "infrastructure"
+	testBot  = "openeuler-ci-bot"
+
+	testConfig = `
+config_items:
+  - repos:
+      - openeuler/infrastructure
+    legal_operator: openeuler-ci-bot
+    branch_keeper:
+      owner: openeuler
+      repo: community
+      branch: master
+`
+)
+
+type testEnv struct {
+	t *testing.T
+	s *fakeatomgit.Server
+	h framework.WebhookHandler
+
+	hookURL string
+}
+
+// newTestEnv serves the review robot with the config and the fake AtomGit which
+// has a mergeable pull request with the labels.
+func newTestEnv(t *testing.T, cfg string, labels ...string) (*testEnv, int) {
+	s := fakeatomgit.NewServer(testBot)
+	// the review robot checks the timeline event of adding label
+	s.LabelEvent = "1231"
+	s.AddCollaborator(testOrg, testRepo, "reviewer", "write")
+	n := s.AddPullRequest(testOrg, testRepo, fakeatomgit.PullRequest{
+		Title:     "update readme",
+		Body:      "fix the typo",
+		Author:    "alice",
+		Base:      "master",
+		Head:      "readme",
+		Labels:    labels,
+		Mergeable: true,
+	})
+
+	api := httptest.NewServer(s)
+	t.Cleanup(api.Close)
+
+	cli, err := atomgitclient.NewClientWithEndpoint(func() []byte { return []byte("token") }, api.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bot := newRobot(cli, nil)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	agent := config.NewConfigAgent(bot.NewConfig)
+	if err := agent.Start(path); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(agent.Stop)
+
+	h := framework.NewWebhookHandler(bot, &agent, nil)
+	hook := httptest.NewServer(h)
+	t.Cleanup(hook.Close)
+
+	return &testEnv{t: t, s: s, h: h, hookURL: hook.URL}, n
+}
+
+func (env *testEnv) comment(n int, body string) {
+	e, err := env.s.CommentOnPullRequest(testOrg, testRepo, n, "reviewer", body)
+	if err != nil {
+		env.t.Fatal(err)
+	}
+
+	if err := fakeatomgit.SendHook(env.hookURL, fakeatomgit.EventTypeReviewComment, e); err != nil {
+		env.t.Fatal(err)
+	}
+
+	env.h.Wait()
+}
+
+func TestLGTMThenApproveMerges(t *testing.T) {
+	env, n := newTestEnv(t, testConfig)
+
+	env.comment(n, "/lgtm")
+
+	if _, merged := env.s.GetMerge(testOrg, testRepo, n); merged {
+		t.Fatal("pr should not be merged without approved label")
+	}
+
+	env.comment(n, "/approve")
+
+	m, merged := env.s.GetMerge(testOrg, testRepo, n)
+	if !merged {
+		t.Fatalf("pr should be merged, labels: %v", env.s.GetLabels(testOrg, testRepo, n))
+	}
+
+	if m.Method != string(mergeMethodeMerge) {
+		t.Errorf("merge method = %s, want %s", m.Method, mergeMethodeMerge)
+	}
+}
+
+func TestSquashCommitMessage(t *testing.T) {
+	cfg := testConfig + `    squash_commit_message: |
+      {{.Title}} (#{{.Number}})
+
+      {{.Body}}
+
+      {{.Trailers}}
+`
+	env, n := newTestEnv(t, cfg, "merge/squash")
+
+	env.comment(n, "/lgtm")
+	env.comment(n, "/approve")
+
+	m, merged := env.s.GetMerge(testOrg, testRepo, n)
+	if !merged {
+		t.Fatalf("pr should be merged, labels: %v", env.s.GetLabels(testOrg, testRepo, n))
+	}
+
+	if m.Method != string(mergeMethodSquash) {
+		t.Errorf("merge method = %s, want %s", m.Method, mergeMethodSquash)
+	}
+
+	if want := "update readme (#1)"; m.CommitTitle != want {
+		t.Errorf("commit title = %q, want %q", m.CommitTitle, want)
+	}
+
+	for _, v := range []string{"fix the typo", "Reviewed-by: @reviewer", "Signed-off-by: @reviewer"} {
+		if !strings.Contains(m.CommitMessage, v) {
+			t.Errorf("commit message %q should contain %q", m.CommitMessage, v)
+		}
+	}
+}
+
+func TestMergeMethodNotAllowed(t *testing.T) {
+	env, n := newTestEnv(t, testConfig, "merge/rebase")
+	env.s.SetMergeMethods(testOrg, testRepo, "merge", "squash")
+
+	env.comment(n, "/lgtm")
+	env.comment(n, "/approve")
+
+	if _, merged := env.s.GetMerge(testOrg, testRepo, n); merged {
+		t.Fatal("pr should not be merged by the method disabled")
+	}
+
+	comments := env.s.GetComments(testOrg, testRepo, n)
+	if last := comments[len(comments)-1]; !strings.Contains(last.Body, "can not be merged by rebase") {
+		t.Errorf("the author should be told, but the last comment is %q", last.Body)
+	}
+}
+
+func TestInvalidSquashCommitMessage(t *testing.T) {
+	cfg := configuration{ConfigItems: []botConfig{{
+		RepoFilter:          config.RepoFilter{Repos: []string{testOrg}},
+		BranchKeeper:        branchKeeper{Owner: testOrg, Repo: "community", Branch: "master"},
+		MergeMethod:         mergeMethodSquash,
+		SquashCommitMessage: "{{.Unknown}}",
+	}}}
+
+	if err := cfg.Validate(); err == nil {
+		t.Error("the template referencing an unknown field should be invalid")
+	}
+}
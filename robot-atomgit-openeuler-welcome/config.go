@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/opensourceways/community-robot-lib/config"
 )
@@ -61,10 +62,11 @@ type botConfig struct {
 	// CommandLink is the link to command help document page.
 	CommandLink string `json:"command_link" required:"true"`
 
-	// CommunityRepo is used to read file path
+	// CommunityRepo is the repository which has the sig files. It is in the form
+	// of org/repo, or only the name of repo if it belongs to the same org.
 	CommunityRepo string `json:"community_repo" required:"true"`
 
-	// Branch is used to read file path
+	// Branch is the branch of CommunityRepo
 	Branch string `json:"branch" required:"true"`
 
 	// FilePath is the path-owner-map file path
@@ -82,11 +84,34 @@ type botConfig struct {
 	// NoNeedToNotice means that no need to @ maintainers and committers in welcome message
 	NoNeedToNotice bool `json:"no_need_to_notice,omitempty"`
 
-	// reposSig is used to cache information
-	reposSig map[string]string
+	// WelcomeTemplates is the text/template files of welcome message.
+	// The default templates are used if not set.
+	WelcomeTemplates welcomeTemplates `json:"welcome_templates,omitempty"`
+
+	// RepoWelcomeTemplates overrides WelcomeTemplates for the repos. The key is org/repo.
+	RepoWelcomeTemplates map[string]welcomeTemplates `json:"repo_welcome_templates,omitempty"`
+
+	// Language is the language of welcome message, which can be en, zh or auto.
+	// It is decided by the title and body of PR/issue if it is auto. The default is en.
+	Language string `json:"language,omitempty"`
+
+	// Newcomer is the way to detect and mentor the newcomers who create pull requests.
+	Newcomer newcomerConfig `json:"newcomer,omitempty"`
+
+	// Triage checks whether the issues are filled in following the issue templates.
+	Triage triageConfig `json:"triage,omitempty"`
+
+	templates     welcomeTemplateSet
+	repoTemplates map[string]welcomeTemplateSet
 }
 
 func (c *botConfig) setDefault() {
+	if c.Language == "" {
+		c.Language = langEn
+	}
+
+	c.Newcomer.setDefault()
+	c.Triage.setDefault()
 }
 
 func (c *botConfig) validate() error {
@@ -106,5 +131,73 @@ func (c *botConfig) validate() error {
 		return fmt.Errorf("the branch configuration can not be empty")
 	}
 
+	if len(strings.Split(c.CommunityRepo, "/")) > 2 {
+		return fmt.Errorf("invalid community_repo: %s", c.CommunityRepo)
+	}
+
+	switch c.Language {
+	case langEn, langZh, langAuto:
+	default:
+		return fmt.Errorf("unsupported language: %s", c.Language)
+	}
+
+	if err := c.parseTemplates(); err != nil {
+		return err
+	}
+
+	if err := c.Newcomer.validate(); err != nil {
+		return err
+	}
+
 	return c.RepoFilter.Validate()
 }
+
+// sigURL returns the link to the directory of sig in the community repository.
+func (c *botConfig) sigURL(org, sig string) string {
+	repo := c.CommunityRepo
+	if !strings.Contains(repo, "/") {
+		repo = org + "/" + repo
+	}
+
+	return fmt.Sprintf("https://atomgit.com/%s/tree/%s/sig/%s", repo, c.Branch, sig)
+}
+
+func (c *botConfig) parseTemplates() error {
+	v, err := c.WelcomeTemplates.parse(defaultWelcomeTemplates)
+	if err != nil {
+		return err
+	}
+
+	c.templates = v
+	c.repoTemplates = make(map[string]welcomeTemplateSet, len(c.RepoWelcomeTemplates))
+
+	for repo, item := range c.RepoWelcomeTemplates {
+		if len(strings.Split(repo, "/")) != 2 {
+			return fmt.Errorf("invalid repo of welcome templates: %s", repo)
+		}
+
+		if v, err = item.parse(c.templates); err != nil {
+			return err
+		}
+
+		c.repoTemplates[repo] = v
+	}
+
+	return nil
+}
+
+// genWelcome generates the welcome message for org/repo. The texts are the title
+// and body of PR/issue to detect the language if it is auto.
+func (c *botConfig) genWelcome(org, repo string, data *welcomeData, texts ...string) (string, error) {
+	lang := c.Language
+	if lang == langAuto {
+		lang = detectLanguage(texts...)
+	}
+
+	templates, ok := c.repoTemplates[org+"/"+repo]
+	if !ok {
+		templates = c.templates
+	}
+
+	return templates.execute(lang, data)
+}
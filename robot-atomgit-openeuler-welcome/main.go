@@ -2,12 +2,16 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"net/url"
-	"time"
+	"os"
+	"strings"
 
 	cache "github.com/opensourceways/atomgit-sig-file-cache/sdk"
 	"github.com/opensourceways/community-robot-lib/atomgitclient"
+	"github.com/opensourceways/community-robot-lib/kafka"
 	"github.com/opensourceways/community-robot-lib/logrusutil"
+	"github.com/opensourceways/community-robot-lib/mq"
 	liboptions "github.com/opensourceways/community-robot-lib/options"
 	framework "github.com/opensourceways/community-robot-lib/robot-atomgit-framework"
 	"github.com/opensourceways/community-robot-lib/secret"
@@ -17,8 +21,14 @@ import (
 type options struct {
 	service       liboptions.ServiceOptions
 	atomgit       liboptions.AtomGitOptions
+	mq            liboptions.MQOptions
 	cacheEndpoint string
 	maxRetries    int
+
+	// fallbackRepo is the community repository in the form of org/repo
+	// to read the sig info from when the cache service is unavailable.
+	fallbackRepo   string
+	fallbackBranch string
 }
 
 func (o *options) Validate() error {
@@ -26,6 +36,14 @@ func (o *options) Validate() error {
 		return err
 	}
 
+	if o.fallbackRepo != "" && len(strings.Split(o.fallbackRepo, "/")) != 2 {
+		return fmt.Errorf("invalid fallback repo: %s", o.fallbackRepo)
+	}
+
+	if err := o.mq.Validate(); err != nil {
+		return err
+	}
+
 	if err := o.service.Validate(); err != nil {
 		return err
 	}
@@ -38,9 +56,12 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 
 	o.atomgit.AddFlags(fs)
 	o.service.AddFlags(fs)
+	o.mq.AddFlags(fs)
 	retry := 3
-	fs.StringVar(&o.cacheEndpoint, "cache-endpoint", "", "The endpoint of repo file cache")
+	fs.StringVar(&o.cacheEndpoint, "cache-endpoint", "", "The endpoint of sig info cache, such as http://localhost:8840")
 	fs.IntVar(&o.maxRetries, "max-retries", retry, "The number of failed retry attempts to call the cache api")
+	fs.StringVar(&o.fallbackRepo, "fallback-repo", "", "The community repository, org/repo, to read the sig info from when the cache is unavailable")
+	fs.StringVar(&o.fallbackBranch, "fallback-branch", "master", "The branch of the fallback repo")
 
 	_ = fs.Parse(args)
 	return o
@@ -49,36 +70,32 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 func main() {
 	logrusutil.ComponentInit(botName)
 
-	//o := gatherOptions(flag.NewFlagSet(os.Args[0], flag.ExitOnError), os.Args[1:]...)
-	o := options{
-		service: liboptions.ServiceOptions{
-			Port:        8833,
-			ConfigFile:  "D:\\Project\\github\\ibfru\\atomgit-bot\\robot-atomgit-openeuler-welcome\\local\\config.yaml",
-			GracePeriod: 300 * time.Second,
-		},
-		atomgit: liboptions.AtomGitOptions{
-			TokenPath:     "D:\\Project\\github\\ibfru\\atomgit-bot\\robot-atomgit-openeuler-welcome\\local\\token",
-			RepoCacheDir:  "",
-			CacheRepoOnPV: true,
-		},
-		cacheEndpoint: "http://localhost:8888/v1/file",
-		maxRetries:    1,
-	}
+	o := gatherOptions(flag.NewFlagSet(os.Args[0], flag.ExitOnError), os.Args[1:]...)
 	if err := o.Validate(); err != nil {
 		logrus.WithError(err).Fatal("Invalid options")
 	}
 
 	secretAgent := new(secret.Agent)
-	if err := secretAgent.Start([]string{o.atomgit.TokenPath}); err != nil {
+	if err := secretAgent.Start(append([]string{o.atomgit.TokenPath}, o.service.SecretPaths()...)); err != nil {
 		logrus.WithError(err).Fatal("Error starting secret agent.")
 	}
 
 	defer secretAgent.Stop()
 
-	c := atomgitclient.NewClient(secretAgent.GetTokenGenerator(o.atomgit.TokenPath))
+	o.service.LoadSecrets(secretAgent)
+
+	c := framework.WrapClient(atomgitclient.NewClient(secretAgent.GetTokenGenerator(o.atomgit.TokenPath)), o.service)
 	s := cache.NewSDK(o.cacheEndpoint, o.maxRetries)
+	if o.fallbackRepo != "" {
+		v := strings.Split(o.fallbackRepo, "/")
+		s.SetFallback(c, v[0], v[1], o.fallbackBranch)
+	}
 
 	p := newRobot(c, s)
 
-	framework.Run(p, o.service, o.atomgit)
+	consume := framework.ConsumeByOptions(func() mq.MQ {
+		return kafka.NewMQ(mq.Addresses(o.mq.AddressList()...))
+	}, &o.mq)
+
+	framework.Run(p, o.service, o.atomgit, consume, framework.WithClient(c))
 }
@@ -0,0 +1,398 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/opensourceways/community-robot-lib/utils"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	cache "github.com/opensourceways/atomgit-sig-file-cache/sdk"
+)
+
+const (
+	detectorIPB    = "ipb"
+	detectorSearch = "search"
+	detectorStore  = "store"
+	detectorNone   = "none"
+
+	defaultNewcomerLabel = "newcomer"
+	defaultIPBEndpoint   = "https://ipb.osinfra.cn/pulls"
+
+	defaultMentoringTemplate = `
+Hi ***{{.Author}}***, it seems to be your first pull request to the {{.CommunityName}} Community, thanks for your contribution!
+{{- if .Mentors}}
+@{{join .Mentors " , @"}} will help you with the review as the mentor.
+{{- end}}`
+)
+
+var defaultMentoring = template.Must(template.New("mentoring").Funcs(templateFuncs).Parse(defaultMentoringTemplate))
+
+// newcomerDetector checks whether the user has never contributed to the org.
+type newcomerDetector interface {
+	isNewcomer(org, login string) (bool, error)
+}
+
+type newcomerConfig struct {
+	// Detector is the way to detect the newcomer, which can be ipb, search, store or none.
+	// ipb asks the service at Endpoint for the pull requests of user, search looks for
+	// the merged pull requests of user in the org by the search api, and store records
+	// the users seen in StoreFile. The default is ipb.
+	Detector string `json:"detector,omitempty"`
+
+	// Endpoint is the api of ipb service.
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// StoreFile is the file to record the users seen when Detector is store.
+	StoreFile string `json:"store_file,omitempty"`
+
+	// Label is the label added to the pull request of newcomer. The default is newcomer.
+	Label string `json:"label,omitempty"`
+
+	// MentoringTemplate is the text/template file of message to the newcomer.
+	// The default message is used if it is not set.
+	MentoringTemplate string `json:"mentoring_template,omitempty"`
+
+	// AssignMentor assigns one of the mentors of sig to the pull request of newcomer.
+	AssignMentor bool `json:"assign_mentor,omitempty"`
+
+	mentoring *template.Template
+}
+
+func (c *newcomerConfig) setDefault() {
+	if c.Detector == "" {
+		c.Detector = detectorIPB
+	}
+
+	if c.Endpoint == "" {
+		c.Endpoint = defaultIPBEndpoint
+	}
+
+	if c.Label == "" {
+		c.Label = defaultNewcomerLabel
+	}
+}
+
+func (c *newcomerConfig) validate() error {
+	switch c.Detector {
+	case detectorIPB:
+		if _, err := url.ParseRequestURI(c.Endpoint); err != nil {
+			return fmt.Errorf("invalid endpoint of newcomer detector: %s", err.Error())
+		}
+
+	case detectorStore:
+		if c.StoreFile == "" {
+			return errors.New("missing store_file of newcomer detector")
+		}
+
+	case detectorSearch, detectorNone:
+	default:
+		return fmt.Errorf("unsupported newcomer detector: %s", c.Detector)
+	}
+
+	c.mentoring = defaultMentoring
+	if c.MentoringTemplate == "" {
+		return nil
+	}
+
+	b, err := os.ReadFile(c.MentoringTemplate)
+	if err != nil {
+		return err
+	}
+
+	t, err := template.New("mentoring").Funcs(templateFuncs).Parse(string(b))
+	if err != nil {
+		return fmt.Errorf("invalid mentoring template: %s, err: %s", c.MentoringTemplate, err.Error())
+	}
+
+	if err = t.Execute(new(strings.Builder), &mentoringData{Mentors: []string{"mentor"}}); err != nil {
+		return fmt.Errorf("invalid mentoring template: %s, err: %s", c.MentoringTemplate, err.Error())
+	}
+
+	c.mentoring = t
+
+	return nil
+}
+
+func (c *newcomerConfig) genMentoring(data *mentoringData) (string, error) {
+	b := new(strings.Builder)
+	if err := c.mentoring.Execute(b, data); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// mentoringData is the data which the mentoring template is executed with.
+type mentoringData struct {
+	Author        string
+	CommunityName string
+	Org           string
+	Repo          string
+
+	// Sig is empty if the repository belongs to no sig.
+	Sig string
+
+	// Mentors is the mentor assigned, or all the mentors of sig if not assigned.
+	Mentors []string
+}
+
+// getNewcomerDetector returns the detector configured, and nil if it is disabled.
+func (bot *robot) getNewcomerDetector(c *newcomerConfig) newcomerDetector {
+	switch c.Detector {
+	case detectorIPB:
+		return &ipbDetector{endpoint: c.Endpoint, hc: utils.NewHttpClient(3)}
+
+	case detectorSearch:
+		return &searchDetector{cli: bot.cli}
+
+	case detectorStore:
+		return bot.stores.get(c.StoreFile)
+	}
+
+	return nil
+}
+
+// isNewcomer checks whether the author of PR is a newcomer.
+func (bot *robot) isNewcomer(p *param) (bool, error) {
+	d := bot.getNewcomerDetector(&p.cnf.Newcomer)
+	if d == nil {
+		return false, nil
+	}
+
+	return d.isNewcomer(p.prIssue.Org, p.author)
+}
+
+// welcomeNewcomer labels the PR of newcomer, assigns a mentor and sends the mentoring message.
+func (bot *robot) welcomeNewcomer(p *param, rs *cache.RepoSig) error {
+	cfg := &p.cnf.Newcomer
+	mErr := utils.NewMultiErrors()
+
+	if err := bot.cli.AddPRLabel(p.prIssue, cfg.Label); err != nil {
+		mErr.AddError(err)
+	}
+
+	data := mentoringData{
+		Author:        p.author,
+		CommunityName: p.cnf.CommunityName,
+		Org:           p.prIssue.Org,
+		Repo:          p.prIssue.Repo,
+	}
+
+	if rs != nil {
+		data.Sig = rs.Sig
+		data.Mentors = mentorsOf(rs)
+	}
+
+	if cfg.AssignMentor && len(data.Mentors) != 0 {
+		mentor := pickMentor(data.Mentors, p.author)
+		if err := bot.cli.AssignPR(p.prIssue, []string{mentor}); err != nil {
+			mErr.AddError(err)
+		} else {
+			data.Mentors = []string{mentor}
+		}
+	}
+
+	comment, err := cfg.genMentoring(&data)
+	if err != nil {
+		mErr.AddError(err)
+	} else if err = bot.cli.CreatePRComment(p.prIssue, comment); err != nil {
+		mErr.AddError(err)
+	}
+
+	return mErr.Err()
+}
+
+func mentorsOf(rs *cache.RepoSig) []string {
+	if rs.Info == nil {
+		return nil
+	}
+
+	v := sets.New[string]()
+	for _, m := range rs.Info.Mentors {
+		if m.GiteeID != "" {
+			v.Insert(strings.ToLower(m.GiteeID))
+		}
+	}
+
+	return sets.List(v)
+}
+
+// pickMentor picks the same mentor for the same author, so that the newcomers
+// are distributed among the mentors.
+func pickMentor(mentors []string, author string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(author))
+
+	return mentors[h.Sum32()%uint32(len(mentors))]
+}
+
+// ipbDetector asks the ipb service for the pull requests of user in all the orgs.
+type ipbDetector struct {
+	endpoint string
+	hc       utils.HttpClient
+}
+
+func (d *ipbDetector) isNewcomer(_, login string) (bool, error) {
+	req, err := http.NewRequest(http.MethodGet, d.endpoint+"?author="+url.QueryEscape(login), nil)
+	if err != nil {
+		return false, err
+	}
+
+	var v struct {
+		Total int `json:"total"`
+	}
+
+	if _, err = d.hc.ForwardTo(req, &v); err != nil {
+		return false, err
+	}
+
+	return v.Total == 0, nil
+}
+
+// searchDetector looks for the merged pull requests of user in the org by the search api.
+type searchDetector struct {
+	cli iClient
+}
+
+func (d *searchDetector) isNewcomer(org, login string) (bool, error) {
+	v, err := d.cli.SearchIssues(fmt.Sprintf("is:pr is:merged author:%s org:%s", login, org))
+	if err != nil {
+		return false, err
+	}
+
+	return v.GetTotal() == 0, nil
+}
+
+// contributorStores shares the store of the same file among the configs.
+type contributorStores struct {
+	mut    sync.Mutex
+	stores map[string]*contributorStore
+}
+
+func (s *contributorStores) get(path string) *contributorStore {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if s.stores == nil {
+		s.stores = map[string]*contributorStore{}
+	}
+
+	v, ok := s.stores[path]
+	if !ok {
+		v = &contributorStore{path: path}
+		s.stores[path] = v
+	}
+
+	return v
+}
+
+// contributorStore records the users seen of each org in a json file. A user is
+// a newcomer only at the first time.
+type contributorStore struct {
+	path string
+
+	mut sync.Mutex
+	// seen maps the org to the logins in lower case.
+	seen map[string]sets.Set[string]
+}
+
+func (s *contributorStore) isNewcomer(org, login string) (bool, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if s.seen == nil {
+		if err := s.load(); err != nil {
+			return false, err
+		}
+	}
+
+	login = strings.ToLower(login)
+
+	v, ok := s.seen[org]
+	if !ok {
+		v = sets.New[string]()
+		s.seen[org] = v
+	}
+
+	if v.Has(login) {
+		return false, nil
+	}
+
+	v.Insert(login)
+
+	if err := s.save(); err != nil {
+		v.Delete(login)
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *contributorStore) load() error {
+	seen := map[string]sets.Set[string]{}
+
+	b, err := os.ReadFile(s.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if len(b) > 0 {
+		var v map[string][]string
+		if err = json.Unmarshal(b, &v); err != nil {
+			return fmt.Errorf("invalid contributor store: %s, err: %s", s.path, err.Error())
+		}
+
+		for org, logins := range v {
+			seen[org] = sets.New[string](logins...)
+		}
+	}
+
+	s.seen = seen
+
+	return nil
+}
+
+// save writes to a temporary file and renames it, so that the store is not corrupted by a crash.
+func (s *contributorStore) save() error {
+	v := make(map[string][]string, len(s.seen))
+	for org, logins := range s.seen {
+		v[org] = sets.List(logins)
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+
+	if _, err = tmp.Write(b); err == nil {
+		err = tmp.Close()
+	} else {
+		_ = tmp.Close()
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+
+	return err
+}
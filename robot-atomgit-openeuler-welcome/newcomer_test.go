@@ -0,0 +1,74 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opensourceways/community-robot-lib/fakeatomgit"
+)
+
+func TestNewcomer(t *testing.T) {
+	env := newTestEnv(t, testConfig+`    newcomer:
+      detector: search
+      assign_mentor: true
+`)
+
+	// veteran has contributed to the org before.
+	env.s.AddPullRequest(testOrg, "kernel", fakeatomgit.PullRequest{Title: "fix", Author: "veteran", Merged: true})
+
+	n := env.openPR("infrastructure", "newbie")
+
+	if labels := env.s.GetLabels(testOrg, "infrastructure", n); !strings.Contains(strings.Join(labels, ","), "newcomer") {
+		t.Errorf("the pr of newcomer should be labeled, labels = %v", labels)
+	}
+
+	if v := env.s.GetAssignees(testOrg, "infrastructure", n); len(v) != 1 || v[0] != "mentor" {
+		t.Errorf("the mentor should be assigned, assignees = %v", v)
+	}
+
+	comments := env.s.GetComments(testOrg, "infrastructure", n)
+	if len(comments) != 2 || !strings.Contains(comments[1].Body, "@mentor will help you") {
+		t.Errorf("comments = %v", comments)
+	}
+
+	n = env.openPR("infrastructure", "veteran")
+
+	for _, l := range env.s.GetLabels(testOrg, "infrastructure", n) {
+		if l == "newcomer" {
+			t.Error("the pr of veteran should not be labeled newcomer")
+		}
+	}
+
+	if comments := env.s.GetComments(testOrg, "infrastructure", n); len(comments) != 1 {
+		t.Errorf("only the welcome message should be sent to veteran, comments = %v", comments)
+	}
+}
+
+func TestContributorStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "contributors.json")
+
+	s := &contributorStore{path: path}
+
+	for i, want := range []bool{true, false} {
+		v, err := s.isNewcomer(testOrg, "Alice")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if v != want {
+			t.Errorf("newcomer at the %d time = %t, want %t", i+1, v, want)
+		}
+	}
+
+	// the users seen are loaded from the file.
+	s = &contributorStore{path: path}
+
+	if v, err := s.isNewcomer(testOrg, "alice"); err != nil || v {
+		t.Errorf("alice should not be a newcomer after reloading, err: %v", err)
+	}
+
+	if v, err := s.isNewcomer("src-openeuler", "alice"); err != nil || !v {
+		t.Errorf("alice should be a newcomer of the other org, err: %v", err)
+	}
+}
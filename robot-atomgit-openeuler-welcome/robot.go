@@ -1,11 +1,9 @@
 package main
 
 import (
+	"context"
 	"encoding/base64"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"regexp"
 	"strings"
 
@@ -20,22 +18,7 @@ import (
 	"sigs.k8s.io/yaml"
 )
 
-const (
-	botName        = "welcome"
-	welcomeMessage = `
-Hi ***%s***, welcome to the %s Community.
-I'm the Bot here serving you. You can find the instructions on how to interact with me at **[Here](%s)**.
-If you have any questions, please contact the SIG: [%s](https://gitee.com/openeuler/community/tree/master/sig/%s), and any of the maintainers: @%s`
-	welcomeMessage2 = `
-Hi ***%s***, welcome to the %s Community.
-I'm the Bot here serving you. You can find the instructions on how to interact with me at **[Here](%s)**.
-If you have any questions, please contact the SIG: [%s](https://gitee.com/openeuler/community/tree/master/sig/%s), and any of the maintainers: @%s, any of the committers: @%s`
-	welcomeMessage3 = `
-Hi ***%s***, welcome to the %s Community.
-I'm the Bot here serving you. You can find the instructions on how to interact with me at **[Here](%s)**.
-If you have any questions, please contact the SIG: [%s](https://gitee.com/openeuler/community/tree/master/sig/%s), and any of the maintainers.
-`
-)
+const botName = "welcome"
 
 type iClient interface {
 	GetRepositoryLabels(pr *atomgitclient.PRIssue) ([]string, error)
@@ -46,9 +29,12 @@ type iClient interface {
 
 	GetIssueLabels(is *atomgitclient.PRIssue) ([]string, error)
 	AddIssueLabel(is *atomgitclient.PRIssue, label []string) error
+	RemoveIssueLabel(is *atomgitclient.PRIssue, label string) error
 
 	CreatePRComment(pr *atomgitclient.PRIssue, comment string) error
 	CreateIssueComment(is *atomgitclient.PRIssue, comment string) error
+	ListIssueComments(is *atomgitclient.PRIssue) ([]*atomgit.IssueComment, error)
+	UpdateIssueComment(is *atomgitclient.PRIssue, commentID int64, c *atomgit.IssueComment) error
 	CreatePRCommentReply(pr *atomgitclient.PRIssue, comment, commentID string) error
 
 	ListCollaborator(pr *atomgitclient.PRIssue) ([]*atomgit.User, error)
@@ -58,21 +44,32 @@ type iClient interface {
 	GetPullRequestChanges(pr *atomgitclient.PRIssue) ([]*atomgit.CommitFile, error)
 
 	AssignPR(pr *atomgitclient.PRIssue, logins []string) error
+
+	SearchIssues(query string) (*atomgit.IssuesSearchResult, error)
 }
 
 func newRobot(cli iClient, cacheCli *cache.SDK) *robot {
-	return &robot{cli: cli, cacheCli: cacheCli}
+	return &robot{cli: cli, cacheCli: cacheCli, stores: &contributorStores{}}
 }
 
 type robot struct {
 	cli      iClient
 	cacheCli *cache.SDK
+	stores   *contributorStores
 }
 
 func (bot *robot) NewConfig() config.Config {
 	return &configuration{}
 }
 
+// WithContext implements framework.ContextRobot.
+func (bot *robot) WithContext(ctx context.Context) framework.Robot {
+	r := *bot
+	r.cli = framework.BindClient(bot.cli, ctx)
+
+	return &r
+}
+
 func (bot *robot) getConfig(cfg config.Config, org, repo string) (*botConfig, error) {
 	c, ok := cfg.(*configuration)
 	if !ok {
@@ -97,11 +94,15 @@ const (
 )
 
 type param struct {
-	prIssue *atomgitclient.PRIssue
-	cnf     *botConfig
-	log     *logrus.Entry
-	flag    int
-	author  string
+	prIssue  *atomgitclient.PRIssue
+	cnf      *botConfig
+	log      *logrus.Entry
+	flag     int
+	author   string
+	newcomer bool
+
+	// texts is the title and body of PR/issue
+	texts []string
 }
 
 func (bot *robot) handlePullRequest(e *atomgit.PullRequestEvent, pc config.Config, log *logrus.Entry) error {
@@ -121,13 +122,15 @@ func (bot *robot) handlePullRequest(e *atomgit.PullRequestEvent, pc config.Confi
 		author:  e.GetPullRequest().GetUser().GetLogin(),
 		cnf:     cfg,
 		log:     log,
+		texts:   []string{e.GetPullRequest().GetTitle(), e.GetPullRequest().GetBody()},
 	}
 
 	return bot.handle(p)
 }
 
 func (bot *robot) handleIssue(e *atomgit.IssuesEvent, pc config.Config, log *logrus.Entry) error {
-	if e.GetAction() != atomgit.ActionStateCreated {
+	action := e.GetAction()
+	if action != atomgit.ActionStateCreated && action != atomgitclient.ActionUpdated {
 		return nil
 	}
 
@@ -137,46 +140,47 @@ func (bot *robot) handleIssue(e *atomgit.IssuesEvent, pc config.Config, log *log
 		return err
 	}
 
+	if action == atomgitclient.ActionUpdated {
+		return bot.triage(e, cfg, log)
+	}
+
 	p := &param{
 		flag:    Issue,
 		prIssue: atomgitclient.BuildPRIssue(org, repo, e.GetIssue().GetNumber()),
 		author:  e.GetIssue().GetUser().GetLogin(),
 		cnf:     cfg,
 		log:     log,
+		texts:   []string{e.GetIssue().GetTitle(), e.GetIssue().GetBody()},
 	}
 
-	return bot.handle(p)
+	mErr := utils.NewMultiErrors()
+
+	if err = bot.handle(p); err != nil {
+		mErr.AddError(err)
+	}
+
+	if err = bot.triage(e, cfg, log); err != nil {
+		mErr.AddError(err)
+	}
+
+	return mErr.Err()
 }
 
 func (bot *robot) handle(p *param) error {
-	mErr := utils.NewMultiErrors()
-	if p.flag == PullRequest {
-		resp, err := http.Get(fmt.Sprintf("https://ipb.osinfra.cn/pulls?author=%s", p.author))
-		if err != nil {
-			mErr.AddError(err)
-		}
-		defer func(Body io.ReadCloser) {
-			_ = Body.Close()
-		}(resp.Body)
-		body, _ := io.ReadAll(resp.Body)
-		type T struct {
-			Total int `json:"total,omitempty"`
-		}
+	rs, err := bot.getSigOfRepo(p.prIssue.Org, p.prIssue.Repo)
+	if err != nil {
+		return err
+	}
 
-		var t T
-		err = json.Unmarshal(body, &t)
-		if err != nil {
-			mErr.AddError(err)
-		}
+	mErr := utils.NewMultiErrors()
 
-		if t.Total == 0 {
-			if err = bot.cli.AddPRLabel(p.prIssue, "newcomer"); err != nil {
-				mErr.AddError(err)
-			}
+	if p.flag == PullRequest {
+		if p.newcomer, err = bot.isNewcomer(p); err != nil {
+			p.log.WithError(err).Errorf("check whether %s is a newcomer", p.author)
 		}
 	}
 
-	sigName, comment, err := bot.genComment(p)
+	sigName, comment, err := bot.genComment(p, rs)
 	if err != nil {
 		return err
 	}
@@ -190,6 +194,17 @@ func (bot *robot) handle(p *param) error {
 		mErr.AddError(err)
 	}
 
+	if p.newcomer {
+		if err = bot.welcomeNewcomer(p, rs); err != nil {
+			mErr.AddError(err)
+		}
+	}
+
+	// the repository belongs to no sig.
+	if sigName == "" {
+		return mErr.Err()
+	}
+
 	label := fmt.Sprintf("sig/%s", sigName)
 	if n := 20; len(label) > n {
 		label = label[:n]
@@ -211,55 +226,69 @@ func (bot *robot) handle(p *param) error {
 	return mErr.Err()
 }
 
-func (bot *robot) genComment(p *param) (string, string, error) {
-	sigName, err := bot.getSigOfRepo(p.prIssue.Org, p.prIssue.Repo)
-	if err != nil {
-		return "", "", err
-	}
-
-	if sigName == "" {
-		return "", "", fmt.Errorf("cant get sig name of repo: %s/%s", p.prIssue.Org, p.prIssue.Repo)
-	}
+func (bot *robot) genComment(p *param, rs *cache.RepoSig) (string, string, error) {
+	org, repo := p.prIssue.Org, p.prIssue.Repo
 
-	if p.cnf.NoNeedToNotice {
-		return sigName, fmt.Sprintf(
-				welcomeMessage3, p.author, p.cnf.CommunityName, p.cnf.CommandLink, sigName, sigName),
-			nil
+	data := welcomeData{
+		Author:        p.author,
+		CommunityName: p.cnf.CommunityName,
+		CommandLink:   p.cnf.CommandLink,
+		Newcomer:      p.newcomer,
+		PullRequest:   p.flag == PullRequest,
 	}
 
-	// TODO use service
-	maintainers, committers, err := bot.getMaintainers(sigName, p)
-	if err != nil {
-		return "", "", err
+	if rs == nil {
+		p.log.Infof("the repo: %s/%s belongs to no sig", org, repo)
+	} else {
+		data.Sig = rs.Sig
+		data.SigURL = p.cnf.sigURL(org, rs.Sig)
 	}
 
-	if p.cnf.NeedAssign && p.prIssue.Number != 0 {
-		if err = bot.cli.AssignPR(p.prIssue, maintainers); err != nil {
+	if !p.cnf.NoNeedToNotice {
+		maintainers, committers, err := bot.getMaintainers(rs, p)
+		if err != nil {
 			return "", "", err
 		}
-	}
 
-	if len(committers) != 0 {
-		return sigName, fmt.Sprintf(
-			welcomeMessage2, p.author, p.cnf.CommunityName, p.cnf.CommandLink,
-			sigName, sigName, strings.Join(maintainers, " , @"), strings.Join(committers, " , @"),
-		), nil
+		if p.cnf.NeedAssign && p.flag == PullRequest && len(maintainers) != 0 {
+			if err = bot.cli.AssignPR(p.prIssue, maintainers); err != nil {
+				return "", "", err
+			}
+		}
+
+		data.Maintainers = maintainers
+		if len(maintainers) != 0 {
+			data.Committers = committers
+		}
 	}
 
-	return sigName, fmt.Sprintf(
-		welcomeMessage, p.author, p.cnf.CommunityName, p.cnf.CommandLink,
-		sigName, sigName, strings.Join(maintainers, " , @"),
-	), nil
+	comment, err := p.cnf.genWelcome(org, repo, &data, p.texts...)
+
+	return data.Sig, comment, err
 }
 
-func (bot *robot) getMaintainers(sigName string, p *param) ([]string, []string, error) {
+// getMaintainers returns the maintainers and committers of the sig which manages the repo.
+// The collaborators who can push are regarded as the maintainers if the repo belongs to
+// no sig or the sig has no maintainers.
+func (bot *robot) getMaintainers(rs *cache.RepoSig, p *param) ([]string, []string, error) {
 	if p.cnf.WelcomeSimpler {
 		membersToContact, err := bot.findSpecialContact(p)
-		if err == nil && len(*membersToContact) != 0 {
+		if err == nil && membersToContact != nil && len(*membersToContact) != 0 {
 			return membersToContact.UnsortedList(), nil, nil
 		}
 	}
 
+	if rs != nil {
+		maintainers, committers, err := bot.getSigMembers(rs)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(maintainers) != 0 {
+			return maintainers, committers, nil
+		}
+	}
+
 	users, err := bot.cli.ListCollaborator(p.prIssue)
 	if err != nil {
 		return nil, nil, err
@@ -275,12 +304,6 @@ func (bot *robot) getMaintainers(sigName string, p *param) ([]string, []string,
 		}
 	}
 
-	// when OWNERS file exists, collaborators as maintainers, committers set empty
-
-	// when sig-info.yaml file not exists, Collaborators as maintainers, committers set empty
-
-	// when sig-info.yaml file exists, get maintainers and committers from service[sig-info-cache]
-
 	return r, nil, nil
 }
 
@@ -331,7 +354,7 @@ func (bot *robot) findSpecialContact(p *param) (*sets.Set[string], error) {
 	}
 
 	owners := sets.New[string]()
-	var mo []Maintainer
+	var mo []cache.Member
 	for _, cg := range changes {
 		for _, f := range r.Relations {
 			for _, ff := range f.Path {
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	cache "github.com/opensourceways/atomgit-sig-file-cache/sdk"
+	"github.com/opensourceways/community-robot-lib/atomgitclient"
+	"github.com/opensourceways/community-robot-lib/config"
+	"github.com/opensourceways/community-robot-lib/fakeatomgit"
+	framework "github.com/opensourceways/community-robot-lib/robot-atomgit-framework"
+)
+
+const (
+	testOrg = "openeuler"
+	testBot = "openeuler-ci-bot"
+
+	testConfig = `
+config_items:
+  - repos:
+      - openeuler
+    community_name: openEuler
+    command_link: https://atomgit.com/openeuler/community/blob/master/en/command.md
+    community_repo: community
+    branch: master
+`
+
+	infraSigInfo = `
+name: Infrastructure
+mentors:
+  - gitee_id: Mentor
+maintainers:
+  - gitee_id: Alice
+repositories:
+  - repo:
+      - openeuler/infrastructure
+    committers:
+      - gitee_id: bob
+`
+)
+
+type testEnv struct {
+	t   *testing.T
+	s   *fakeatomgit.Server
+	h   framework.WebhookHandler
+	cli atomgitclient.Client
+
+	hookURL string
+}
+
+// newTestEnv serves the welcome robot with the fake AtomGit, and the sig info is
+// read from the community repository of it because the cache service is down.
+func newTestEnv(t *testing.T, cfg string) *testEnv {
+	s := fakeatomgit.NewServer(testBot)
+	s.SetFile(testOrg, "community", "master", "sig/Infrastructure/sig-info.yaml", []byte(infraSigInfo))
+
+	api := httptest.NewServer(s)
+	t.Cleanup(api.Close)
+
+	cli, err := atomgitclient.NewClientWithEndpoint(func() []byte { return []byte("token") }, api.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	sdk := cache.NewSDK(down.URL, 1)
+	sdk.SetFallback(cli, testOrg, "community", "master")
+
+	bot := newRobot(cli, sdk)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	agent := config.NewConfigAgent(bot.NewConfig)
+	if err := agent.Start(path); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(agent.Stop)
+
+	h := framework.NewWebhookHandler(bot, &agent, nil)
+	hook := httptest.NewServer(h)
+	t.Cleanup(hook.Close)
+
+	return &testEnv{t: t, s: s, h: h, cli: cli, hookURL: hook.URL}
+}
+
+func (env *testEnv) openIssue(repo string) int {
+	n := env.s.AddIssue(testOrg, repo, fakeatomgit.Issue{Title: "bug", Author: "newbie"})
+
+	env.sendIssueEvent(repo, n, "created")
+
+	return n
+}
+
+func (env *testEnv) openPR(repo, author string) int {
+	n := env.s.AddPullRequest(testOrg, repo, fakeatomgit.PullRequest{Title: "fix", Author: author})
+
+	e, err := env.s.PullRequestEvent(testOrg, repo, n, "created")
+	if err != nil {
+		env.t.Fatal(err)
+	}
+
+	if err := fakeatomgit.SendHook(env.hookURL, fakeatomgit.EventTypePullRequest, e); err != nil {
+		env.t.Fatal(err)
+	}
+
+	env.h.Wait()
+
+	return n
+}
+
+func TestWelcomeWithSig(t *testing.T) {
+	env := newTestEnv(t, testConfig)
+
+	n := env.openIssue("infrastructure")
+
+	comments := env.s.GetComments(testOrg, "infrastructure", n)
+	if len(comments) != 1 {
+		t.Fatalf("comments = %v", comments)
+	}
+
+	body := comments[0].Body
+	for _, want := range []string{
+		"[Infrastructure](https://atomgit.com/openeuler/community/tree/master/sig/Infrastructure)",
+		"any of the maintainers: @alice",
+		"any of the committers: @bob",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("comment should contain %q, got: %s", want, body)
+		}
+	}
+
+	if labels := env.s.GetLabels(testOrg, "infrastructure", n); len(labels) != 1 || labels[0] != "sig/Infrastructure" {
+		t.Errorf("labels = %v", labels)
+	}
+}
+
+func TestWelcomeWithoutSig(t *testing.T) {
+	env := newTestEnv(t, testConfig)
+	env.s.AddCollaborator(testOrg, "playground", "carol", "admin")
+
+	n := env.openIssue("playground")
+
+	comments := env.s.GetComments(testOrg, "playground", n)
+	if len(comments) != 1 {
+		t.Fatalf("comments = %v", comments)
+	}
+
+	body := comments[0].Body
+	if !strings.Contains(body, "does not belong to any SIG") || !strings.Contains(body, "@carol") {
+		t.Errorf("unexpected comment: %s", body)
+	}
+
+	if labels := env.s.GetLabels(testOrg, "playground", n); len(labels) != 0 {
+		t.Errorf("no sig label should be added, labels = %v", labels)
+	}
+}
@@ -1,21 +1,37 @@
 package main
 
 import (
+	"errors"
+
 	"k8s.io/apimachinery/pkg/util/sets"
+
+	cache "github.com/opensourceways/atomgit-sig-file-cache/sdk"
 )
 
-type sigDiffBySha struct {
-	Maintainers, Committers    sets.Set[string]
-	Org, Repo, Name, Path, Sha string
-	Size                       float64
+// getSigOfRepo returns the sig which manages org/repo, and nil if the repo belongs to no sig.
+func (bot *robot) getSigOfRepo(org, repo string) (*cache.RepoSig, error) {
+	v, err := bot.cacheCli.GetSigOfRepo(org, repo)
+	if errors.Is(err, cache.ErrNotFound) {
+		return nil, nil
+	}
+
+	return v, err
 }
 
-func (bot *robot) getSigOfRepo(org, repo string) (string, error) {
-	// TODO use service[sig-info-cache]
-	//sigName, err := bot.findSigName(org, repo, cfg, true)
-	//if err != nil {
-	//	return sigName, err
-	//}
+// getSigMembers returns the maintainers of sig and the committers of repo
+// which are not maintainers.
+func (bot *robot) getSigMembers(rs *cache.RepoSig) ([]string, []string, error) {
+	m, err := bot.cacheCli.GetSigMembers(rs.Sig)
+	if err != nil {
+		if errors.Is(err, cache.ErrNotFound) {
+			err = nil
+		}
+
+		return nil, nil, err
+	}
+
+	maintainers := sets.New[string](m.Maintainers...)
+	committers := sets.New[string](rs.Committers...).Difference(maintainers)
 
-	return "sigName", nil
+	return sets.List(maintainers), sets.List(committers), nil
 }
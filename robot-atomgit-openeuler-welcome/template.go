@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+const (
+	langEn   = "en"
+	langZh   = "zh"
+	langAuto = "auto"
+
+	defaultWelcomeTemplateEn = `
+Hi ***{{.Author}}***, welcome to the {{.CommunityName}} Community.
+I'm the Bot here serving you. You can find the instructions on how to interact with me at **[Here]({{.CommandLink}})**.
+{{- if .Sig}}
+If you have any questions, please contact the SIG: [{{.Sig}}]({{.SigURL}}), and any of the maintainers
+{{- if .Maintainers}}: @{{join .Maintainers " , @"}}{{if .Committers}}, any of the committers: @{{join .Committers " , @"}}{{end}}{{else}}.{{end}}
+{{- else}}
+This repository does not belong to any SIG yet. If you have any questions, please contact the administrators of the repository
+{{- if .Maintainers}}: @{{join .Maintainers " , @"}}{{else}}.{{end}}
+{{- end}}`
+
+	defaultWelcomeTemplateZh = `
+***{{.Author}}*** 您好，欢迎来到 {{.CommunityName}} 社区。
+我是这里的机器人，您可以在 **[这里]({{.CommandLink}})** 查看与我交互的指令说明。
+{{- if .Sig}}
+如有任何问题，请联系 SIG: [{{.Sig}}]({{.SigURL}})，或者任意一位 maintainer
+{{- if .Maintainers}}: @{{join .Maintainers " , @"}}{{if .Committers}}，或者任意一位 committer: @{{join .Committers " , @"}}{{end}}{{else}}。{{end}}
+{{- else}}
+该仓库尚未归属任何 SIG，如有任何问题，请联系仓库管理员
+{{- if .Maintainers}}: @{{join .Maintainers " , @"}}{{else}}。{{end}}
+{{- end}}`
+)
+
+var (
+	templateFuncs = template.FuncMap{"join": strings.Join}
+
+	defaultWelcomeTemplates = welcomeTemplateSet{
+		langEn: template.Must(newWelcomeTemplate(langEn).Parse(defaultWelcomeTemplateEn)),
+		langZh: template.Must(newWelcomeTemplate(langZh).Parse(defaultWelcomeTemplateZh)),
+	}
+)
+
+// welcomeData is the data which the welcome template is executed with.
+type welcomeData struct {
+	Author        string
+	CommunityName string
+	CommandLink   string
+
+	// Sig is empty if the repository belongs to no sig.
+	Sig    string
+	SigURL string
+
+	// Maintainers is the people to contact, and it is empty if they need not be noticed.
+	Maintainers []string
+	Committers  []string
+
+	Newcomer    bool
+	PullRequest bool
+}
+
+// welcomeTemplates is the template files of welcome message in each language.
+type welcomeTemplates struct {
+	// En is the path of template file in English
+	En string `json:"en,omitempty"`
+
+	// Zh is the path of template file in Chinese
+	Zh string `json:"zh,omitempty"`
+}
+
+// parse parses the template files, and the ones in base are used if not set.
+func (t *welcomeTemplates) parse(base welcomeTemplateSet) (welcomeTemplateSet, error) {
+	r := welcomeTemplateSet{}
+
+	for lang, path := range map[string]string{langEn: t.En, langZh: t.Zh} {
+		if path == "" {
+			r[lang] = base[lang]
+
+			continue
+		}
+
+		v, err := parseWelcomeTemplate(lang, path)
+		if err != nil {
+			return nil, err
+		}
+
+		r[lang] = v
+	}
+
+	return r, nil
+}
+
+// welcomeTemplateSet maps the language to the template.
+type welcomeTemplateSet map[string]*template.Template
+
+func (s welcomeTemplateSet) execute(lang string, data *welcomeData) (string, error) {
+	t, ok := s[lang]
+	if !ok {
+		t = s[langEn]
+	}
+
+	buf := new(bytes.Buffer)
+	if err := t.Execute(buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func newWelcomeTemplate(lang string) *template.Template {
+	return template.New("welcome-" + lang).Funcs(templateFuncs)
+}
+
+// parseWelcomeTemplate parses the template file at path, and executes it with
+// the sample data so that the errors of referring to unknown fields are found early.
+func parseWelcomeTemplate(lang, path string) (*template.Template, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := newWelcomeTemplate(lang).Parse(string(b))
+	if err != nil {
+		return nil, fmt.Errorf("invalid welcome template: %s, err: %s", path, err.Error())
+	}
+
+	sample := welcomeData{
+		Author:        "author",
+		CommunityName: "community",
+		CommandLink:   "https://atomgit.com",
+		Sig:           "sig",
+		SigURL:        "https://atomgit.com",
+		Maintainers:   []string{"maintainer"},
+		Committers:    []string{"committer"},
+	}
+	if err = t.Execute(new(bytes.Buffer), &sample); err != nil {
+		return nil, fmt.Errorf("invalid welcome template: %s, err: %s", path, err.Error())
+	}
+
+	return t, nil
+}
+
+// detectLanguage regards the texts as Chinese if they have any Chinese character.
+func detectLanguage(texts ...string) string {
+	for _, text := range texts {
+		for _, r := range text {
+			if unicode.Is(unicode.Han, r) {
+				return langZh
+			}
+		}
+	}
+
+	return langEn
+}
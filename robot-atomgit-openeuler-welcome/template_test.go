@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTemplate(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "welcome.tmpl")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func newTestBotConfig() *botConfig {
+	c := &botConfig{
+		CommunityName: "openEuler",
+		CommandLink:   "https://atomgit.com/openeuler/community",
+		CommunityRepo: "community",
+		Branch:        "master",
+	}
+	c.Repos = []string{"openeuler"}
+
+	return c
+}
+
+func TestGenWelcome(t *testing.T) {
+	c := newTestBotConfig()
+	c.Language = langAuto
+	c.RepoWelcomeTemplates = map[string]welcomeTemplates{
+		"openeuler/kernel": {En: writeTemplate(t, `{{if .Newcomer}}first contribution of {{.Author}}{{end}}`)},
+	}
+	c.setDefault()
+
+	if err := c.validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	data := &welcomeData{Author: "alice", Sig: "Kernel", Maintainers: []string{"bob"}, Newcomer: true}
+
+	cases := []struct {
+		repo  string
+		texts []string
+		want  string
+	}{
+		{repo: "infrastructure", texts: []string{"fix the bug"}, want: "any of the maintainers: @bob"},
+		{repo: "infrastructure", texts: []string{"修复问题"}, want: "任意一位 maintainer: @bob"},
+		{repo: "kernel", texts: []string{"fix the bug"}, want: "first contribution of alice"},
+		// the default template in Chinese is used if the repo only overrides the English one.
+		{repo: "kernel", texts: []string{"修复问题"}, want: "任意一位 maintainer: @bob"},
+	}
+
+	for _, tc := range cases {
+		v, err := c.genWelcome("openeuler", tc.repo, data, tc.texts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(v, tc.want) {
+			t.Errorf("welcome of %s with %v = %q, want containing %q", tc.repo, tc.texts, v, tc.want)
+		}
+	}
+}
+
+func TestInvalidWelcomeTemplate(t *testing.T) {
+	for _, content := range []string{`{{.Author`, `{{.Unknown}}`} {
+		c := newTestBotConfig()
+		c.WelcomeTemplates.Zh = writeTemplate(t, content)
+		c.setDefault()
+
+		if err := c.validate(); err == nil {
+			t.Errorf("the template %q should be invalid", content)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/opensourceways/community-robot-lib/atomgitclient"
+	"github.com/opensourceways/go-atomgit/atomgit"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultNeedsInfoLabel = "needs-info"
+
+	triageCommentMark = "<!-- issue-triage -->"
+	triageComment     = triageCommentMark + `
+Hi ***%s***, the following fields of the issue are missing or empty:
+
+%s
+
+Please edit the issue to fill them in, so that it can be handled quickly. The label **%s** will be removed after that.`
+
+	triageDoneComment = triageCommentMark + `
+Thanks ***%s***, all the required fields of the issue have been filled in.`
+)
+
+var (
+	headingRe     = regexp.MustCompile(`(?m)^#{1,6}[ \t]+(.+?)[ \t#]*$`)
+	htmlCommentRe = regexp.MustCompile(`(?s)<!--.*?-->`)
+	frontMatterRe = regexp.MustCompile(`(?s)\A---\r?\n.*?\r?\n---\r?\n`)
+
+	optionalMarks = []string{"(optional)", "（可选）", "(可选)"}
+)
+
+type triageConfig struct {
+	// IssueTemplates is the paths of issue templates in the repository. The sections
+	// of template are the fields of issue, and they are required unless the titles end
+	// with "(optional)". The triage is disabled if it is empty.
+	IssueTemplates []string `json:"issue_templates,omitempty"`
+
+	// Branch is the branch to read the templates from. The default branch of repository is used if not set.
+	Branch string `json:"branch,omitempty"`
+
+	// Label is the label added to the incomplete issue. The default is needs-info.
+	Label string `json:"label,omitempty"`
+}
+
+func (c *triageConfig) setDefault() {
+	if c.Label == "" {
+		c.Label = defaultNeedsInfoLabel
+	}
+}
+
+func (c *triageConfig) enabled() bool {
+	return len(c.IssueTemplates) > 0
+}
+
+// section is a field of issue which is a markdown section led by a heading.
+type section struct {
+	title    string
+	content  string
+	optional bool
+}
+
+// parseSections splits the markdown text into sections by the headings.
+// The text before the first heading is dropped.
+func parseSections(text string) []section {
+	text = frontMatterRe.ReplaceAllString(text, "")
+
+	locs := headingRe.FindAllStringSubmatchIndex(text, -1)
+	r := make([]section, 0, len(locs))
+
+	for i, loc := range locs {
+		end := len(text)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+
+		title, optional := trimOptionalMark(text[loc[2]:loc[3]])
+
+		r = append(r, section{
+			title:    title,
+			content:  cleanContent(text[loc[1]:end]),
+			optional: optional,
+		})
+	}
+
+	return r
+}
+
+func trimOptionalMark(title string) (string, bool) {
+	title = strings.TrimSpace(title)
+
+	// the suffix is compared on the title itself, because the lower case of it
+	// may have a different length.
+	for _, mark := range optionalMarks {
+		if n := len(title) - len(mark); n >= 0 && strings.EqualFold(title[n:], mark) {
+			return strings.TrimSpace(title[:n]), true
+		}
+	}
+
+	return title, false
+}
+
+func cleanContent(s string) string {
+	return strings.TrimSpace(htmlCommentRe.ReplaceAllString(s, ""))
+}
+
+func normalizeTitle(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// missingFields returns the required fields of template which are absent in the body,
+// or left empty or the same as the placeholder of template. It also returns the number
+// of fields of template which the body has, to pick the template which the issue follows.
+func missingFields(template []section, body []section) ([]string, int) {
+	contents := make(map[string]string, len(body))
+	for _, s := range body {
+		contents[normalizeTitle(s.title)] = s.content
+	}
+
+	var missing []string
+	matched := 0
+
+	for _, s := range template {
+		v, ok := contents[normalizeTitle(s.title)]
+		if ok {
+			matched++
+		}
+
+		if s.optional {
+			continue
+		}
+
+		if !ok || v == "" || v == s.content {
+			missing = append(missing, s.title)
+		}
+	}
+
+	return missing, matched
+}
+
+// triage checks the issue against the templates, labels it with needs-info and
+// comments the missing fields if it is incomplete, or removes the label otherwise.
+func (bot *robot) triage(e *atomgit.IssuesEvent, cfg *botConfig, log *logrus.Entry) error {
+	tc := &cfg.Triage
+	if !tc.enabled() {
+		return nil
+	}
+
+	org, repo := e.GetRepo().GetOrgAndRepo()
+	is := atomgitclient.BuildPRIssue(org, repo, e.GetIssue().GetNumber())
+
+	branch := tc.Branch
+	if branch == "" {
+		branch = e.GetRepo().GetDefaultBranch()
+	}
+
+	missing, err := bot.checkIssueBody(org, repo, branch, tc.IssueTemplates, e.GetIssue().GetBody(), log)
+	if err != nil {
+		return err
+	}
+
+	hasLabel := false
+	for _, l := range e.GetIssue().Labels {
+		if l.GetName() == tc.Label {
+			hasLabel = true
+
+			break
+		}
+	}
+
+	author := e.GetIssue().GetUser().GetLogin()
+
+	if len(missing) == 0 {
+		if hasLabel {
+			if err = bot.cli.RemoveIssueLabel(is, tc.Label); err != nil {
+				return err
+			}
+		}
+
+		// the comment listing the missing fields is out of date.
+		return bot.upsertTriageComment(is, fmt.Sprintf(triageDoneComment, author), false)
+	}
+
+	if !hasLabel {
+		if err = bot.cli.AddIssueLabel(is, []string{tc.Label}); err != nil {
+			return err
+		}
+	}
+
+	fields := make([]string, len(missing))
+	for i, v := range missing {
+		fields[i] = "- " + v
+	}
+
+	comment := fmt.Sprintf(triageComment, author, strings.Join(fields, "\n"), tc.Label)
+
+	return bot.upsertTriageComment(is, comment, true)
+}
+
+// checkIssueBody returns the missing fields of body against the template which it follows most.
+func (bot *robot) checkIssueBody(org, repo, branch string, paths []string, body string, log *logrus.Entry) ([]string, error) {
+	bodySections := parseSections(body)
+
+	var missing []string
+	best := -1
+
+	for _, path := range paths {
+		content, err := bot.cli.GetPathContent(org, repo, path, branch)
+		if err != nil {
+			log.WithError(err).Errorf("get issue template: %s/%s/%s", org, repo, path)
+
+			continue
+		}
+
+		text, err := content.GetContent()
+		if err != nil {
+			log.WithError(err).Errorf("decode issue template: %s/%s/%s", org, repo, path)
+
+			continue
+		}
+
+		m, matched := missingFields(parseSections(text), bodySections)
+		if matched > best {
+			missing, best = m, matched
+		}
+	}
+
+	if best < 0 {
+		return nil, fmt.Errorf("no issue template is available for %s/%s", org, repo)
+	}
+
+	return missing, nil
+}
+
+// upsertTriageComment updates the comment of triage if it exists, so that the
+// issue is not flooded with the comments when it is edited several times.
+// The comment is created only if create is true.
+func (bot *robot) upsertTriageComment(is *atomgitclient.PRIssue, comment string, create bool) error {
+	comments, err := bot.cli.ListIssueComments(is)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range comments {
+		if strings.HasPrefix(c.GetBody(), triageCommentMark) {
+			if c.GetBody() == comment {
+				return nil
+			}
+
+			return bot.cli.UpdateIssueComment(is, c.GetID(), &atomgit.IssueComment{Body: atomgit.String(comment)})
+		}
+	}
+
+	if !create {
+		return nil
+	}
+
+	return bot.cli.CreateIssueComment(is, comment)
+}
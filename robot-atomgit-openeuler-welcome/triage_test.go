@@ -0,0 +1,148 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/opensourceways/community-robot-lib/atomgitclient"
+	"github.com/opensourceways/community-robot-lib/fakeatomgit"
+	"github.com/opensourceways/go-atomgit/atomgit"
+)
+
+const bugTemplate = `---
+name: Bug report
+---
+<!-- describe the bug as below -->
+
+### Environment
+- version:
+
+### Steps to reproduce
+
+### Expected behavior
+
+### Anything else (optional)
+`
+
+func TestMissingFields(t *testing.T) {
+	body := `
+### Environment
+- version:
+
+### steps  to reproduce
+1. run it
+<!-- no expected behavior -->
+### Expected behavior
+<!-- leave it -->
+`
+
+	missing, matched := missingFields(parseSections(bugTemplate), parseSections(body))
+
+	if want := []string{"Environment", "Expected behavior"}; !reflect.DeepEqual(missing, want) {
+		t.Errorf("missing = %v, want %v", missing, want)
+	}
+
+	if matched != 3 {
+		t.Errorf("matched = %d, want 3", matched)
+	}
+}
+
+func TestTriage(t *testing.T) {
+	env := newTestEnv(t, testConfig+`    triage:
+      issue_templates:
+        - .atomgit/ISSUE_TEMPLATE/bug.md
+      branch: master
+`)
+	env.s.SetFile(testOrg, "infrastructure", "master", ".atomgit/ISSUE_TEMPLATE/bug.md", []byte(bugTemplate))
+
+	n := env.s.AddIssue(testOrg, "infrastructure", fakeatomgit.Issue{
+		Title:  "crash",
+		Author: "alice",
+		Body:   "### Environment\n- version: 24.03\n",
+	})
+	env.sendIssueEvent("infrastructure", n, atomgit.ActionStateCreated)
+
+	if labels := env.s.GetLabels(testOrg, "infrastructure", n); !strings.Contains(strings.Join(labels, ","), defaultNeedsInfoLabel) {
+		t.Fatalf("the incomplete issue should be labeled, labels = %v", labels)
+	}
+
+	triaged := func() string {
+		for _, c := range env.s.GetComments(testOrg, "infrastructure", n) {
+			if strings.HasPrefix(c.Body, triageCommentMark) {
+				return c.Body
+			}
+		}
+
+		return ""
+	}
+
+	if c := triaged(); !strings.Contains(c, "- Steps to reproduce\n- Expected behavior") || strings.Contains(c, "Anything else") {
+		t.Errorf("unexpected comment of triage: %s", c)
+	}
+
+	// the comment of triage is updated instead of created again.
+	env.editIssue("infrastructure", n, "### Environment\n- version: 24.03\n### Steps to reproduce\nrun it\n")
+
+	if c := triaged(); strings.Contains(c, "Steps to reproduce") || !strings.Contains(c, "- Expected behavior") {
+		t.Errorf("unexpected comment of triage after editing: %s", c)
+	}
+
+	if len(env.s.GetComments(testOrg, "infrastructure", n)) != 2 {
+		t.Errorf("comments = %v", env.s.GetComments(testOrg, "infrastructure", n))
+	}
+
+	env.editIssue("infrastructure", n, "### Environment\n- version: 24.03\n### Steps to reproduce\nrun it\n### Expected behavior\nno crash\n")
+
+	for _, l := range env.s.GetLabels(testOrg, "infrastructure", n) {
+		if l == defaultNeedsInfoLabel {
+			t.Errorf("the label should be removed after the issue is completed")
+		}
+	}
+
+	if c := triaged(); strings.Contains(c, "Expected behavior") || !strings.Contains(c, "have been filled in") {
+		t.Errorf("the comment of triage should be updated after the issue is completed: %s", c)
+	}
+}
+
+func TestTrimOptionalMark(t *testing.T) {
+	cases := []struct {
+		title    string
+		want     string
+		optional bool
+	}{
+		{"Anything else (Optional)", "Anything else", true},
+		{"其他信息（可选）", "其他信息", true},
+		// the lower case of "İ" is longer than itself.
+		{"İİİ Logs (optional)", "İİİ Logs", true},
+		{"İİİİİİİİİİİİ", "İİİİİİİİİİİİ", false},
+	}
+
+	for _, c := range cases {
+		if title, optional := trimOptionalMark(c.title); title != c.want || optional != c.optional {
+			t.Errorf("trimOptionalMark(%q) = %q, %v", c.title, title, optional)
+		}
+	}
+}
+
+func (env *testEnv) sendIssueEvent(repo string, n int, action string) {
+	e, err := env.s.IssuesEvent(testOrg, repo, n, action)
+	if err != nil {
+		env.t.Fatal(err)
+	}
+
+	if err := fakeatomgit.SendHook(env.hookURL, fakeatomgit.EventTypeIssues, e); err != nil {
+		env.t.Fatal(err)
+	}
+
+	env.h.Wait()
+}
+
+func (env *testEnv) editIssue(repo string, n int, body string) {
+	is := atomgitclient.BuildPRIssue(testOrg, repo, n)
+	if err := env.cli.UpdateIssue(is, &atomgit.IssueRequest{Body: atomgit.String(body)}); err != nil {
+		env.t.Fatal(err)
+	}
+
+	env.sendIssueEvent(repo, n, atomgitclient.ActionUpdated)
+}